@@ -0,0 +1,60 @@
+// Package main provides an example of using gopher-ai with a local Ollama server.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+	"github.com/marti-jorda-roca/gopher-ai/gopherai/ollama"
+)
+
+type GetWeatherParams struct {
+	Location string `json:"location" description:"The city and state, e.g. San Francisco, CA"`
+	Unit     string `json:"unit" description:"Temperature unit" enum:"celsius,fahrenheit"`
+}
+
+func GetWeather(params GetWeatherParams) (string, error) {
+	weatherData := map[string]any{
+		"location":    params.Location,
+		"temperature": 22,
+		"unit":        params.Unit,
+		"conditions":  "partly cloudy",
+		"humidity":    65,
+	}
+	data, _ := json.Marshal(weatherData)
+	return string(data), nil
+}
+
+func main() {
+	ctx := context.Background()
+
+	myProvider := ollama.NewProvider().
+		SetModel("llama3.1").
+		SetNumCtx(8192)
+
+	err := myProvider.EnsureModel(ctx, "llama3.1", func(p ollama.PullProgress) {
+		fmt.Printf("%s %d/%d\n", p.Status, p.Completed, p.Total)
+	})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	myTool := gopherai.NewTool("get_weather", "Get the current weather for a location", GetWeather)
+
+	myAgent := gopherai.NewAgent(myProvider,
+		gopherai.WithSystemPrompt("You are a helpful weather assistant."),
+		gopherai.WithTools(myTool),
+	)
+
+	result, err := myAgent.Run(ctx, "What's the weather like in Paris?")
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Response: %s\n", result.Text)
+}
@@ -42,8 +42,12 @@ func main() {
 		os.Exit(1)
 	}
 
-	myProvider := openai.NewProvider(apiKey).
-		SetModel("gpt-4.1")
+	limiter := gopherai.NewRateLimiter(gopherai.RateLimits{
+		RequestsPerMinute: 500,
+		TokensPerMinute:   30_000,
+		MaxInFlight:       4,
+	})
+	myProvider := gopherai.NewRateLimitedProvider(openai.NewProvider(apiKey).SetModel("gpt-4.1"), limiter)
 
 	myTool := gopherai.NewTool("get_weather", "Get the current weather for a location", GetWeather)
 
@@ -2,23 +2,47 @@ package gopherai
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 )
 
 // Agent orchestrates interactions with an AI provider.
 type Agent struct {
+	name                string
 	provider            Provider
 	tools               []Tool
 	toolMap             map[string]Tool
 	systemPrompt        string
-	conversationHistory []any
+	conversationHistory []Message
+	sessionStore        SessionStore
+	checkpointer        Checkpointer
+	maxTurns            int
+	maxToolCalls        int
+	timeout             time.Duration
+	maxTokens           int
+	maxCost             float64
+	toolErrorPolicy     ToolErrorPolicy
+	outputSchema        *OutputSchema
+	outputRetries       int
+	pricer              Pricer
+	hooks               hookChain
 }
 
 // AgentOption configures an Agent.
 type AgentOption func(*Agent)
 
+// WithName sets the agent's name, which is reported in the RunInfo of its runs.
+func WithName(name string) AgentOption {
+	return func(a *Agent) {
+		a.name = name
+	}
+}
+
 // WithSystemPrompt sets the system prompt for the agent.
 func WithSystemPrompt(systemPrompt string) AgentOption {
 	return func(a *Agent) {
@@ -37,17 +61,36 @@ func WithTools(tools ...Tool) AgentOption {
 }
 
 // WithConversationHistory sets the initial conversation history for the agent.
-func WithConversationHistory(history []any) AgentOption {
+func WithConversationHistory(history []Message) AgentOption {
 	return func(a *Agent) {
 		a.conversationHistory = history
 	}
 }
 
+// WithToolErrorPolicy sets how the agent handles failing and unknown tool calls.
+// Tools with their own ErrorPolicy override it.
+func WithToolErrorPolicy(policy ToolErrorPolicy) AgentOption {
+	return func(a *Agent) {
+		a.toolErrorPolicy = policy
+	}
+}
+
+// WithSessionStore sets the store used by RunSession and RunStreamSession
+// to load and persist conversations by session ID.
+func WithSessionStore(store SessionStore) AgentOption {
+	return func(a *Agent) {
+		a.sessionStore = store
+	}
+}
+
 // NewAgent creates a new Agent with the given provider and options.
 func NewAgent(provider Provider, opts ...AgentOption) *Agent {
 	agent := &Agent{
-		provider: provider,
-		toolMap:  make(map[string]Tool),
+		provider:        provider,
+		toolMap:         make(map[string]Tool),
+		maxTurns:        defaultMaxTurns,
+		toolErrorPolicy: AbortOnToolError(),
+		outputRetries:   defaultOutputRetries,
 	}
 
 	for _, opt := range opts {
@@ -57,186 +100,373 @@ func NewAgent(provider Provider, opts ...AgentOption) *Agent {
 	return agent
 }
 
+// WithProvider returns a copy of the agent that sends requests to the given provider.
+// Passing a RunResult's MessageHistory to the copy continues the conversation on
+// the new provider, including any tool calls and tool outputs.
+func (a *Agent) WithProvider(provider Provider) *Agent {
+	clone := *a
+	clone.provider = provider
+	return &clone
+}
+
 type subAgentInput struct {
 	Task string `json:"task" description:"The task or prompt to send to this agent"`
 }
 
 // AsTool converts the agent into a Tool that can be used by another agent.
+// The sub-agent runs with the parent's tool context, so it is cancelled with
+// the parent run and draws from the parent's budget.
 func (a *Agent) AsTool(name, description string) Tool {
-	return NewTool(name, description, func(input subAgentInput) (string, error) {
-		result, err := a.Run(context.Background(), input.Task)
+	return NewToolCtx(name, description, func(ctx context.Context, input subAgentInput) (string, error) {
+		result, err := a.Run(ctx, input.Task)
 		if err != nil {
 			return "", err
 		}
+		if result.Interrupted {
+			return "", fmt.Errorf("sub-agent %s has tool calls awaiting approval, which sub-agents cannot wait for", name)
+		}
 		return result.Text, nil
 	})
 }
 
 // RunResult holds the result of an agent run, including the response text and conversation history.
 type RunResult struct {
-	Text    string
-	history []any
+	Text  string
+	RunID string
+	// Provider names the provider that produced the final response, when the
+	// agent's provider routes requests to several, as FallbackProvider does.
+	// Streamed runs take it from the done event of the model's stream. It is
+	// empty otherwise.
+	Provider string
+	// Usage is the token usage of the run, summed over Turns, which holds the
+	// usage of each model call. Providers that do not implement UsageProvider
+	// report none.
+	Usage Usage
+	Turns []TurnUsage
+	// Cost is the cost of the run in USD according to the agent's Pricer. It
+	// leaves out calls to models the Pricer has no price for.
+	Cost float64
+	// Interrupted reports that the run stopped before calling tools that
+	// require approval. State holds the pending calls and what is needed to
+	// continue the run with Agent.Resume; Text is empty.
+	Interrupted bool
+	State       *RunState
+	history     []Message
 }
 
 // MessageHistory returns the conversation history from this run.
-func (r *RunResult) MessageHistory() []any {
+func (r *RunResult) MessageHistory() []Message {
 	return r.history
 }
 
+// modelCaller sends a provider request and returns the assistant message it produced.
+type modelCaller func(ctx context.Context, req any) (ModelResponse, error)
+
 // Run executes the agent with the given prompt and optional conversation history, returning the final response and updated history.
-func (a *Agent) Run(ctx context.Context, prompt string, history ...[]any) (*RunResult, error) {
-	providerTools := make([]any, len(a.tools))
-	for i, tool := range a.tools {
-		providerTools[i] = a.provider.ConvertTool(tool)
+func (a *Agent) Run(ctx context.Context, prompt string, history ...[]Message) (*RunResult, error) {
+	messages := a.startConversation(prompt, history)
+	return a.runLoop(ctx, a.newRunState(ctx, messages), a.callModel)
+}
+
+// RunStream executes the agent with streaming output, returning a channel of StreamEvents.
+func (a *Agent) RunStream(ctx context.Context, prompt string, history ...[]Message) (<-chan StreamEvent, error) {
+	return a.runStream(ctx, a.newRunState(ctx, a.startConversation(prompt, history)), nil)
+}
+
+// RunSession executes the agent on the conversation stored under sessionID in
+// the agent's session store, then appends the prompt and every message produced
+// by the run to that session. A session that does not exist yet is created,
+// starting from the agent's conversation history. A run interrupted for tool
+// call approval is saved once it is resumed and completes.
+func (a *Agent) RunSession(ctx context.Context, sessionID, prompt string) (*RunResult, error) {
+	history, err := a.loadSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	state := a.newRunState(ctx, a.startConversation(prompt, [][]Message{history}))
+	state.sessionID, state.sessionLength = sessionID, len(history)
+	result, err := a.runLoop(ctx, state, a.callModel)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.finishSession(ctx, sessionID, history, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RunStreamSession is the streaming counterpart of RunSession. The session is
+// only updated when the run completes, before the final done event is sent.
+func (a *Agent) RunStreamSession(ctx context.Context, sessionID, prompt string) (<-chan StreamEvent, error) {
+	history, err := a.loadSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	state := a.newRunState(ctx, a.startConversation(prompt, [][]Message{history}))
+	state.sessionID, state.sessionLength = sessionID, len(history)
+	return a.runStream(ctx, state, func(result *RunResult) error {
+		return a.finishSession(ctx, sessionID, history, result)
+	})
+}
+
+// runStream runs the loop in the background, streaming its events. The stream
+// ends with a done event, an interrupted event or an error event.
+func (a *Agent) runStream(ctx context.Context, state *runState, onComplete func(*RunResult) error) (<-chan StreamEvent, error) {
+	streamProvider, ok := a.provider.(StreamProvider)
+	if !ok {
+		return nil, fmt.Errorf("provider does not support streaming")
+	}
+
+	outEvents := make(chan StreamEvent, 100)
+
+	go func() {
+		defer close(outEvents)
+
+		result, err := a.runLoop(ctx, state, a.streamModel(streamProvider, outEvents))
+		if err == nil && onComplete != nil {
+			err = onComplete(result)
+		}
+		if err != nil {
+			outEvents <- StreamEvent{
+				Type:  StreamEventTypeError,
+				Error: err,
+			}
+			return
+		}
+		if result.Interrupted {
+			outEvents <- StreamEvent{Type: StreamEventTypeInterrupted, State: result.State}
+			return
+		}
+		outEvents <- StreamEvent{Type: StreamEventTypeDone, Provider: result.Provider}
+	}()
+
+	return outEvents, nil
+}
+
+func (a *Agent) loadSession(ctx context.Context, sessionID string) ([]Message, error) {
+	if a.sessionStore == nil {
+		return nil, fmt.Errorf("no session store configured")
+	}
+
+	history, err := a.sessionStore.Load(ctx, sessionID)
+	if err != nil && !errors.Is(err, ErrSessionNotFound) {
+		return nil, fmt.Errorf("failed to load session: %w", err)
 	}
+	return history, nil
+}
 
-	var conversationHistory []any
+// saveSession appends the messages of result that are not part of the loaded history.
+func (a *Agent) saveSession(ctx context.Context, sessionID string, history []Message, result *RunResult) error {
+	if err := a.sessionStore.Append(ctx, sessionID, result.history[len(history):]...); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	return nil
+}
+
+// finishSession saves a completed run to the session. An interrupted run is
+// not saved yet: its state records the session instead, for Resume to save it.
+func (a *Agent) finishSession(ctx context.Context, sessionID string, history []Message, result *RunResult) error {
+	if result.Interrupted {
+		return nil
+	}
+	if a.sessionStore == nil {
+		return fmt.Errorf("no session store configured")
+	}
+	return a.saveSession(ctx, sessionID, history, result)
+}
+
+// startConversation copies the history to use for a run and appends the prompt to it.
+func (a *Agent) startConversation(prompt string, history [][]Message) []Message {
+	var messages []Message
 	if len(history) > 0 && len(history[0]) > 0 {
-		conversationHistory = make([]any, len(history[0]))
-		copy(conversationHistory, history[0])
+		messages = CloneMessages(history[0])
 	} else if len(a.conversationHistory) > 0 {
-		conversationHistory = make([]any, len(a.conversationHistory))
-		copy(conversationHistory, a.conversationHistory)
+		messages = CloneMessages(a.conversationHistory)
 	}
+	return append(messages, NewUserMessage(prompt))
+}
 
-	conversationHistory = append(conversationHistory, prompt)
-	var input any = prompt
-	if len(conversationHistory) > 1 {
-		input = conversationHistory
+func (a *Agent) runLoop(ctx context.Context, state *runState, call modelCaller) (result *RunResult, err error) {
+	if state.deadline.IsZero() && a.timeout > 0 {
+		state.deadline = time.Now().Add(a.timeout)
+	}
+	if !state.deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadlineCause(ctx, state.deadline, errRunTimeout)
+		defer cancel()
 	}
-	maxIterations := 10
 
-	for i := 0; i < maxIterations; i++ {
-		req := a.provider.BuildRequest(input, a.systemPrompt, providerTools)
-		resp, err := a.provider.CreateResponse(ctx, req)
+	ctx = contextWithRunInfo(ctx, state.info)
+	ctx = contextWithBudgets(ctx, state.budgets)
+
+	defer func() {
 		if err != nil {
-			return nil, fmt.Errorf("failed to create response: %w", err)
+			a.hooks.runError(ctx, err)
+		} else {
+			a.hooks.runEnd(ctx, result)
 		}
+	}()
+	if err := a.hooks.runStart(ctx, state.messages); err != nil {
+		return nil, err
+	}
 
-		toolCalls, err := a.provider.ExtractToolCalls(resp)
+	providerTools := make([]any, len(a.tools))
+	for i, tool := range a.tools {
+		providerTools[i] = a.provider.ConvertTool(tool)
+	}
+
+	if batch := state.resume; batch != nil {
+		results, err := a.runDecidedTools(ctx, state, batch)
 		if err != nil {
-			return nil, fmt.Errorf("failed to extract tool calls: %w", err)
+			return nil, state.toolsFailed(ctx, err)
 		}
-
-		if len(toolCalls) == 0 {
-			text := a.provider.ExtractText(resp)
-			assistantMessage := a.provider.CreateAssistantMessage(text)
-			conversationHistory = append(conversationHistory, assistantMessage)
-			return &RunResult{
-				Text:    text,
-				history: conversationHistory,
-			}, nil
+		state.messages = append(state.messages, batch.response, NewToolResultMessage(results...))
+		if err := a.saveCheckpoint(ctx, state.snapshot(nil, nil)); err != nil {
+			return nil, err
 		}
+	}
 
-		type toolResult struct {
-			call   ToolCall
-			output string
+	for {
+		if limit, ok := state.budgetExceeded(); ok {
+			return nil, state.exceeded(limit)
+		}
+		if !state.startTurn() {
+			return nil, state.exceeded(LimitMaxTurns)
+		}
+		if timedOut(ctx) {
+			return nil, state.exceeded(LimitTimeout)
 		}
 
-		results := make([]toolResult, len(toolCalls))
-		g, _ := errgroup.WithContext(ctx)
-
-		for i, call := range toolCalls {
-			call := call
-			i := i
+		req, err := a.buildRequest(state.messages, providerTools)
+		if err != nil {
+			return nil, err
+		}
+		modelCall := &ModelCall{Turn: state.turns, Messages: state.messages, Request: req}
+		if err := a.hooks.beforeModelCall(ctx, modelCall); err != nil {
+			return nil, err
+		}
+		response, err := call(ctx, modelCall.Request)
+		if err != nil {
+			if timedOut(ctx) {
+				return nil, state.exceeded(LimitTimeout)
+			}
+			return nil, err
+		}
+		state.provider = response.Provider
+		state.addUsage(response.Usage)
+		if err := a.hooks.afterModelCall(ctx, modelCall, &response); err != nil {
+			return nil, err
+		}
+		assistantMessage := withUniqueToolCallIDs(state.messages, response.Message)
 
-			tool, ok := a.toolMap[call.Name]
-			if !ok {
-				return nil, fmt.Errorf("unknown tool: %s", call.Name)
+		toolCalls := assistantMessage.ToolCalls()
+		if len(toolCalls) == 0 {
+			state.messages = append(state.messages, assistantMessage)
+			retry, err := state.retryOutput(assistantMessage.Text())
+			if err != nil {
+				return nil, err
+			}
+			if retry {
+				continue
+			}
+			if err := a.deleteCheckpoint(ctx, state.info.RunID); err != nil {
+				return nil, err
 			}
+			return state.result(assistantMessage.Text()), nil
+		}
 
-			g.Go(func() error {
-				result, err := tool.Handler(call.Arguments)
-				if err != nil {
-					return fmt.Errorf("tool %s failed: %w", call.Name, err)
-				}
-				results[i] = toolResult{call: call, output: result}
-				return nil
-			})
+		if !state.addToolCalls(len(toolCalls)) {
+			return nil, state.exceeded(LimitMaxToolCalls)
 		}
 
-		if err := g.Wait(); err != nil {
+		pending := a.pendingApproval(toolCalls)
+		if err := a.saveCheckpoint(ctx, state.snapshot(&assistantMessage, pending)); err != nil {
 			return nil, err
 		}
-
-		var inputItems []any
-		for _, result := range results {
-			callInputItem := a.provider.CreateFunctionCallInput(result.call)
-			inputItems = append(inputItems, callInputItem)
-
-			outputItem := a.provider.CreateFunctionCallOutput(result.call.CallID, result.output)
-			inputItems = append(inputItems, outputItem)
+		if len(pending) > 0 {
+			return state.interrupted(&assistantMessage, pending), nil
 		}
 
-		conversationHistory = append(conversationHistory, inputItems...)
-		input = conversationHistory
+		results, err := a.executeTools(ctx, toolCalls, a.newBatchCheckpoint(state, assistantMessage).done)
+		if err != nil {
+			return nil, state.toolsFailed(ctx, err)
+		}
+		assistantMessage = withToolCallArguments(assistantMessage, toolCalls)
+		state.messages = append(state.messages, assistantMessage, NewToolResultMessage(results...))
+		if err := a.saveCheckpoint(ctx, state.snapshot(nil, nil)); err != nil {
+			return nil, err
+		}
 	}
-
-	return nil, fmt.Errorf("max iterations reached")
 }
 
-// RunStream executes the agent with streaming output, returning a channel of StreamEvents.
-func (a *Agent) RunStream(ctx context.Context, prompt string, history ...[]any) (<-chan StreamEvent, error) {
-	streamProvider, ok := a.provider.(StreamProvider)
-	if !ok {
-		return nil, fmt.Errorf("provider does not support streaming")
+// buildRequest builds the provider request for the conversation, applying the
+// agent's output schema if it has one.
+func (a *Agent) buildRequest(messages []Message, providerTools []any) (any, error) {
+	req := a.provider.BuildRequest(messages, a.systemPrompt, providerTools)
+	if a.outputSchema == nil {
+		return req, nil
 	}
 
-	providerTools := make([]any, len(a.tools))
-	for i, tool := range a.tools {
-		providerTools[i] = a.provider.ConvertTool(tool)
+	structuredProvider, ok := a.provider.(StructuredOutputProvider)
+	if !ok {
+		return nil, fmt.Errorf("provider does not support structured output")
 	}
-
-	var conversationHistory []any
-	if len(history) > 0 && len(history[0]) > 0 {
-		conversationHistory = make([]any, len(history[0]))
-		copy(conversationHistory, history[0])
-	} else if len(a.conversationHistory) > 0 {
-		conversationHistory = make([]any, len(a.conversationHistory))
-		copy(conversationHistory, a.conversationHistory)
+	if err := structuredProvider.SetOutputSchema(req, *a.outputSchema); err != nil {
+		return nil, fmt.Errorf("failed to set output schema: %w", err)
 	}
+	return req, nil
+}
 
-	conversationHistory = append(conversationHistory, prompt)
-	var input any = prompt
-	if len(conversationHistory) > 1 {
-		input = conversationHistory
+// callModel sends a request through the provider and converts the response into an assistant message.
+func (a *Agent) callModel(ctx context.Context, req any) (ModelResponse, error) {
+	resp, err := a.provider.CreateResponse(ctx, req)
+	if err != nil {
+		return ModelResponse{}, fmt.Errorf("failed to create response: %w", err)
 	}
 
-	outEvents := make(chan StreamEvent, 100)
-
-	go a.runStreamLoop(ctx, streamProvider, input, conversationHistory, providerTools, outEvents)
+	toolCalls, err := a.provider.ExtractToolCalls(resp)
+	if err != nil {
+		return ModelResponse{}, fmt.Errorf("failed to extract tool calls: %w", err)
+	}
 
-	return outEvents, nil
+	response := ModelResponse{
+		Message: NewAssistantMessage(a.provider.ExtractText(resp), toolCalls...),
+		Raw:     resp,
+	}
+	if routed, ok := a.provider.(RoutedProvider); ok {
+		response.Provider = routed.RespondedBy(resp)
+	}
+	if usageProvider, ok := a.provider.(UsageProvider); ok {
+		if usage, ok := usageProvider.ExtractUsage(resp); ok {
+			response.Usage = &usage
+		}
+	}
+	return response, nil
 }
 
-func (a *Agent) runStreamLoop(
-	ctx context.Context,
-	streamProvider StreamProvider,
-	input any,
-	conversationHistory []any,
-	providerTools []any,
-	outEvents chan<- StreamEvent,
-) {
-	defer close(outEvents)
-
-	maxIterations := 10
-	for i := 0; i < maxIterations; i++ {
-		req := a.provider.BuildRequest(input, a.systemPrompt, providerTools)
+// streamModel returns a modelCaller that streams the response, forwarding
+// text, tool call and usage events to outEvents while assembling the assistant
+// message.
+func (a *Agent) streamModel(streamProvider StreamProvider, outEvents chan<- StreamEvent) modelCaller {
+	return func(ctx context.Context, req any) (ModelResponse, error) {
 		events, err := streamProvider.CreateResponseStream(ctx, req)
 		if err != nil {
-			outEvents <- StreamEvent{
-				Type:  StreamEventTypeError,
-				Error: fmt.Errorf("failed to create response stream: %w", err),
-			}
-			return
+			return ModelResponse{}, fmt.Errorf("failed to create response stream: %w", err)
 		}
 
 		var toolCalls []ToolCall
+		var deltas strings.Builder
 		var fullText string
+		var usage *Usage
+		var provider string
 
 		for event := range events {
 			switch event.Type {
 			case StreamEventTypeTextDelta:
+				deltas.WriteString(event.Delta)
 				outEvents <- event
 
 			case StreamEventTypeTextDone:
@@ -249,78 +479,254 @@ func (a *Agent) runStreamLoop(
 					outEvents <- event
 				}
 
+			case StreamEventTypeUsage:
+				if event.Usage != nil {
+					usage = event.Usage
+					outEvents <- event
+				}
+
 			case StreamEventTypeError:
-				outEvents <- event
-				return
+				return ModelResponse{}, event.Error
 
 			case StreamEventTypeDone:
+				provider = event.Provider
 			}
 		}
 
-		if len(toolCalls) == 0 {
-			outEvents <- StreamEvent{Type: StreamEventTypeDone}
-			return
+		if fullText == "" {
+			fullText = deltas.String()
 		}
 
-		type toolResult struct {
-			call   ToolCall
-			output string
-		}
+		return ModelResponse{Message: NewAssistantMessage(fullText, toolCalls...), Usage: usage, Provider: provider}, nil
+	}
+}
 
-		results := make([]toolResult, len(toolCalls))
-		g, _ := errgroup.WithContext(ctx)
+// executeTools runs the requested tool calls concurrently and returns their results in call order.
+// Failed calls are retried and then either abort the run or are reported to the
+// model, according to the tool's error policy. Timed out calls are always reported.
+// Arguments edited by the hooks are written back to toolCalls.
+func (a *Agent) executeTools(ctx context.Context, toolCalls []ToolCall, done func(context.Context, ToolCall, ToolResult) error) ([]ToolResult, error) {
+	results := make([]ToolResult, len(toolCalls))
+	g, gctx := errgroup.WithContext(ctx)
+
+	for i, call := range toolCalls {
+		g.Go(func() error {
+			result, err := a.runTool(gctx, &call)
+			toolCalls[i].Arguments = call.Arguments
+			if err != nil {
+				return err
+			}
+			if err := a.hooks.afterToolCall(gctx, call, &result); err != nil {
+				return err
+			}
+			results[i] = result
+			return done(gctx, call, result)
+		})
+	}
 
-		for i, call := range toolCalls {
-			call := call
-			i := i
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
 
-			tool, ok := a.toolMap[call.Name]
-			if !ok {
-				outEvents <- StreamEvent{
-					Type:  StreamEventTypeError,
-					Error: fmt.Errorf("unknown tool: %s", call.Name),
-				}
-				return
-			}
+	return results, nil
+}
 
-			g.Go(func() error {
-				result, err := tool.Handler(call.Arguments)
-				if err != nil {
-					return fmt.Errorf("tool %s failed: %w", call.Name, err)
-				}
-				results[i] = toolResult{call: call, output: result}
-				return nil
-			})
-		}
+// runTool executes a single tool call, after the hooks have seen it. It returns
+// an error only when the failure aborts the run.
+func (a *Agent) runTool(ctx context.Context, call *ToolCall) (ToolResult, error) {
+	hookErr := a.hooks.beforeToolCall(ctx, call)
 
-		if err := g.Wait(); err != nil {
-			outEvents <- StreamEvent{
-				Type:  StreamEventTypeError,
-				Error: err,
-			}
-			return
+	tool, ok := a.toolMap[call.Name]
+	if !ok {
+		if a.toolErrorPolicy.Action != ToolErrorReport {
+			return ToolResult{}, fmt.Errorf("unknown tool: %s", call.Name)
 		}
+		return toolErrorResult(*call, fmt.Errorf("unknown tool: %s", call.Name)), nil
+	}
 
-		var inputItems []any
-		for _, result := range results {
-			callInputItem := a.provider.CreateFunctionCallInput(result.call)
-			inputItems = append(inputItems, callInputItem)
+	policy := a.toolErrorPolicy
+	if tool.ErrorPolicy != nil {
+		policy = *tool.ErrorPolicy
+	}
 
-			outputItem := a.provider.CreateFunctionCallOutput(result.call.CallID, result.output)
-			inputItems = append(inputItems, outputItem)
+	err := hookErr
+	var output string
+	if err == nil {
+		output, err = invokeTool(ctx, tool, *call)
+		for attempt := 0; err != nil && attempt < policy.Retries && retryableToolError(ctx, err); attempt++ {
+			output, err = invokeTool(ctx, tool, *call)
 		}
+	}
+
+	switch {
+	case err == nil:
+		return ToolResult{CallID: call.CallID, Name: call.Name, Output: output}, nil
+	case errors.Is(err, ErrToolTimeout), errors.Is(err, ErrInvalidArguments):
+		return toolErrorResult(*call, err), nil
+	case ctx.Err() != nil:
+		return ToolResult{}, context.Cause(ctx)
+	}
+
+	err = fmt.Errorf("tool %s failed: %w", call.Name, err)
+	if policy.Action != ToolErrorReport {
+		return ToolResult{}, err
+	}
+	return toolErrorResult(*call, err), nil
+}
+
+// invokeTool calls the tool with a context carrying the tool call and the tool's
+// timeout. It stops waiting when the context is done, even if the handler
+// ignores the context and keeps running.
+func invokeTool(ctx context.Context, tool Tool, call ToolCall) (string, error) {
+	ctx = contextWithToolCall(ctx, call)
+	if tool.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, tool.Timeout, ErrToolTimeout)
+		defer cancel()
+	}
+
+	type outcome struct {
+		output string
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		output, err := tool.call(ctx, call.Arguments)
+		done <- outcome{output: output, err: err}
+	}()
+
+	var out outcome
+	select {
+	case out = <-done:
+	case <-ctx.Done():
+		out.err = ctx.Err()
+	}
+
+	if out.err != nil && errors.Is(context.Cause(ctx), ErrToolTimeout) {
+		return "", fmt.Errorf("tool %s timed out after %s: %w", call.Name, tool.Timeout, ErrToolTimeout)
+	}
+	return out.output, out.err
+}
+
+// retryableToolError reports whether a failed tool call may be attempted again.
+// Calls with invalid arguments would fail the same way, so they are not retried.
+func retryableToolError(ctx context.Context, err error) bool {
+	return ctx.Err() == nil && !errors.Is(err, ErrToolTimeout) && !errors.Is(err, ErrInvalidArguments)
+}
 
-		if fullText != "" {
-			assistantMessage := a.provider.CreateAssistantMessage(fullText)
-			conversationHistory = append(conversationHistory, assistantMessage)
+// toolErrorResult reports a failed tool call to the model. Argument violations
+// are reported as JSON listing each violation, so the model can fix the call.
+func toolErrorResult(call ToolCall, err error) ToolResult {
+	output := err.Error()
+
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		report := struct {
+			Error      string      `json:"error"`
+			Violations []Violation `json:"violations"`
+		}{
+			Error:      fmt.Sprintf("invalid arguments for tool %s", call.Name),
+			Violations: validationErr.Violations,
 		}
+		if data, marshalErr := json.Marshal(report); marshalErr == nil {
+			output = string(data)
+		}
+	}
+
+	return ToolResult{
+		CallID:  call.CallID,
+		Name:    call.Name,
+		Output:  output,
+		IsError: true,
+	}
+}
+
+// runState holds the progress of a single run.
+type runState struct {
+	agent     *Agent
+	info      RunInfo
+	messages  []Message
+	turns     int
+	toolCalls int
+	// provider is the provider that produced the latest response.
+	provider  string
+	usage     Usage
+	turnUsage []TurnUsage
+	cost      float64
+	// budgets are the budgets the run draws from, shared with its sub-agents.
+	budgets []*budget
+	// deadline is when the run times out, set when its loop first starts.
+	deadline time.Time
+	// resume is the tool call batch a resumed run starts with.
+	resume *resumedBatch
+	// sessionID is the session the run is saved to on completion, which held
+	// sessionLength messages when the run started.
+	sessionID     string
+	sessionLength int
+	// checkOutput checks the final answer of a RunTyped run, which has taken
+	// outputAttempts retries so far.
+	checkOutput    func(text string) error
+	outputAttempts int
+}
+
+func (a *Agent) newRunState(ctx context.Context, messages []Message) *runState {
+	info := RunInfo{
+		RunID:     newRunID(),
+		AgentName: a.name,
+	}
+	if runID, ok := ctx.Value(runIDKey).(string); ok && runID != "" {
+		info.RunID = runID
+	}
+	if parent, ok := RunInfoFromContext(ctx); ok {
+		info.ParentRunID = parent.RunID
+	}
+	return &runState{
+		agent:    a,
+		info:     info,
+		messages: messages,
+		budgets:  a.runBudgets(ctx),
+	}
+}
+
+func (s *runState) result(text string) *RunResult {
+	return &RunResult{
+		Text:     text,
+		RunID:    s.info.RunID,
+		Provider: s.provider,
+		Usage:    s.usage,
+		Turns:    s.turnUsage,
+		Cost:     s.cost,
+		history:  s.messages,
+	}
+}
 
-		conversationHistory = append(conversationHistory, inputItems...)
-		input = conversationHistory
+// toolsFailed returns the error that stops the run when a tool call batch
+// failed, which is a limit error if the run timed out or ran out of budget.
+func (s *runState) toolsFailed(ctx context.Context, err error) error {
+	if timedOut(ctx) {
+		return s.exceeded(LimitTimeout)
+	}
+	if limit, ok := s.budgetExceeded(); ok && errors.Is(err, ErrBudgetExceeded) {
+		return s.exceeded(limit)
 	}
+	return err
+}
 
-	outEvents <- StreamEvent{
-		Type:  StreamEventTypeError,
-		Error: fmt.Errorf("max iterations reached"),
+// addUsage records the usage of a model call, if it was reported.
+func (s *runState) addUsage(usage *Usage) {
+	if usage == nil {
+		return
+	}
+	turn := TurnUsage{Usage: *usage}
+	if s.agent.pricer != nil {
+		if cost, ok := s.agent.pricer.Cost(*usage); ok {
+			turn.Cost = cost
+		}
+	}
+	s.usage = s.usage.Add(*usage)
+	s.turnUsage = append(s.turnUsage, turn)
+	s.cost += turn.Cost
+	for _, b := range s.budgets {
+		b.charge(*usage, turn.Cost)
 	}
 }
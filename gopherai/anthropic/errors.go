@@ -0,0 +1,57 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+)
+
+const providerName = "anthropic"
+
+// newAPIError converts an error response into a gopherai.APIError, reading
+// the error type and message from the APIError payload when the body holds
+// one.
+func newAPIError(statusCode int, header http.Header, body []byte) *gopherai.APIError {
+	e := gopherai.NewAPIError(providerName, statusCode, header, body)
+
+	var payload APIError
+	if err := json.Unmarshal(body, &payload); err == nil && payload.Error.Type != "" {
+		e.Code = payload.Error.Type
+		e.Message = payload.Error.Message
+		classify(e)
+	}
+	return e
+}
+
+// newStreamError converts an error event received inside a stream.
+func newStreamError(errType, message string) *gopherai.APIError {
+	e := &gopherai.APIError{Provider: providerName, Code: errType, Message: message}
+	e.Retryable = errType == "overloaded_error" || errType == "api_error" || errType == "rate_limit_error"
+	classify(e)
+	return e
+}
+
+// classify sets the kind of error from the error type.
+func classify(e *gopherai.APIError) {
+	switch {
+	case e.Code == "rate_limit_error":
+		e.Kind = gopherai.ErrRateLimited
+	case e.Code == "authentication_error" || e.Code == "permission_error":
+		e.Kind = gopherai.ErrAuth
+	case e.Code == "invalid_request_error" && strings.Contains(e.Message, "prompt is too long"):
+		e.Kind = gopherai.ErrContextLengthExceeded
+	}
+}
+
+// newRefusalError returns the error for a response the model stopped because
+// it refused the request.
+func newRefusalError() *gopherai.APIError {
+	return &gopherai.APIError{
+		Provider: providerName,
+		Code:     "refusal",
+		Message:  "the model refused the request",
+		Kind:     gopherai.ErrContentFiltered,
+	}
+}
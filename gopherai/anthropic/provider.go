@@ -0,0 +1,76 @@
+// Package anthropic provides an Anthropic Messages API provider implementation.
+package anthropic
+
+import "github.com/marti-jorda-roca/gopher-ai/gopherai"
+
+const (
+	defaultBaseURL   = "https://api.anthropic.com/v1"
+	apiVersion       = "2023-06-01"
+	defaultMaxTokens = 4096
+)
+
+// Provider is the Anthropic Messages API provider.
+type Provider struct {
+	client      *gopherai.HTTPClient
+	model       string
+	temperature *float64
+	maxTokens   int
+}
+
+// NewProvider creates a new Anthropic API provider with the given API key.
+func NewProvider(apiKey string) *Provider {
+	p := &Provider{
+		client:    gopherai.NewHTTPClient(defaultBaseURL, gopherai.APIKeyHeader("x-api-key", apiKey), newAPIError),
+		model:     "claude-sonnet-4-5",
+		maxTokens: defaultMaxTokens,
+	}
+	p.client.SetHeader("anthropic-version", apiVersion)
+
+	return p
+}
+
+// SetModel sets the model to use for requests.
+func (p *Provider) SetModel(model string) *Provider {
+	p.model = model
+	return p
+}
+
+// SetTemperature sets the temperature for requests.
+func (p *Provider) SetTemperature(temperature float64) *Provider {
+	p.temperature = &temperature
+	return p
+}
+
+// SetMaxTokens sets the maximum output tokens for requests.
+// The Messages API requires a limit; the default is 4096.
+func (p *Provider) SetMaxTokens(maxTokens int) *Provider {
+	p.maxTokens = maxTokens
+	return p
+}
+
+// SetBaseURL sets a custom base URL for API requests.
+func (p *Provider) SetBaseURL(url string) *Provider {
+	p.client.SetBaseURL(url)
+	return p
+}
+
+// SetAuthenticator replaces the API key authentication, for example with a
+// gateway that signs requests. A nil authenticator sends requests without
+// credentials.
+func (p *Provider) SetAuthenticator(auth gopherai.Authenticator) *Provider {
+	p.client.SetAuthenticator(auth)
+	return p
+}
+
+// SetHeader sets a header sent with every request.
+func (p *Provider) SetHeader(name, value string) *Provider {
+	p.client.SetHeader(name, value)
+	return p
+}
+
+// SetRetryPolicy sets how failed requests are retried. Requests are not
+// retried by default; gopherai.DefaultRetryPolicy is a good starting point.
+func (p *Provider) SetRetryPolicy(policy gopherai.RetryPolicy) *Provider {
+	p.client.SetRetryPolicy(policy)
+	return p
+}
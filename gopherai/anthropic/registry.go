@@ -0,0 +1,29 @@
+package anthropic
+
+import "github.com/marti-jorda-roca/gopher-ai/gopherai"
+
+// init registers the "anthropic" provider URI scheme. The API key is read from
+// ANTHROPIC_API_KEY.
+func init() {
+	gopherai.RegisterProvider("anthropic", gopherai.ProviderRegistration{
+		EnvKeys: []string{"ANTHROPIC_API_KEY"},
+		New:     newFromConfig,
+	})
+}
+
+func newFromConfig(cfg gopherai.ProviderConfig) (gopherai.Provider, error) {
+	p := NewProvider(cfg.APIKey)
+	if cfg.Model != "" {
+		p.SetModel(cfg.Model)
+	}
+	if cfg.BaseURL != "" {
+		p.SetBaseURL(cfg.BaseURL)
+	}
+	if cfg.Temperature != nil {
+		p.SetTemperature(*cfg.Temperature)
+	}
+	if cfg.MaxTokens != nil {
+		p.SetMaxTokens(*cfg.MaxTokens)
+	}
+	return p, nil
+}
@@ -0,0 +1,378 @@
+package anthropic
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+)
+
+// CreateResponse sends a request to the Anthropic Messages API.
+func (p *Provider) CreateResponse(ctx context.Context, req any) (any, error) {
+	messagesReq, ok := req.(*MessagesRequest)
+	if !ok {
+		return nil, fmt.Errorf("invalid request type: expected *MessagesRequest")
+	}
+
+	var result MessagesResponse
+
+	if err := p.client.Do(ctx, http.MethodPost, "/messages", messagesReq, &result); err != nil {
+		return nil, err
+	}
+	if result.StopReason == "refusal" {
+		return nil, newRefusalError()
+	}
+
+	return &result, nil
+}
+
+// ConvertTool converts a gopherai.Tool to an Anthropic Tool.
+func (p *Provider) ConvertTool(tool gopherai.Tool) any {
+	schema := tool.Parameters
+	if schema == nil {
+		schema = map[string]any{"type": "object", "properties": map[string]any{}}
+	}
+	return Tool{
+		Name:        tool.Name,
+		Description: tool.Description,
+		InputSchema: schema,
+	}
+}
+
+// ExtractToolCalls extracts tool calls from the tool_use blocks of a response.
+func (p *Provider) ExtractToolCalls(resp any) ([]gopherai.ToolCall, error) {
+	response, ok := resp.(*MessagesResponse)
+	if !ok {
+		return nil, fmt.Errorf("invalid response type: expected *MessagesResponse")
+	}
+
+	var calls []gopherai.ToolCall
+	for _, block := range response.Content {
+		if block.Type == "tool_use" {
+			calls = append(calls, gopherai.ToolCall{
+				Name:      block.Name,
+				Arguments: toolInput(block.Input),
+				CallID:    block.ID,
+			})
+		}
+	}
+	return calls, nil
+}
+
+// ExtractText extracts the text blocks of a response.
+func (p *Provider) ExtractText(resp any) string {
+	response, ok := resp.(*MessagesResponse)
+	if !ok {
+		return ""
+	}
+
+	var text strings.Builder
+	for _, block := range response.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	return text.String()
+}
+
+// ExtractUsage extracts token usage from a response. Input tokens include
+// those read from and written to the prompt cache.
+func (p *Provider) ExtractUsage(resp any) (gopherai.Usage, bool) {
+	response, ok := resp.(*MessagesResponse)
+	if !ok {
+		return gopherai.Usage{}, false
+	}
+	return toUsage(response.Model, response.Usage), true
+}
+
+func toUsage(model string, usage Usage) gopherai.Usage {
+	return gopherai.Usage{
+		Model:             model,
+		InputTokens:       usage.InputTokens + usage.CacheCreationInputTokens + usage.CacheReadInputTokens,
+		CachedInputTokens: usage.CacheReadInputTokens,
+		OutputTokens:      usage.OutputTokens,
+	}
+}
+
+// BuildRequest builds a MessagesRequest from the given parameters.
+func (p *Provider) BuildRequest(messages []gopherai.Message, systemPrompt string, tools []any) any {
+	anthropicTools := make([]Tool, len(tools))
+	for i, tool := range tools {
+		anthropicTools[i] = tool.(Tool)
+	}
+
+	return &MessagesRequest{
+		Model:       p.model,
+		MaxTokens:   p.maxTokens,
+		System:      systemPrompt,
+		Messages:    ConvertMessages(messages),
+		Tools:       anthropicTools,
+		Temperature: p.temperature,
+	}
+}
+
+// ConvertMessages converts gopherai messages into Anthropic messages.
+// Tool results are sent as tool_result blocks in user messages, and
+// consecutive messages with the same role are merged, since the API requires
+// user and assistant turns to alternate.
+func ConvertMessages(messages []gopherai.Message) []Message {
+	messages = gopherai.NormalizeToolCallIDs(messages)
+	result := make([]Message, 0, len(messages))
+
+	for _, msg := range messages {
+		role := "user"
+		if msg.Role == gopherai.RoleAssistant {
+			role = "assistant"
+		}
+
+		blocks := make([]ContentBlock, 0, len(msg.Parts))
+		for _, part := range msg.Parts {
+			switch part.Type {
+			case gopherai.PartTypeText:
+				if part.Text != "" {
+					blocks = append(blocks, NewTextBlock(part.Text))
+				}
+			case gopherai.PartTypeToolCall:
+				if part.ToolCall == nil {
+					continue
+				}
+				call := part.ToolCall
+				blocks = append(blocks, NewToolUseBlock(call.CallID, call.Name, json.RawMessage(toolInput(json.RawMessage(call.Arguments)))))
+			case gopherai.PartTypeToolResult:
+				if part.ToolResult == nil {
+					continue
+				}
+				res := part.ToolResult
+				blocks = append(blocks, NewToolResultBlock(res.CallID, res.Output, res.IsError))
+			}
+		}
+		if len(blocks) == 0 {
+			continue
+		}
+
+		if n := len(result); n > 0 && result[n-1].Role == role {
+			result[n-1].Content = append(result[n-1].Content, blocks...)
+			continue
+		}
+		result = append(result, Message{Role: role, Content: blocks})
+	}
+
+	return result
+}
+
+// ToMessages converts Anthropic messages into gopherai messages. User
+// messages holding tool_result blocks become tool messages.
+func ToMessages(messages []Message) []gopherai.Message {
+	result := make([]gopherai.Message, 0, len(messages))
+
+	for _, msg := range messages {
+		var text []gopherai.Part
+		var calls []gopherai.Part
+		var results []gopherai.Part
+
+		for _, block := range msg.Content {
+			switch block.Type {
+			case "text":
+				text = append(text, gopherai.TextPart(block.Text))
+			case "tool_use":
+				calls = append(calls, gopherai.ToolCallPart(gopherai.ToolCall{
+					Name:      block.Name,
+					Arguments: toolInput(block.Input),
+					CallID:    block.ID,
+				}))
+			case "tool_result":
+				results = append(results, gopherai.ToolResultPart(gopherai.ToolResult{
+					CallID:  block.ToolUseID,
+					Output:  block.Content,
+					IsError: block.IsError,
+				}))
+			}
+		}
+
+		if msg.Role == "assistant" {
+			result = append(result, gopherai.Message{Role: gopherai.RoleAssistant, Parts: append(text, calls...)})
+			continue
+		}
+		if len(results) > 0 {
+			result = append(result, gopherai.Message{Role: gopherai.RoleTool, Parts: results})
+		}
+		if len(text) > 0 {
+			result = append(result, gopherai.Message{Role: gopherai.RoleUser, Parts: text})
+		}
+	}
+
+	return result
+}
+
+// toolInput returns the JSON object of tool call arguments, defaulting to an
+// empty object for missing or invalid JSON, since the API requires tool_use
+// input to be an object.
+func toolInput(input json.RawMessage) string {
+	trimmed := strings.TrimSpace(string(input))
+	if !json.Valid([]byte(trimmed)) || trimmed == "null" {
+		return "{}"
+	}
+	return trimmed
+}
+
+// CreateResponseStream sends a streaming request to the Anthropic Messages API.
+func (p *Provider) CreateResponseStream(ctx context.Context, req any) (<-chan gopherai.StreamEvent, error) {
+	messagesReq, ok := req.(*MessagesRequest)
+	if !ok {
+		return nil, fmt.Errorf("invalid request type: expected *MessagesRequest")
+	}
+
+	messagesReq.Stream = true
+
+	events := make(chan gopherai.StreamEvent, 100)
+
+	body, err := p.client.Stream(ctx, http.MethodPost, "/messages", messagesReq)
+	if err != nil {
+		close(events)
+		return nil, err
+	}
+
+	go p.parseSSEStream(body, events)
+
+	return events, nil
+}
+
+// ParseSSEStreamForTest exposes stream parsing for testing.
+func ParseSSEStreamForTest(r io.Reader) <-chan gopherai.StreamEvent {
+	events := make(chan gopherai.StreamEvent, 100)
+	go func() {
+		defer close(events)
+		parseSSEStreamReader(r, events)
+	}()
+	return events
+}
+
+func (p *Provider) parseSSEStream(body io.ReadCloser, events chan<- gopherai.StreamEvent) {
+	defer close(events)
+	defer func() { _ = body.Close() }()
+	parseSSEStreamReader(body, events)
+}
+
+func parseSSEStreamReader(r io.Reader, events chan<- gopherai.StreamEvent) {
+	reader := bufio.NewReader(r)
+	var fullText strings.Builder
+	toolCalls := make(map[int]*gopherai.ToolCall)
+	toolInputs := make(map[int]*strings.Builder)
+	var model string
+	var usage Usage
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			if err != io.EOF {
+				events <- gopherai.StreamEvent{
+					Type:  gopherai.StreamEventTypeError,
+					Error: err,
+				}
+			}
+			break
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" || !strings.HasPrefix(line, "data:") {
+			continue
+		}
+
+		var eventData StreamEventData
+		if err := json.Unmarshal([]byte(strings.TrimSpace(strings.TrimPrefix(line, "data:"))), &eventData); err != nil {
+			continue
+		}
+
+		switch eventData.Type {
+		case "message_start":
+			if eventData.Message != nil {
+				model = eventData.Message.Model
+				usage = eventData.Message.Usage
+			}
+
+		case "message_delta":
+			if eventData.Delta != nil && eventData.Delta.StopReason == "refusal" {
+				events <- gopherai.StreamEvent{
+					Type:  gopherai.StreamEventTypeError,
+					Error: newRefusalError(),
+				}
+				return
+			}
+			if delta := eventData.Usage; delta != nil {
+				usage.OutputTokens = delta.OutputTokens
+				if delta.InputTokens > 0 {
+					usage.InputTokens = delta.InputTokens
+					usage.CacheCreationInputTokens = delta.CacheCreationInputTokens
+					usage.CacheReadInputTokens = delta.CacheReadInputTokens
+				}
+			}
+
+		case "content_block_start":
+			if block := eventData.ContentBlock; block != nil && block.Type == "tool_use" {
+				toolCalls[eventData.Index] = &gopherai.ToolCall{CallID: block.ID, Name: block.Name}
+				toolInputs[eventData.Index] = &strings.Builder{}
+			}
+
+		case "content_block_delta":
+			if eventData.Delta == nil {
+				continue
+			}
+			switch eventData.Delta.Type {
+			case "text_delta":
+				fullText.WriteString(eventData.Delta.Text)
+				events <- gopherai.StreamEvent{
+					Type:  gopherai.StreamEventTypeTextDelta,
+					Delta: eventData.Delta.Text,
+				}
+			case "input_json_delta":
+				if input, ok := toolInputs[eventData.Index]; ok {
+					input.WriteString(eventData.Delta.PartialJSON)
+				}
+			}
+
+		case "content_block_stop":
+			if tc, ok := toolCalls[eventData.Index]; ok {
+				tc.Arguments = toolInput(json.RawMessage(toolInputs[eventData.Index].String()))
+				events <- gopherai.StreamEvent{
+					Type:     gopherai.StreamEventTypeToolCall,
+					ToolCall: tc,
+				}
+				delete(toolCalls, eventData.Index)
+				delete(toolInputs, eventData.Index)
+			}
+
+		case "message_stop":
+			if fullText.Len() > 0 {
+				events <- gopherai.StreamEvent{
+					Type: gopherai.StreamEventTypeTextDone,
+					Text: fullText.String(),
+				}
+			}
+			if usage != (Usage{}) {
+				streamUsage := toUsage(model, usage)
+				events <- gopherai.StreamEvent{
+					Type:  gopherai.StreamEventTypeUsage,
+					Usage: &streamUsage,
+				}
+			}
+			events <- gopherai.StreamEvent{
+				Type: gopherai.StreamEventTypeDone,
+			}
+
+		case "error":
+			streamErr := newStreamError("", "stream error")
+			if eventData.Error != nil {
+				streamErr = newStreamError(eventData.Error.Type, eventData.Error.Message)
+			}
+			events <- gopherai.StreamEvent{
+				Type:  gopherai.StreamEventTypeError,
+				Error: streamErr,
+			}
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package gopherai
+
+import (
+	"context"
+	"fmt"
+)
+
+// RunState is the state of a run between two steps: that of a run interrupted
+// for tool call approval, which Agent.Resume continues, or a checkpoint, which
+// Agent.ResumeRun continues. It can be encoded with encoding/json, to resume
+// the run in another process with an agent that has the same tools.
+type RunState struct {
+	RunID string `json:"run_id"`
+	// Messages is the conversation before Response.
+	Messages []Message `json:"messages"`
+	// Response is the model response whose tool calls await approval, or
+	// have not run yet in a checkpoint.
+	Response *Message `json:"response,omitempty"`
+	// Pending lists the tool calls of Response that need a decision.
+	Pending []ToolCall `json:"pending"`
+	// Results holds the results of the tool calls of Response that completed
+	// before a checkpoint. ResumeRun does not run those calls again.
+	Results   []ToolResult `json:"results,omitempty"`
+	Turns     int          `json:"turns"`
+	ToolCalls int          `json:"tool_calls"`
+	Usage     Usage        `json:"usage"`
+	TurnUsage []TurnUsage  `json:"turn_usage,omitempty"`
+	Cost      float64      `json:"cost,omitempty"`
+	// SessionID is the session of a run started by RunSession or
+	// RunStreamSession, which is saved when the resumed run completes.
+	// SessionLength counts the messages the session held before the run.
+	SessionID     string `json:"session_id,omitempty"`
+	SessionLength int    `json:"session_length,omitempty"`
+}
+
+// ToolDecision is a human decision on a tool call awaiting approval.
+type ToolDecision struct {
+	CallID   string
+	Approved bool
+	// Arguments, when set, replace the arguments of an approved call.
+	Arguments string
+	// Reason is reported to the model when the call is rejected.
+	Reason string
+}
+
+// Approve approves a tool call as requested by the model.
+func Approve(callID string) ToolDecision {
+	return ToolDecision{CallID: callID, Approved: true}
+}
+
+// ApproveWithArguments approves a tool call with edited arguments.
+func ApproveWithArguments(callID, arguments string) ToolDecision {
+	return ToolDecision{CallID: callID, Approved: true, Arguments: arguments}
+}
+
+// Reject rejects a tool call. The model is told the call was rejected, and
+// why, instead of receiving its output.
+func Reject(callID, reason string) ToolDecision {
+	return ToolDecision{CallID: callID, Reason: reason}
+}
+
+// PendingToolCalls returns the tool calls awaiting approval in an interrupted
+// run.
+func (r *RunResult) PendingToolCalls() []ToolCall {
+	if r.State == nil {
+		return nil
+	}
+	return r.State.Pending
+}
+
+// Resume continues a run interrupted for tool call approval, with a decision
+// for each pending call. Approved calls run with any edited arguments, which
+// also replace the arguments recorded in the history; rejected calls are
+// reported to the model. The other calls of the same response run as usual.
+// The resumed run may be interrupted again. Runs started by RunTyped are
+// continued with ResumeTyped, to decode their output.
+func (a *Agent) Resume(ctx context.Context, state *RunState, decisions []ToolDecision) (*RunResult, error) {
+	run, err := a.resumeRunState(ctx, state, decisions)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.continueRun(ctx, run, state)
+}
+
+// continueRun runs the loop of a restored run, and saves it to the session
+// it was started for, if any.
+func (a *Agent) continueRun(ctx context.Context, run *runState, state *RunState) (*RunResult, error) {
+	result, err := a.runLoop(ctx, run, a.callModel)
+	if err != nil {
+		return nil, err
+	}
+
+	if state.SessionID != "" {
+		if err := a.finishSession(ctx, state.SessionID, state.Messages[:state.SessionLength], result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// ResumeStream is the streaming counterpart of Resume.
+func (a *Agent) ResumeStream(ctx context.Context, state *RunState, decisions []ToolDecision) (<-chan StreamEvent, error) {
+	run, err := a.resumeRunState(ctx, state, decisions)
+	if err != nil {
+		return nil, err
+	}
+
+	var onComplete func(*RunResult) error
+	if state.SessionID != "" {
+		onComplete = func(result *RunResult) error {
+			return a.finishSession(ctx, state.SessionID, state.Messages[:state.SessionLength], result)
+		}
+	}
+	return a.runStream(ctx, run, onComplete)
+}
+
+// resumeRunState restores the run state of an interrupted run, checking that
+// there is exactly one decision for each pending call.
+func (a *Agent) resumeRunState(ctx context.Context, state *RunState, decisions []ToolDecision) (*runState, error) {
+	if state == nil {
+		return nil, fmt.Errorf("no run state to resume")
+	}
+
+	pending := make(map[string]bool, len(state.Pending))
+	for _, call := range state.Pending {
+		pending[call.CallID] = true
+	}
+	decided := make(map[string]ToolDecision, len(decisions))
+	for _, decision := range decisions {
+		if !pending[decision.CallID] {
+			return nil, fmt.Errorf("tool call %q is not awaiting approval", decision.CallID)
+		}
+		if _, ok := decided[decision.CallID]; ok {
+			return nil, fmt.Errorf("more than one decision for tool call %q", decision.CallID)
+		}
+		decided[decision.CallID] = decision
+	}
+	for _, call := range state.Pending {
+		if _, ok := decided[call.CallID]; !ok {
+			return nil, fmt.Errorf("no decision for tool call %q (%s)", call.CallID, call.Name)
+		}
+	}
+
+	s, err := a.restoreRunState(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+	if s.resume == nil {
+		return s, nil
+	}
+	for _, part := range s.resume.response.Parts {
+		if part.ToolCall == nil {
+			continue
+		}
+		if decision, ok := decided[part.ToolCall.CallID]; ok && decision.Approved && decision.Arguments != "" {
+			part.ToolCall.Arguments = decision.Arguments
+		}
+	}
+	s.resume.decisions = decided
+	return s, nil
+}
+
+// restoreRunState rebuilds the progress of a run from its state. The run
+// starts by running the tool calls of the state's response, if any.
+func (a *Agent) restoreRunState(ctx context.Context, state *RunState) (*runState, error) {
+	if state.SessionLength > len(state.Messages) {
+		return nil, fmt.Errorf("invalid run state: session length %d exceeds %d messages", state.SessionLength, len(state.Messages))
+	}
+	if len(state.Pending) > 0 && state.Response == nil {
+		return nil, fmt.Errorf("invalid run state: pending tool calls without a response")
+	}
+	if len(state.Results) > 0 && state.Response == nil {
+		return nil, fmt.Errorf("invalid run state: tool results without a response")
+	}
+
+	s := a.newRunState(ctx, CloneMessages(state.Messages))
+	s.info.RunID = state.RunID
+	s.turns = state.Turns
+	s.toolCalls = state.ToolCalls
+	s.usage = state.Usage
+	s.turnUsage = append([]TurnUsage(nil), state.TurnUsage...)
+	s.cost = state.Cost
+	s.sessionID = state.SessionID
+	s.sessionLength = state.SessionLength
+	if a.maxTokens > 0 || a.maxCost > 0 {
+		own := s.budgets[len(s.budgets)-1]
+		own.tokens, own.cost = state.Usage.TotalTokens(), state.Cost
+	}
+	if state.Response != nil {
+		s.resume = &resumedBatch{
+			response: CloneMessages([]Message{*state.Response})[0],
+			results:  append([]ToolResult(nil), state.Results...),
+		}
+	}
+	return s, nil
+}
+
+// resumedBatch is the model response of an interrupted run, with the
+// decisions on its tool calls and the results of those that already ran.
+type resumedBatch struct {
+	response  Message
+	decisions map[string]ToolDecision
+	results   []ToolResult
+}
+
+// pendingApproval returns the calls of tools that require approval.
+func (a *Agent) pendingApproval(toolCalls []ToolCall) []ToolCall {
+	var pending []ToolCall
+	for _, call := range toolCalls {
+		if tool, ok := a.toolMap[call.Name]; ok && tool.RequiresApproval {
+			pending = append(pending, call)
+		}
+	}
+	return pending
+}
+
+// runDecidedTools runs the tool calls of a resumed batch, reporting rejected
+// calls to the model instead of running them and skipping those that already
+// ran. Arguments edited by the hooks replace those of the batch's response.
+func (a *Agent) runDecidedTools(ctx context.Context, state *runState, batch *resumedBatch) ([]ToolResult, error) {
+	toolCalls := batch.response.ToolCalls()
+	results := make([]ToolResult, len(toolCalls))
+	completed := make(map[string]ToolResult, len(batch.results))
+	for _, result := range batch.results {
+		completed[result.CallID] = result
+	}
+	checkpoint := a.newBatchCheckpoint(state, batch.response)
+
+	var approved []ToolCall
+	var indexes []int
+	for i, call := range toolCalls {
+		if result, ok := completed[call.CallID]; ok {
+			results[i] = result
+			checkpoint.record(call, result)
+			continue
+		}
+		if decision, ok := batch.decisions[call.CallID]; ok && !decision.Approved {
+			output := "The user rejected this tool call."
+			if decision.Reason != "" {
+				output += " Reason: " + decision.Reason
+			}
+			results[i] = ToolResult{CallID: call.CallID, Name: call.Name, Output: output, IsError: true}
+			checkpoint.record(call, results[i])
+			continue
+		}
+		approved = append(approved, call)
+		indexes = append(indexes, i)
+	}
+
+	approvedResults, err := a.executeTools(ctx, approved, checkpoint.done)
+	if err != nil {
+		return nil, err
+	}
+	batch.response = withToolCallArguments(batch.response, approved)
+	for j, i := range indexes {
+		results[i] = approvedResults[j]
+	}
+	return results, nil
+}
+
+// interrupted stops the run for approval of the pending calls of response.
+func (s *runState) interrupted(response *Message, pending []ToolCall) *RunResult {
+	result := s.result("")
+	result.Interrupted = true
+	result.State = s.snapshot(response, pending)
+	return result
+}
+
+// snapshot returns the state of the run, with the model response whose tool
+// calls have not run yet, if any.
+func (s *runState) snapshot(response *Message, pending []ToolCall) *RunState {
+	return &RunState{
+		RunID:         s.info.RunID,
+		Messages:      s.messages,
+		Response:      response,
+		Pending:       pending,
+		Turns:         s.turns,
+		ToolCalls:     s.toolCalls,
+		Usage:         s.usage,
+		TurnUsage:     s.turnUsage,
+		Cost:          s.cost,
+		SessionID:     s.sessionID,
+		SessionLength: s.sessionLength,
+	}
+}
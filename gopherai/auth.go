@@ -0,0 +1,41 @@
+package gopherai
+
+import "net/http"
+
+// Authenticator adds credentials to outgoing provider requests. Providers call
+// Authenticate on every HTTP request just before it is sent, once the URL,
+// headers and body are final, so an implementation may sign the request. The
+// body can be read through r.GetBody without consuming it.
+type Authenticator interface {
+	Authenticate(r *http.Request) error
+}
+
+// AuthenticatorFunc adapts a function to the Authenticator interface.
+type AuthenticatorFunc func(r *http.Request) error
+
+// Authenticate calls f(r).
+func (f AuthenticatorFunc) Authenticate(r *http.Request) error {
+	return f(r)
+}
+
+// BearerToken returns an Authenticator that sends token in an
+// "Authorization: Bearer" header. An empty token sends no header.
+func BearerToken(token string) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request) error {
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		return nil
+	})
+}
+
+// APIKeyHeader returns an Authenticator that sends key in the named header,
+// such as "api-key" or "x-api-key". An empty key sends no header.
+func APIKeyHeader(name, key string) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request) error {
+		if key != "" {
+			r.Header.Set(name, key)
+		}
+		return nil
+	})
+}
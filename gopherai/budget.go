@@ -0,0 +1,98 @@
+package gopherai
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrBudgetExceeded is matched by errors.Is for a LimitExceededError caused by
+// a token or cost budget, besides ErrLimitExceeded.
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// Budget limit kinds.
+const (
+	LimitTokenBudget LimitKind = "token_budget"
+	LimitCostBudget  LimitKind = "cost_budget"
+)
+
+// WithBudget limits the tokens and the cost in USD a single run may use. The
+// agent checks the budget before each model call, and stops the run with a
+// LimitExceededError once a limit has been reached. A zero limit means no
+// limit. The cost limit needs a Pricer, set with WithPricing.
+//
+// Sub-agents used as tools, through AsTool, draw from the budget of the run
+// that calls them, on top of any budget of their own.
+func WithBudget(maxTokens int, maxCostUSD float64) AgentOption {
+	return func(a *Agent) {
+		a.maxTokens = maxTokens
+		a.maxCost = maxCostUSD
+	}
+}
+
+// budget tracks the usage charged against a run's budget, including that of
+// its sub-agent runs, which may run concurrently.
+type budget struct {
+	maxTokens int
+	maxCost   float64
+	pricer    Pricer
+
+	mu     sync.Mutex
+	tokens int
+	cost   float64
+}
+
+// charge adds the usage of a model call. The cost is computed with the
+// budget's pricer, so that calls by sub-agents without one are charged too.
+func (b *budget) charge(usage Usage, cost float64) {
+	if b.pricer != nil {
+		cost, _ = b.pricer.Cost(usage)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens += usage.TotalTokens()
+	b.cost += cost
+}
+
+// exceeded reports which limit of the budget has been reached, if any.
+func (b *budget) exceeded() (LimitKind, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case b.maxTokens > 0 && b.tokens >= b.maxTokens:
+		return LimitTokenBudget, true
+	case b.maxCost > 0 && b.cost >= b.maxCost:
+		return LimitCostBudget, true
+	}
+	return "", false
+}
+
+// runBudgets returns the budgets a run draws from: those of the runs it was
+// started by, found in ctx, and the agent's own.
+func (a *Agent) runBudgets(ctx context.Context) []*budget {
+	budgets, _ := ctx.Value(budgetsKey).([]*budget)
+	if a.maxTokens <= 0 && a.maxCost <= 0 {
+		return budgets
+	}
+	own := &budget{maxTokens: a.maxTokens, maxCost: a.maxCost, pricer: a.pricer}
+	return append(budgets[:len(budgets):len(budgets)], own)
+}
+
+func contextWithBudgets(ctx context.Context, budgets []*budget) context.Context {
+	if len(budgets) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, budgetsKey, budgets)
+}
+
+// budgetExceeded reports the limit of the first of the run's budgets that has
+// been reached, if any.
+func (s *runState) budgetExceeded() (LimitKind, bool) {
+	for _, b := range s.budgets {
+		if limit, ok := b.exceeded(); ok {
+			return limit, true
+		}
+	}
+	return "", false
+}
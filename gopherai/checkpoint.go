@@ -0,0 +1,200 @@
+package gopherai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrCheckpointNotFound is returned by a Checkpointer when a run has no
+// checkpoint.
+var ErrCheckpointNotFound = errors.New("checkpoint not found")
+
+// Checkpointer persists the progress of runs by run ID, so that a run can be
+// continued with Agent.ResumeRun after the process running it stopped. A
+// checkpoint is the RunState of the run.
+type Checkpointer interface {
+	// Save stores the checkpoint of a run, replacing any previous one.
+	Save(ctx context.Context, state *RunState) error
+	// Load returns the latest checkpoint of a run, or ErrCheckpointNotFound.
+	Load(ctx context.Context, runID string) (*RunState, error)
+	// Delete removes the checkpoint of a run. Deleting a missing checkpoint is
+	// not an error.
+	Delete(ctx context.Context, runID string) error
+}
+
+// WithCheckpointer makes the agent checkpoint its runs, streamed or not, after
+// every model response that calls tools and after every tool call of the
+// response completes. The checkpoint of a run is deleted when the run completes, and kept
+// when it fails or is interrupted, for ResumeRun to continue it. A checkpoint
+// that cannot be saved fails the run.
+func WithCheckpointer(checkpointer Checkpointer) AgentOption {
+	return func(a *Agent) {
+		a.checkpointer = checkpointer
+	}
+}
+
+// ResumeRun continues a run from its latest checkpoint. Tool calls whose
+// results were checkpointed are not run again, while those that had not
+// completed at the checkpoint are. A run interrupted for tool call
+// approval is returned interrupted again, to be continued with Resume.
+//
+// Runs started by RunSession or RunStreamSession are saved to their session
+// when the resumed run completes. Runs started by RunTyped are continued with
+// ResumeRunTyped, to decode their output.
+func (a *Agent) ResumeRun(ctx context.Context, runID string) (*RunResult, error) {
+	run, state, err := a.loadCheckpoint(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	return a.continueCheckpoint(ctx, run, state)
+}
+
+// loadCheckpoint restores the run state of the latest checkpoint of a run.
+func (a *Agent) loadCheckpoint(ctx context.Context, runID string) (*runState, *RunState, error) {
+	if a.checkpointer == nil {
+		return nil, nil, fmt.Errorf("no checkpointer configured")
+	}
+
+	state, err := a.checkpointer.Load(ctx, runID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load checkpoint: %w", err)
+	}
+
+	run, err := a.restoreRunState(ctx, state)
+	if err != nil {
+		return nil, nil, err
+	}
+	return run, state, nil
+}
+
+// continueCheckpoint continues a run restored from a checkpoint, unless it
+// awaits tool call approval.
+func (a *Agent) continueCheckpoint(ctx context.Context, run *runState, state *RunState) (*RunResult, error) {
+	if len(state.Pending) > 0 {
+		return run.interrupted(state.Response, state.Pending), nil
+	}
+	return a.continueRun(ctx, run, state)
+}
+
+func (a *Agent) saveCheckpoint(ctx context.Context, state *RunState) error {
+	if a.checkpointer == nil {
+		return nil
+	}
+	if err := a.checkpointer.Save(ctx, state); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (a *Agent) deleteCheckpoint(ctx context.Context, runID string) error {
+	if a.checkpointer == nil {
+		return nil
+	}
+	if err := a.checkpointer.Delete(ctx, runID); err != nil {
+		return fmt.Errorf("failed to delete checkpoint: %w", err)
+	}
+	return nil
+}
+
+// batchCheckpoint checkpoints a run while the tool calls of a model response
+// run, saving the results completed so far each time a call completes.
+type batchCheckpoint struct {
+	agent    *Agent
+	state    *runState
+	response Message
+
+	mu      sync.Mutex
+	calls   []ToolCall
+	results []ToolResult
+}
+
+func (a *Agent) newBatchCheckpoint(state *runState, response Message) *batchCheckpoint {
+	return &batchCheckpoint{agent: a, state: state, response: response}
+}
+
+// record adds the result of a call without saving the checkpoint.
+func (b *batchCheckpoint) record(call ToolCall, result ToolResult) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.calls = append(b.calls, call)
+	b.results = append(b.results, result)
+}
+
+// done adds the result of a completed call and saves the checkpoint, with the
+// arguments the completed calls ran with.
+func (b *batchCheckpoint) done(ctx context.Context, call ToolCall, result ToolResult) error {
+	if b.agent.checkpointer == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.calls = append(b.calls, call)
+	b.results = append(b.results, result)
+	response := withToolCallArguments(b.response, b.calls)
+	state := b.state.snapshot(&response, nil)
+	state.Results = b.results
+	return b.agent.saveCheckpoint(ctx, state)
+}
+
+// cloneRunState returns a deep copy of the state.
+func cloneRunState(state *RunState) *RunState {
+	clone := *state
+	clone.Messages = CloneMessages(state.Messages)
+	if state.Response != nil {
+		response := CloneMessages([]Message{*state.Response})[0]
+		clone.Response = &response
+	}
+	clone.Pending = append([]ToolCall(nil), state.Pending...)
+	clone.Results = append([]ToolResult(nil), state.Results...)
+	clone.TurnUsage = append([]TurnUsage(nil), state.TurnUsage...)
+	return &clone
+}
+
+// MemoryCheckpointer is a Checkpointer that keeps checkpoints in memory, so
+// they only survive the process for runs resumed by the same process.
+type MemoryCheckpointer struct {
+	mu          sync.RWMutex
+	checkpoints map[string]*RunState
+}
+
+// NewMemoryCheckpointer creates an empty in-memory checkpointer.
+func NewMemoryCheckpointer() *MemoryCheckpointer {
+	return &MemoryCheckpointer{
+		checkpoints: make(map[string]*RunState),
+	}
+}
+
+// Save stores the checkpoint of a run.
+func (c *MemoryCheckpointer) Save(_ context.Context, state *RunState) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checkpoints[state.RunID] = cloneRunState(state)
+	return nil
+}
+
+// Load returns the latest checkpoint of a run.
+func (c *MemoryCheckpointer) Load(_ context.Context, runID string) (*RunState, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	state, ok := c.checkpoints[runID]
+	if !ok {
+		return nil, ErrCheckpointNotFound
+	}
+	return cloneRunState(state), nil
+}
+
+// Delete removes the checkpoint of a run.
+func (c *MemoryCheckpointer) Delete(_ context.Context, runID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.checkpoints, runID)
+	return nil
+}
@@ -0,0 +1,113 @@
+package gopherai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// CheckpointVersion is the current version of the checkpoint file format.
+const CheckpointVersion = 1
+
+// checkpointFile is the versioned JSON representation of a checkpoint.
+type checkpointFile struct {
+	Version   int       `json:"version"`
+	State     *RunState `json:"state"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// FileCheckpointer is a Checkpointer that keeps the checkpoint of each run in
+// a JSON file named after its run ID. Run IDs may only contain letters,
+// digits, '.', '_' and '-', and must not start with '.'.
+type FileCheckpointer struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileCheckpointer creates a file-system checkpointer rooted at dir,
+// creating the directory if it does not exist.
+func NewFileCheckpointer(dir string) (*FileCheckpointer, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+	return &FileCheckpointer{dir: dir}, nil
+}
+
+// Save stores the checkpoint of a run, replacing its file atomically.
+func (c *FileCheckpointer) Save(_ context.Context, state *RunState) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	path, err := c.path(state.RunID)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(checkpointFile{
+		Version:   CheckpointVersion,
+		State:     state,
+		UpdatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+
+	if err := writeFileAtomic(c.dir, state.RunID, path, data); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// Load returns the latest checkpoint of a run.
+func (c *FileCheckpointer) Load(_ context.Context, runID string) (*RunState, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	path, err := c.path(runID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path) // #nosec G304 -- path is built from a validated run ID
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrCheckpointNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var file checkpointFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode checkpoint: %w", err)
+	}
+	if file.Version < 1 || file.Version > CheckpointVersion {
+		return nil, fmt.Errorf("unsupported checkpoint version: %d", file.Version)
+	}
+	if file.State == nil {
+		return nil, fmt.Errorf("failed to decode checkpoint: no run state")
+	}
+	return file.State, nil
+}
+
+// Delete removes the checkpoint of a run.
+func (c *FileCheckpointer) Delete(_ context.Context, runID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	path, err := c.path(runID)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (c *FileCheckpointer) path(runID string) (string, error) {
+	return jsonFilePath(c.dir, "run", runID)
+}
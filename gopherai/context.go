@@ -0,0 +1,75 @@
+package gopherai
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+type contextKey int
+
+const (
+	runInfoKey contextKey = iota
+	toolCallKey
+	budgetsKey
+	runIDKey
+)
+
+// RunInfo identifies an agent run. It is attached to the context passed to
+// context-aware tool handlers.
+type RunInfo struct {
+	RunID     string
+	AgentName string
+	// ParentRunID is the ID of the run that started this one, for example
+	// when the agent runs as a sub-agent tool.
+	ParentRunID string
+}
+
+// RunInfoFromContext returns the run the context belongs to.
+func RunInfoFromContext(ctx context.Context) (RunInfo, bool) {
+	info, ok := ctx.Value(runInfoKey).(RunInfo)
+	return info, ok
+}
+
+// ToolCallFromContext returns the tool call a context-aware handler is executing.
+func ToolCallFromContext(ctx context.Context) (ToolCall, bool) {
+	call, ok := ctx.Value(toolCallKey).(ToolCall)
+	return call, ok
+}
+
+// ContextWithRunID returns a context for which the run started with it uses
+// runID instead of a random ID, for example to checkpoint a job under its own
+// ID. Runs it starts, such as sub-agent runs, still get random IDs.
+func ContextWithRunID(ctx context.Context, runID string) context.Context {
+	return context.WithValue(ctx, runIDKey, runID)
+}
+
+// contextWithRunInfo attaches the run to the context, dropping any run ID
+// requested with ContextWithRunID, which the run has used.
+func contextWithRunInfo(ctx context.Context, info RunInfo) context.Context {
+	if _, ok := ctx.Value(runIDKey).(string); ok {
+		ctx = context.WithValue(ctx, runIDKey, nil)
+	}
+	return context.WithValue(ctx, runInfoKey, info)
+}
+
+func contextWithToolCall(ctx context.Context, call ToolCall) context.Context {
+	return context.WithValue(ctx, toolCallKey, call)
+}
+
+// newRunID returns a random run identifier.
+func newRunID() string {
+	return "run_" + randomHex()
+}
+
+// NewToolCallID returns a random tool call ID, for providers whose responses
+// do not identify tool calls.
+func NewToolCallID() string {
+	return "call_" + randomHex()
+}
+
+func randomHex() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,105 @@
+package gopherai
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Sentinel errors matched by errors.Is on an *APIError, whichever provider
+// returned it.
+var (
+	// ErrRateLimited reports that a rate limit or quota was exceeded.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrContextLengthExceeded reports that the request did not fit in the
+	// model's context window.
+	ErrContextLengthExceeded = errors.New("context length exceeded")
+	// ErrContentFiltered reports that the request or response was blocked by
+	// a content filter.
+	ErrContentFiltered = errors.New("content filtered")
+	// ErrAuth reports missing, invalid or insufficient credentials.
+	ErrAuth = errors.New("authentication failed")
+)
+
+// APIError is returned by providers when the API answers with an error. Use
+// errors.As to inspect it, or errors.Is with the sentinel errors above to
+// handle kinds of failure the same way across providers.
+type APIError struct {
+	// Provider names the provider, such as "openai" or "anthropic".
+	Provider string
+	// StatusCode is the HTTP status, or 0 for errors reported inside a stream.
+	StatusCode int
+	// Code is the provider's error code or type, such as
+	// "context_length_exceeded" or "RESOURCE_EXHAUSTED".
+	Code    string
+	Message string
+	// RequestID is the provider's ID for the request, for support tickets.
+	RequestID string
+	// Retryable reports whether the same request may succeed if sent again.
+	Retryable bool
+	// RetryAfter is the delay the provider asked for before retrying, if any.
+	RetryAfter time.Duration
+	// Body is the raw response body.
+	Body []byte
+	// Kind is the sentinel error the failure maps to, or nil.
+	Kind error
+}
+
+// NewAPIError creates an APIError for an HTTP error response. It classifies
+// the error by status code; providers then fill in Code and Message from the
+// body and refine Kind and Retryable where the code says more.
+func NewAPIError(provider string, statusCode int, header http.Header, body []byte) *APIError {
+	e := &APIError{
+		Provider:   provider,
+		StatusCode: statusCode,
+		Message:    strings.TrimSpace(string(body)),
+		Retryable:  IsRetryableStatus(statusCode),
+		Body:       body,
+	}
+	for _, name := range []string{"x-request-id", "request-id"} {
+		if id := header.Get(name); id != "" {
+			e.RequestID = id
+			break
+		}
+	}
+	if delay, ok := RetryDelay(&http.Response{Header: header}, time.Now()); ok {
+		e.RetryAfter = delay
+	}
+
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		e.Kind = ErrAuth
+	case http.StatusTooManyRequests:
+		e.Kind = ErrRateLimited
+	}
+	return e
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Provider)
+	b.WriteString(" API error")
+	switch {
+	case e.StatusCode != 0 && e.Code != "":
+		fmt.Fprintf(&b, " (%d %s)", e.StatusCode, e.Code)
+	case e.StatusCode != 0:
+		fmt.Fprintf(&b, " (%d)", e.StatusCode)
+	case e.Code != "":
+		fmt.Fprintf(&b, " (%s)", e.Code)
+	}
+	if e.Message != "" {
+		b.WriteString(": ")
+		b.WriteString(e.Message)
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, " [request %s]", e.RequestID)
+	}
+	return b.String()
+}
+
+// Unwrap returns Kind, so that errors.Is matches the sentinel errors.
+func (e *APIError) Unwrap() error {
+	return e.Kind
+}
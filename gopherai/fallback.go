@@ -0,0 +1,332 @@
+package gopherai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// RoutedProvider is implemented by providers that send each request to one
+// of several providers, to report which one produced a response. The agent
+// records it in RunResult.Provider.
+type RoutedProvider interface {
+	Provider
+	RespondedBy(resp any) string
+}
+
+// FallbackProvider sends each request to the first of an ordered list of
+// providers, and fails over to the next one when a provider fails with an
+// error that another provider may not have, such as a rate limit or an
+// outage. The providers may be of different vendors: each request is built
+// from the provider-agnostic conversation by the provider that sends it.
+//
+// Streaming requests fail over only while no event has been delivered.
+// Providers that do not support streaming answer streaming requests with a
+// single response, sent as events. The done event of a stream names the
+// provider that produced it.
+type FallbackProvider struct {
+	entries    []fallbackEntry
+	fallbackOn func(err error) bool
+}
+
+type fallbackEntry struct {
+	name     string
+	provider Provider
+}
+
+// fallbackRequest holds what is needed to build a request for any provider.
+type fallbackRequest struct {
+	messages     []Message
+	systemPrompt string
+	tools        []Tool
+	outputSchema *OutputSchema
+}
+
+// fallbackResponse is a response together with the provider that produced it.
+type fallbackResponse struct {
+	entry fallbackEntry
+	resp  any
+}
+
+// NewFallbackProvider creates an empty FallbackProvider. Add providers in the
+// order they should be tried.
+func NewFallbackProvider() *FallbackProvider {
+	return &FallbackProvider{fallbackOn: ShouldFallback}
+}
+
+// Add appends a provider, under a name that is reported in RunResult.Provider
+// and in errors.
+func (f *FallbackProvider) Add(name string, provider Provider) *FallbackProvider {
+	f.entries = append(f.entries, fallbackEntry{name: name, provider: provider})
+	return f
+}
+
+// SetFallbackOn replaces ShouldFallback as the check of which errors make the
+// provider try the next one.
+func (f *FallbackProvider) SetFallbackOn(fallbackOn func(err error) bool) *FallbackProvider {
+	f.fallbackOn = fallbackOn
+	return f
+}
+
+// ShouldFallback reports whether err may not happen with another provider:
+// retryable API errors, rate limits and network errors.
+func ShouldFallback(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable || errors.Is(apiErr, ErrRateLimited)
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// ConvertTool returns the tool unchanged; each provider converts it when it
+// builds its request.
+func (f *FallbackProvider) ConvertTool(tool Tool) any {
+	return tool
+}
+
+// BuildRequest records the conversation so that it can be sent to any of the
+// providers.
+func (f *FallbackProvider) BuildRequest(messages []Message, systemPrompt string, tools []any) any {
+	req := &fallbackRequest{messages: messages, systemPrompt: systemPrompt}
+	for _, tool := range tools {
+		req.tools = append(req.tools, tool.(Tool))
+	}
+	return req
+}
+
+// SetOutputSchema records the schema, which is applied to the request of
+// each provider. Providers without structured output support are skipped.
+func (f *FallbackProvider) SetOutputSchema(req any, schema OutputSchema) error {
+	fallbackReq, ok := req.(*fallbackRequest)
+	if !ok {
+		return fmt.Errorf("invalid request type: expected a FallbackProvider request")
+	}
+	fallbackReq.outputSchema = &schema
+	return nil
+}
+
+// CreateResponse sends the request to each provider in turn until one
+// succeeds or fails with an error that does not warrant a fallback.
+func (f *FallbackProvider) CreateResponse(ctx context.Context, req any) (any, error) {
+	fallbackReq, ok := req.(*fallbackRequest)
+	if !ok {
+		return nil, fmt.Errorf("invalid request type: expected a FallbackProvider request")
+	}
+
+	var errs []error
+	for _, entry := range f.entries {
+		providerReq, err := fallbackReq.buildFor(entry.provider)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.name, err))
+			continue
+		}
+
+		resp, err := entry.provider.CreateResponse(ctx, providerReq)
+		if err == nil {
+			return &fallbackResponse{entry: entry, resp: resp}, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", entry.name, err))
+		if !f.fallbackOn(err) {
+			break
+		}
+	}
+	return nil, f.failed(errs)
+}
+
+// CreateResponseStream opens a stream with each provider in turn until one
+// delivers a first event that is not an error warranting a fallback.
+func (f *FallbackProvider) CreateResponseStream(ctx context.Context, req any) (<-chan StreamEvent, error) {
+	fallbackReq, ok := req.(*fallbackRequest)
+	if !ok {
+		return nil, fmt.Errorf("invalid request type: expected a FallbackProvider request")
+	}
+
+	var errs []error
+	for _, entry := range f.entries {
+		events, first, err := f.openStream(ctx, fallbackReq, entry)
+		if err == nil {
+			return forwardStream(ctx, entry.name, first, events), nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", entry.name, err))
+		if !f.fallbackOn(err) {
+			break
+		}
+	}
+	return nil, f.failed(errs)
+}
+
+// openStream opens a stream with a provider and waits for its first event.
+// An error event is returned as the error, since nothing has been delivered,
+// and the rest of a stream that is not returned is drained.
+func (f *FallbackProvider) openStream(ctx context.Context, req *fallbackRequest, entry fallbackEntry) (<-chan StreamEvent, *StreamEvent, error) {
+	providerReq, err := req.buildFor(entry.provider)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	streamProvider, ok := entry.provider.(StreamProvider)
+	if !ok {
+		resp, err := entry.provider.CreateResponse(ctx, providerReq)
+		if err != nil {
+			return nil, nil, err
+		}
+		events, err := responseEvents(entry.provider, resp)
+		return events, nil, err
+	}
+
+	events, err := streamProvider.CreateResponseStream(ctx, providerReq)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	select {
+	case <-ctx.Done():
+		go drain(events)
+		return nil, nil, ctx.Err()
+	case first, ok := <-events:
+		if !ok {
+			return events, nil, nil
+		}
+		if first.Type == StreamEventTypeError && first.Error != nil {
+			go drain(events)
+			return nil, nil, first.Error
+		}
+		return events, &first, nil
+	}
+}
+
+// RespondedBy returns the name of the provider that produced a response
+// returned by CreateResponse.
+func (f *FallbackProvider) RespondedBy(resp any) string {
+	if fallbackResp, ok := resp.(*fallbackResponse); ok {
+		return fallbackResp.entry.name
+	}
+	return ""
+}
+
+// ExtractToolCalls extracts tool calls using the provider that produced the response.
+func (f *FallbackProvider) ExtractToolCalls(resp any) ([]ToolCall, error) {
+	fallbackResp, ok := resp.(*fallbackResponse)
+	if !ok {
+		return nil, fmt.Errorf("invalid response type: expected a FallbackProvider response")
+	}
+	return fallbackResp.entry.provider.ExtractToolCalls(fallbackResp.resp)
+}
+
+// ExtractText extracts text using the provider that produced the response.
+func (f *FallbackProvider) ExtractText(resp any) string {
+	fallbackResp, ok := resp.(*fallbackResponse)
+	if !ok {
+		return ""
+	}
+	return fallbackResp.entry.provider.ExtractText(fallbackResp.resp)
+}
+
+// ExtractUsage extracts token usage using the provider that produced the
+// response, if it reports usage.
+func (f *FallbackProvider) ExtractUsage(resp any) (Usage, bool) {
+	fallbackResp, ok := resp.(*fallbackResponse)
+	if !ok {
+		return Usage{}, false
+	}
+	usageProvider, ok := fallbackResp.entry.provider.(UsageProvider)
+	if !ok {
+		return Usage{}, false
+	}
+	return usageProvider.ExtractUsage(fallbackResp.resp)
+}
+
+func (f *FallbackProvider) failed(errs []error) error {
+	if len(errs) == 0 {
+		return fmt.Errorf("no providers to fall back on")
+	}
+	return fmt.Errorf("all providers failed: %w", errors.Join(errs...))
+}
+
+// buildFor builds the request for a provider from the conversation.
+func (r *fallbackRequest) buildFor(provider Provider) (any, error) {
+	tools := make([]any, len(r.tools))
+	for i, tool := range r.tools {
+		tools[i] = provider.ConvertTool(tool)
+	}
+	req := provider.BuildRequest(r.messages, r.systemPrompt, tools)
+
+	if r.outputSchema != nil {
+		structuredProvider, ok := provider.(StructuredOutputProvider)
+		if !ok {
+			return nil, fmt.Errorf("provider does not support structured output")
+		}
+		if err := structuredProvider.SetOutputSchema(req, *r.outputSchema); err != nil {
+			return nil, fmt.Errorf("failed to set output schema: %w", err)
+		}
+	}
+	return req, nil
+}
+
+// responseEvents converts a complete response into stream events.
+func responseEvents(provider Provider, resp any) (<-chan StreamEvent, error) {
+	toolCalls, err := provider.ExtractToolCalls(resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract tool calls: %w", err)
+	}
+
+	text := provider.ExtractText(resp)
+	events := make(chan StreamEvent, len(toolCalls)+4)
+	if text != "" {
+		events <- StreamEvent{Type: StreamEventTypeTextDelta, Delta: text}
+		events <- StreamEvent{Type: StreamEventTypeTextDone, Text: text}
+	}
+	for _, call := range toolCalls {
+		events <- StreamEvent{Type: StreamEventTypeToolCall, ToolCall: &call}
+	}
+	if usageProvider, ok := provider.(UsageProvider); ok {
+		if usage, ok := usageProvider.ExtractUsage(resp); ok {
+			events <- StreamEvent{Type: StreamEventTypeUsage, Usage: &usage}
+		}
+	}
+	events <- StreamEvent{Type: StreamEventTypeDone}
+	close(events)
+	return events, nil
+}
+
+// forwardStream returns a channel delivering first, if not nil, followed by
+// the remaining events, with done events naming the provider. When ctx is
+// done, the remaining events are drained in the background, so that the
+// provider's stream can finish.
+func forwardStream(ctx context.Context, provider string, first *StreamEvent, events <-chan StreamEvent) <-chan StreamEvent {
+	out := make(chan StreamEvent, 100)
+	go func() {
+		defer close(out)
+		if first != nil {
+			out <- withProvider(*first, provider)
+		}
+		for event := range events {
+			select {
+			case out <- withProvider(event, provider):
+			case <-ctx.Done():
+				go drain(events)
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// withProvider names the provider on a done event.
+func withProvider(event StreamEvent, provider string) StreamEvent {
+	if event.Type == StreamEventTypeDone {
+		event.Provider = provider
+	}
+	return event
+}
+
+// drain discards the remaining events of a stream until it is closed.
+func drain(events <-chan StreamEvent) {
+	for range events {
+	}
+}
@@ -0,0 +1,75 @@
+package gemini
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+)
+
+const providerName = "gemini"
+
+// newAPIError converts an error response into a gopherai.APIError, reading
+// the status and message from the APIError payload when the body holds one.
+// Streamed error responses arrive as a JSON array holding the payload.
+func newAPIError(statusCode int, header http.Header, body []byte) *gopherai.APIError {
+	e := gopherai.NewAPIError(providerName, statusCode, header, body)
+
+	var payload APIError
+	if err := json.Unmarshal(body, &payload); err != nil {
+		var payloads []APIError
+		if err := json.Unmarshal(body, &payloads); err == nil && len(payloads) > 0 {
+			payload = payloads[0]
+		}
+	}
+	if payload.Error.Message == "" {
+		return e
+	}
+
+	e.Message = payload.Error.Message
+	e.Code = payload.Error.Status
+	switch {
+	case e.Code == "RESOURCE_EXHAUSTED":
+		e.Kind = gopherai.ErrRateLimited
+	case e.Code == "UNAUTHENTICATED" || e.Code == "PERMISSION_DENIED" || strings.Contains(e.Message, "API key not valid"):
+		e.Kind = gopherai.ErrAuth
+	case strings.Contains(e.Message, "exceeds the maximum number of tokens"):
+		e.Kind = gopherai.ErrContextLengthExceeded
+	}
+	return e
+}
+
+// blockedFinishReasons are the finish reasons of candidates stopped by a
+// content filter.
+var blockedFinishReasons = map[string]bool{
+	"SAFETY":             true,
+	"BLOCKLIST":          true,
+	"PROHIBITED_CONTENT": true,
+	"SPII":               true,
+	"IMAGE_SAFETY":       true,
+}
+
+// newBlockedError returns an error matching gopherai.ErrContentFiltered when
+// the prompt or a candidate of the response was blocked, or nil.
+func newBlockedError(resp *GenerateContentResponse) *gopherai.APIError {
+	if resp.PromptFeedback != nil && resp.PromptFeedback.BlockReason != "" {
+		return &gopherai.APIError{
+			Provider: providerName,
+			Code:     resp.PromptFeedback.BlockReason,
+			Message:  "prompt was blocked",
+			Kind:     gopherai.ErrContentFiltered,
+		}
+	}
+	for _, candidate := range resp.Candidates {
+		if blockedFinishReasons[candidate.FinishReason] {
+			return &gopherai.APIError{
+				Provider: providerName,
+				Code:     candidate.FinishReason,
+				Message:  "response was blocked",
+				Kind:     gopherai.ErrContentFiltered,
+			}
+		}
+	}
+	return nil
+}
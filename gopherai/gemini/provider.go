@@ -1,9 +1,7 @@
 // Package gemini provides a Google Gemini API provider implementation.
 package gemini
 
-import (
-	"github.com/go-resty/resty/v2"
-)
+import "github.com/marti-jorda-roca/gopher-ai/gopherai"
 
 const (
 	defaultBaseURL = "https://generativelanguage.googleapis.com/v1beta"
@@ -11,9 +9,7 @@ const (
 
 // Provider is the Google Gemini API provider.
 type Provider struct {
-	apiKey      string
-	baseURL     string
-	http        *resty.Client
+	client      *gopherai.HTTPClient
 	model       string
 	temperature *float64
 	maxTokens   *int
@@ -21,18 +17,10 @@ type Provider struct {
 
 // NewProvider creates a new Gemini API provider with the given API key.
 func NewProvider(apiKey string) *Provider {
-	p := &Provider{
-		apiKey:  apiKey,
-		baseURL: defaultBaseURL,
-		http:    resty.New(),
-		model:   "gemini-2.5-flash",
+	return &Provider{
+		client: gopherai.NewHTTPClient(defaultBaseURL, gopherai.APIKeyHeader("x-goog-api-key", apiKey), newAPIError),
+		model:  "gemini-2.5-flash",
 	}
-
-	p.http.SetBaseURL(p.baseURL)
-	p.http.SetHeader("x-goog-api-key", apiKey)
-	p.http.SetHeader("Content-Type", "application/json")
-
-	return p
 }
 
 // SetModel sets the model to use for requests.
@@ -55,7 +43,27 @@ func (p *Provider) SetMaxTokens(maxTokens int) *Provider {
 
 // SetBaseURL sets a custom base URL for API requests.
 func (p *Provider) SetBaseURL(url string) *Provider {
-	p.baseURL = url
-	p.http.SetBaseURL(url)
+	p.client.SetBaseURL(url)
+	return p
+}
+
+// SetAuthenticator replaces the API key authentication, for example with a
+// gateway that signs requests. A nil authenticator sends requests without
+// credentials.
+func (p *Provider) SetAuthenticator(auth gopherai.Authenticator) *Provider {
+	p.client.SetAuthenticator(auth)
+	return p
+}
+
+// SetHeader sets a header sent with every request.
+func (p *Provider) SetHeader(name, value string) *Provider {
+	p.client.SetHeader(name, value)
+	return p
+}
+
+// SetRetryPolicy sets how failed requests are retried. Requests are not
+// retried by default; gopherai.DefaultRetryPolicy is a good starting point.
+func (p *Provider) SetRetryPolicy(policy gopherai.RetryPolicy) *Provider {
+	p.client.SetRetryPolicy(policy)
 	return p
 }
@@ -0,0 +1,29 @@
+package gemini
+
+import "github.com/marti-jorda-roca/gopher-ai/gopherai"
+
+// init registers the "gemini" provider URI scheme. The API key is read from
+// GEMINI_API_KEY or GOOGLE_API_KEY.
+func init() {
+	gopherai.RegisterProvider("gemini", gopherai.ProviderRegistration{
+		EnvKeys: []string{"GEMINI_API_KEY", "GOOGLE_API_KEY"},
+		New:     newFromConfig,
+	})
+}
+
+func newFromConfig(cfg gopherai.ProviderConfig) (gopherai.Provider, error) {
+	p := NewProvider(cfg.APIKey)
+	if cfg.Model != "" {
+		p.SetModel(cfg.Model)
+	}
+	if cfg.BaseURL != "" {
+		p.SetBaseURL(cfg.BaseURL)
+	}
+	if cfg.Temperature != nil {
+		p.SetTemperature(*cfg.Temperature)
+	}
+	if cfg.MaxTokens != nil {
+		p.SetMaxTokens(*cfg.MaxTokens)
+	}
+	return p, nil
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 	"strings"
 
 	"github.com/marti-jorda-roca/gopher-ai/gopherai"
@@ -19,22 +20,15 @@ func (p *Provider) CreateResponse(ctx context.Context, req any) (any, error) {
 	}
 
 	var result GenerateContentResponse
-	var apiErr APIError
 
 	endpoint := fmt.Sprintf("/models/%s:generateContent", p.model)
-	resp, err := p.http.R().
-		SetContext(ctx).
-		SetBody(generateReq).
-		SetResult(&result).
-		SetError(&apiErr).
-		Post(endpoint)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+	if err := p.client.Do(ctx, http.MethodPost, endpoint, generateReq, &result); err != nil {
+		return nil, err
 	}
-
-	if resp.IsError() {
-		return nil, fmt.Errorf("API error: %d - %s", apiErr.Error.Code, apiErr.Error.Message)
+	if blocked := newBlockedError(&result); blocked != nil {
+		return nil, blocked
 	}
+	generateReq.decodeResponse(&result)
 
 	return &result, nil
 }
@@ -44,63 +38,7 @@ func (p *Provider) ConvertTool(tool gopherai.Tool) any {
 	return FunctionDeclaration{
 		Name:        tool.Name,
 		Description: tool.Description,
-		Parameters:  convertParameters(tool.Parameters),
-	}
-}
-
-// convertParameters converts a map[string]any parameters to FunctionParameters.
-func convertParameters(params map[string]any) *FunctionParameters {
-	if params == nil {
-		return &FunctionParameters{
-			Type:       "object",
-			Properties: make(map[string]PropertySchema),
-		}
-	}
-
-	properties := make(map[string]PropertySchema)
-	required := []string{}
-
-	if requiredList, ok := params["required"].([]any); ok {
-		for _, req := range requiredList {
-			if reqStr, ok := req.(string); ok {
-				required = append(required, reqStr)
-			}
-		}
-	}
-
-	if props, ok := params["properties"].(map[string]any); ok {
-		for name, prop := range props {
-			if propMap, ok := prop.(map[string]any); ok {
-				schema := PropertySchema{}
-				if typ, ok := propMap["type"].(string); ok {
-					schema.Type = typ
-				}
-				if desc, ok := propMap["description"].(string); ok {
-					schema.Description = desc
-				}
-				if items, ok := propMap["items"].(map[string]any); ok {
-					if itemType, ok := items["type"].(string); ok {
-						schema.Items = &PropertySchema{Type: itemType}
-					}
-				}
-				if enum, ok := propMap["enum"].([]any); ok {
-					enumStrs := make([]string, len(enum))
-					for i, e := range enum {
-						if eStr, ok := e.(string); ok {
-							enumStrs[i] = eStr
-						}
-					}
-					schema.Enum = enumStrs
-				}
-				properties[name] = schema
-			}
-		}
-	}
-
-	return &FunctionParameters{
-		Type:       "object",
-		Properties: properties,
-		Required:   required,
+		Parameters:  ConvertSchema(tool.Parameters),
 	}
 }
 
@@ -122,7 +60,7 @@ func (p *Provider) ExtractToolCalls(resp any) ([]gopherai.ToolCall, error) {
 				calls = append(calls, gopherai.ToolCall{
 					Name:      part.FunctionCall.Name,
 					Arguments: string(argsJSON),
-					CallID:    fmt.Sprintf("%d", len(calls)),
+					CallID:    gopherai.NewToolCallID(),
 				})
 			}
 		}
@@ -147,36 +85,34 @@ func (p *Provider) ExtractText(resp any) string {
 	return ""
 }
 
-// BuildRequest builds a GenerateContentRequest from the given parameters.
-func (p *Provider) BuildRequest(input any, systemPrompt string, tools []any) any {
-	var contents []Content
-
-	if inputStr, ok := input.(string); ok {
-		contents = []Content{
-			{
-				Role: "user",
-				Parts: []Part{
-					{Text: inputStr},
-				},
-			},
-		}
-	} else if inputItems, ok := input.([]any); ok {
-		contents = make([]Content, 0, len(inputItems))
-		for _, item := range inputItems {
-			if content, ok := item.(Content); ok {
-				contents = append(contents, content)
-			} else if inputStr, ok := item.(string); ok {
-				contents = append(contents, Content{
-					Role: "user",
-					Parts: []Part{
-						{Text: inputStr},
-					},
-				})
-			}
-		}
-		contents = p.fixFunctionResponseNames(contents)
+// ExtractUsage extracts token usage from a response.
+func (p *Provider) ExtractUsage(resp any) (gopherai.Usage, bool) {
+	response, ok := resp.(*GenerateContentResponse)
+	if !ok || response.UsageMetadata == nil {
+		return gopherai.Usage{}, false
+	}
+	usage := toUsage(response)
+	if usage.Model == "" {
+		usage.Model = p.model
+	}
+	return usage, true
+}
+
+// toUsage converts the usage of a response. Gemini counts thinking tokens
+// apart from the candidates, so they are added to the output tokens.
+func toUsage(response *GenerateContentResponse) gopherai.Usage {
+	metadata := response.UsageMetadata
+	return gopherai.Usage{
+		Model:             response.ModelVersion,
+		InputTokens:       metadata.PromptTokenCount,
+		CachedInputTokens: metadata.CachedContentTokenCount,
+		OutputTokens:      metadata.CandidatesTokenCount + metadata.ThoughtsTokenCount,
+		ReasoningTokens:   metadata.ThoughtsTokenCount,
 	}
+}
 
+// BuildRequest builds a GenerateContentRequest from the given parameters.
+func (p *Provider) BuildRequest(messages []gopherai.Message, systemPrompt string, tools []any) any {
 	functionDeclarations := make([]FunctionDeclaration, len(tools))
 	for i, tool := range tools {
 		functionDeclarations[i] = tool.(FunctionDeclaration)
@@ -190,7 +126,7 @@ func (p *Provider) BuildRequest(input any, systemPrompt string, tools []any) any
 	}
 
 	req := &GenerateContentRequest{
-		Contents: contents,
+		Contents: ConvertMessages(messages),
 		Tools:    toolsList,
 		GenerationConfig: &GenerationConfig{
 			Temperature:     p.temperature,
@@ -209,83 +145,163 @@ func (p *Provider) BuildRequest(input any, systemPrompt string, tools []any) any
 	return req
 }
 
-// CreateFunctionCallInput creates a function call input content from a ToolCall.
-func (p *Provider) CreateFunctionCallInput(call gopherai.ToolCall) any {
-	var args map[string]any
-	if err := json.Unmarshal([]byte(call.Arguments), &args); err != nil {
-		args = make(map[string]any)
+// SetOutputSchema constrains the response to JSON matching the schema, which
+// is converted as for tool parameters.
+func (p *Provider) SetOutputSchema(req any, schema gopherai.OutputSchema) error {
+	genReq, ok := req.(*GenerateContentRequest)
+	if !ok {
+		return fmt.Errorf("invalid request type: expected *GenerateContentRequest")
 	}
 
-	return Content{
-		Role: "model",
-		Parts: []Part{
-			{
-				FunctionCall: &FunctionCall{
-					Name: call.Name,
-					Args: args,
-				},
-			},
-		},
+	if genReq.GenerationConfig == nil {
+		genReq.GenerationConfig = &GenerationConfig{}
 	}
+	genReq.GenerationConfig.ResponseMIMEType = "application/json"
+	genReq.GenerationConfig.ResponseSchema = ConvertSchema(schema.Schema)
+	return nil
 }
 
-// CreateFunctionCallOutput creates a function call output content.
-func (p *Provider) CreateFunctionCallOutput(callID, output string) any {
-	var response map[string]any
-	if err := json.Unmarshal([]byte(output), &response); err != nil {
-		response = map[string]any{"result": output}
-	}
+// ConvertMessages converts gopherai messages into Gemini contents.
+// Function responses are named after the function that was called, which is
+// looked up by call ID when the tool result does not carry a name.
+func ConvertMessages(messages []gopherai.Message) []Content {
+	callNames := make(map[string]string)
+	contents := make([]Content, 0, len(messages))
+
+	for _, msg := range messages {
+		role := "user"
+		if msg.Role == gopherai.RoleAssistant {
+			role = "model"
+		}
 
-	return Content{
-		Role: "user",
-		Parts: []Part{
-			{
-				FunctionResponse: &FunctionResponse{
-					Name:     callID,
-					Response: response,
-				},
-			},
-		},
-	}
-}
+		parts := make([]Part, 0, len(msg.Parts))
+		for _, part := range msg.Parts {
+			switch part.Type {
+			case gopherai.PartTypeText:
+				if part.Text == "" {
+					continue
+				}
+				parts = append(parts, Part{Text: part.Text})
 
-// CreateAssistantMessage creates an assistant message content.
-func (p *Provider) CreateAssistantMessage(text string) any {
-	return Content{
-		Role: "model",
-		Parts: []Part{
-			{Text: text},
-		},
+			case gopherai.PartTypeToolCall:
+				if part.ToolCall == nil {
+					continue
+				}
+				callNames[part.ToolCall.CallID] = part.ToolCall.Name
+				var args map[string]any
+				if err := json.Unmarshal([]byte(part.ToolCall.Arguments), &args); err != nil {
+					args = make(map[string]any)
+				}
+				parts = append(parts, Part{
+					FunctionCall: &FunctionCall{
+						Name: part.ToolCall.Name,
+						Args: args,
+					},
+				})
+
+			case gopherai.PartTypeToolResult:
+				if part.ToolResult == nil {
+					continue
+				}
+				name := part.ToolResult.Name
+				if name == "" {
+					name = callNames[part.ToolResult.CallID]
+				}
+				var response map[string]any
+				if part.ToolResult.IsError {
+					response = map[string]any{"error": part.ToolResult.Output}
+				} else if err := json.Unmarshal([]byte(part.ToolResult.Output), &response); err != nil {
+					response = map[string]any{"result": part.ToolResult.Output}
+				}
+				parts = append(parts, Part{
+					FunctionResponse: &FunctionResponse{
+						Name:     name,
+						Response: response,
+					},
+				})
+			}
+		}
+
+		if len(parts) > 0 {
+			contents = append(contents, Content{Role: role, Parts: parts})
+		}
 	}
+
+	return contents
 }
 
-// fixFunctionResponseNames fixes function response names by matching them
-// with the preceding function calls in the conversation.
-func (p *Provider) fixFunctionResponseNames(contents []Content) []Content {
-	result := make([]Content, len(contents))
-	copy(result, contents)
-
-	for i := 0; i < len(result); i++ {
-		if result[i].Role == "function" {
-			for j := i - 1; j >= 0; j-- {
-				if result[j].Role == "model" {
-					for _, part := range result[j].Parts {
-						if part.FunctionCall != nil {
-							for k := range result[i].Parts {
-								if result[i].Parts[k].FunctionResponse != nil {
-									result[i].Parts[k].FunctionResponse.Name = part.FunctionCall.Name
-								}
-							}
-							break
-						}
-					}
-					break
+// ToMessages converts Gemini contents into gopherai messages, so a conversation
+// stored in Gemini's wire format can be continued on any provider. Gemini does
+// not identify function calls, so call IDs are generated and each function
+// response is matched to the earliest unanswered call with the same name.
+func ToMessages(contents []Content) []gopherai.Message {
+	var messages []gopherai.Message
+	unanswered := make(map[string][]string)
+	next := 0
+
+	for _, content := range contents {
+		role := gopherai.RoleUser
+		if content.Role == "model" {
+			role = gopherai.RoleAssistant
+		}
+
+		var parts, results []gopherai.Part
+		for _, part := range content.Parts {
+			switch {
+			case part.FunctionCall != nil:
+				next++
+				callID := fmt.Sprintf("call_%d", next)
+				unanswered[part.FunctionCall.Name] = append(unanswered[part.FunctionCall.Name], callID)
+				argsJSON, err := json.Marshal(part.FunctionCall.Args)
+				if err != nil {
+					argsJSON = []byte("{}")
+				}
+				parts = append(parts, gopherai.ToolCallPart(gopherai.ToolCall{
+					Name:      part.FunctionCall.Name,
+					Arguments: string(argsJSON),
+					CallID:    callID,
+				}))
+
+			case part.FunctionResponse != nil:
+				name := part.FunctionResponse.Name
+				var callID string
+				if ids := unanswered[name]; len(ids) > 0 {
+					callID = ids[0]
+					unanswered[name] = ids[1:]
 				}
+				results = append(results, gopherai.ToolResultPart(gopherai.ToolResult{
+					CallID: callID,
+					Name:   name,
+					Output: functionResponseOutput(part.FunctionResponse.Response),
+				}))
+
+			case part.Text != "":
+				parts = append(parts, gopherai.TextPart(part.Text))
 			}
 		}
+
+		if len(parts) > 0 {
+			messages = append(messages, gopherai.Message{Role: role, Parts: parts})
+		}
+		if len(results) > 0 {
+			messages = append(messages, gopherai.Message{Role: gopherai.RoleTool, Parts: results})
+		}
 	}
 
-	return result
+	return messages
+}
+
+// functionResponseOutput reverses the wrapping applied by ConvertMessages to
+// tool outputs that are not JSON objects.
+func functionResponseOutput(response map[string]any) string {
+	if result, ok := response["result"].(string); ok && len(response) == 1 {
+		return result
+	}
+	output, err := json.Marshal(response)
+	if err != nil {
+		return "{}"
+	}
+	return string(output)
 }
 
 // CreateResponseStream sends a streaming request to the Gemini API.
@@ -295,28 +311,14 @@ func (p *Provider) CreateResponseStream(ctx context.Context, req any) (<-chan go
 		return nil, fmt.Errorf("invalid request type: expected *GenerateContentRequest")
 	}
 
-	events := make(chan gopherai.StreamEvent, 100)
-
 	endpoint := fmt.Sprintf("/models/%s:streamGenerateContent?alt=sse", p.model)
-	resp, err := p.http.R().
-		SetContext(ctx).
-		SetBody(generateReq).
-		SetDoNotParseResponse(true).
-		Post(endpoint)
+	body, err := p.client.Stream(ctx, http.MethodPost, endpoint, generateReq)
 	if err != nil {
-		close(events)
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-
-	if resp.IsError() {
-		body := resp.RawBody()
-		defer func() { _ = body.Close() }()
-		bodyBytes, _ := io.ReadAll(body)
-		close(events)
-		return nil, fmt.Errorf("API error: %s", string(bodyBytes))
+		return nil, err
 	}
 
-	go p.parseGeminiStream(resp.RawBody(), events)
+	events := make(chan gopherai.StreamEvent, 100)
+	go p.parseGeminiStream(generateReq, body, events)
 
 	return events, nil
 }
@@ -326,19 +328,24 @@ func ParseGeminiStreamForTest(r io.Reader) <-chan gopherai.StreamEvent {
 	events := make(chan gopherai.StreamEvent, 100)
 	go func() {
 		defer close(events)
-		parseGeminiStreamReader(r, events)
+		parseGeminiStreamReader(nil, r, events)
 	}()
 	return events
 }
 
-func (p *Provider) parseGeminiStream(body io.ReadCloser, events chan<- gopherai.StreamEvent) {
+func (p *Provider) parseGeminiStream(req *GenerateContentRequest, body io.ReadCloser, events chan<- gopherai.StreamEvent) {
+	defer close(events)
 	defer func() { _ = body.Close() }()
-	parseGeminiStreamReader(body, events)
+	parseGeminiStreamReader(req, body, events)
 }
 
-func parseGeminiStreamReader(r io.Reader, events chan<- gopherai.StreamEvent) {
+// parseGeminiStreamReader parses the events of a stream answering req. Text
+// deltas are forwarded as generated, while the full text and function call
+// arguments are decoded like those of a CreateResponse result.
+func parseGeminiStreamReader(req *GenerateContentRequest, r io.Reader, events chan<- gopherai.StreamEvent) {
 	reader := bufio.NewReader(r)
 	var fullText strings.Builder
+	var usage *gopherai.Usage
 
 	for {
 		line, err := reader.ReadString('\n')
@@ -363,6 +370,17 @@ func parseGeminiStreamReader(r io.Reader, events chan<- gopherai.StreamEvent) {
 		if err := json.Unmarshal([]byte(data), &response); err != nil {
 			continue
 		}
+		if response.UsageMetadata != nil {
+			chunkUsage := toUsage(&response)
+			usage = &chunkUsage
+		}
+		if blocked := newBlockedError(&response); blocked != nil {
+			events <- gopherai.StreamEvent{
+				Type:  gopherai.StreamEventTypeError,
+				Error: blocked,
+			}
+			return
+		}
 
 		for _, candidate := range response.Candidates {
 			for _, part := range candidate.Content.Parts {
@@ -375,6 +393,7 @@ func parseGeminiStreamReader(r io.Reader, events chan<- gopherai.StreamEvent) {
 				}
 
 				if part.FunctionCall != nil {
+					req.decodeArguments(part.FunctionCall)
 					argsJSON, err := json.Marshal(part.FunctionCall.Args)
 					if err != nil {
 						continue
@@ -384,7 +403,7 @@ func parseGeminiStreamReader(r io.Reader, events chan<- gopherai.StreamEvent) {
 						ToolCall: &gopherai.ToolCall{
 							Name:      part.FunctionCall.Name,
 							Arguments: string(argsJSON),
-							CallID:    part.FunctionCall.Name,
+							CallID:    gopherai.NewToolCallID(),
 						},
 					}
 				}
@@ -394,7 +413,13 @@ func parseGeminiStreamReader(r io.Reader, events chan<- gopherai.StreamEvent) {
 				if fullText.Len() > 0 {
 					events <- gopherai.StreamEvent{
 						Type: gopherai.StreamEventTypeTextDone,
-						Text: fullText.String(),
+						Text: req.decodeText(fullText.String()),
+					}
+				}
+				if usage != nil {
+					events <- gopherai.StreamEvent{
+						Type:  gopherai.StreamEventTypeUsage,
+						Usage: usage,
 					}
 				}
 				events <- gopherai.StreamEvent{
@@ -405,3 +430,55 @@ func parseGeminiStreamReader(r io.Reader, events chan<- gopherai.StreamEvent) {
 		}
 	}
 }
+
+// decodeResponse decodes the function call arguments and the JSON text of a
+// response into the shape of the schemas of req, which ConvertSchema may have
+// changed to fit Gemini. The text decoded is the one ExtractText returns.
+func (r *GenerateContentRequest) decodeResponse(resp *GenerateContentResponse) {
+	for _, candidate := range resp.Candidates {
+		textDecoded := false
+		for i, part := range candidate.Content.Parts {
+			if part.FunctionCall != nil {
+				r.decodeArguments(part.FunctionCall)
+			}
+			if part.Text != "" && !textDecoded {
+				candidate.Content.Parts[i].Text = r.decodeText(part.Text)
+				textDecoded = true
+			}
+		}
+	}
+}
+
+// decodeArguments decodes the arguments of a call to a declared function.
+func (r *GenerateContentRequest) decodeArguments(call *FunctionCall) {
+	if r == nil {
+		return
+	}
+	for _, tool := range r.Tools {
+		for _, declaration := range tool.FunctionDeclarations {
+			if declaration.Name != call.Name {
+				continue
+			}
+			if args, ok := declaration.Parameters.decode(call.Args).(map[string]any); ok {
+				call.Args = args
+			}
+			return
+		}
+	}
+}
+
+// decodeText decodes JSON text generated for the response schema.
+func (r *GenerateContentRequest) decodeText(text string) string {
+	if r == nil || r.GenerationConfig == nil || !r.GenerationConfig.ResponseSchema.encoded() {
+		return text
+	}
+	var value any
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		return text
+	}
+	decoded, err := json.Marshal(r.GenerationConfig.ResponseSchema.decode(value))
+	if err != nil {
+		return text
+	}
+	return string(decoded)
+}
@@ -0,0 +1,498 @@
+package gemini
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// ConvertSchema converts a JSON schema, such as the one generated by
+// gopherai.NewTool, into the schema subset Gemini accepts. Keywords Gemini
+// does not support are translated where possible:
+//   - local $ref pointers are inlined; recursive definitions are unrolled
+//     maxRefDepth times, and the references below are left out
+//   - oneOf becomes anyOf, const becomes a single-value enum, and a
+//     ["type", "null"] type list becomes a nullable type
+//   - map schemas (additionalProperties with a schema) become arrays of
+//     {key, value} entries
+//   - schemas allowing any value, and objects without properties that allow
+//     other properties, become strings holding the value encoded as JSON
+//   - objects without properties that allow no other properties are left
+//     out, since Gemini rejects objects without properties
+//   - non-string enums and string formats other than date-time are
+//     described in the description
+//
+// The provider decodes map entries and JSON strings in function call
+// arguments and structured output back into the shape of the original
+// schema, and fills required properties that were left out with an empty
+// value. ConvertSchema returns nil when the whole schema is left out.
+func ConvertSchema(schema map[string]any) *Schema {
+	if schema == nil {
+		return nil
+	}
+	c := &schemaConverter{root: schema, resolving: map[string]int{"#": 1}}
+	return c.convert(schema)
+}
+
+// maxRefDepth is how many times a recursive definition is inlined within
+// itself.
+const maxRefDepth = 3
+
+// schemaEncoding is how a value is encoded to fit a converted schema.
+type schemaEncoding int
+
+const (
+	encodingNone schemaEncoding = iota
+	// encodingJSON is a string holding the value encoded as JSON.
+	encodingJSON
+	// encodingMapEntries is an array of {key, value} entries of a map.
+	encodingMapEntries
+)
+
+type schemaConverter struct {
+	root      map[string]any
+	resolving map[string]int
+}
+
+// convert converts a schema, returning nil when it is left out.
+func (c *schemaConverter) convert(schema map[string]any) *Schema {
+	if ref, ok := schema["$ref"].(string); ok {
+		return c.convertRef(ref, schema)
+	}
+
+	result := &Schema{}
+	var notes []string
+
+	switch typ := schema["type"].(type) {
+	case string:
+		result.Type = typ
+	case []any:
+		for _, t := range typ {
+			if t == "null" {
+				result.Nullable = true
+			} else if s, ok := t.(string); ok && result.Type == "" {
+				result.Type = s
+			}
+		}
+	}
+	if nullable, ok := schema["nullable"].(bool); ok && nullable {
+		result.Nullable = true
+	}
+
+	result.Title, _ = schema["title"].(string)
+	result.Description, _ = schema["description"].(string)
+	result.Pattern, _ = schema["pattern"].(string)
+	result.Default = schema["default"]
+	result.Example = schema["example"]
+	if examples, ok := schema["examples"].([]any); ok && len(examples) > 0 && result.Example == nil {
+		result.Example = examples[0]
+	}
+
+	result.Minimum = toFloat(schema["minimum"])
+	result.Maximum = toFloat(schema["maximum"])
+	result.MinLength = toInt(schema["minLength"])
+	result.MaxLength = toInt(schema["maxLength"])
+	result.MinItems = toInt(schema["minItems"])
+	result.MaxItems = toInt(schema["maxItems"])
+
+	if format, ok := schema["format"].(string); ok {
+		if supportedFormat(result.Type, format) {
+			result.Format = format
+		} else {
+			notes = append(notes, fmt.Sprintf("Format: %s.", format))
+		}
+	}
+
+	enum, hasEnum := schema["enum"]
+	if value, ok := schema["const"]; ok {
+		enum, hasEnum = []any{value}, true
+	}
+	if hasEnum {
+		if values, ok := stringValues(enum); ok && (result.Type == "" || result.Type == "string") {
+			result.Type = "string"
+			result.Format = "enum"
+			result.Enum = values
+		} else {
+			notes = append(notes, fmt.Sprintf("One of: %s.", formatValues(enum)))
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]any)
+	if len(properties) > 0 {
+		result.Properties = make(map[string]*Schema, len(properties))
+		for name, prop := range properties {
+			if propSchema, ok := prop.(map[string]any); ok {
+				if converted := c.convert(propSchema); converted != nil {
+					result.Properties[name] = converted
+				}
+			}
+		}
+		for _, name := range stringList(schema["required"]) {
+			if _, ok := result.Properties[name]; ok {
+				result.Required = append(result.Required, name)
+			} else if prop, ok := properties[name].(map[string]any); ok {
+				if result.omitted == nil {
+					result.omitted = make(map[string]string)
+				}
+				result.omitted[name] = c.emptyKind(prop)
+			}
+		}
+	}
+
+	var mapValue map[string]any
+	closed := false
+	switch additional := schema["additionalProperties"].(type) {
+	case map[string]any:
+		if len(result.Properties) == 0 {
+			mapValue = additional
+		} else {
+			notes = append(notes, "Other properties: "+describe(c.convert(additional))+" values.")
+		}
+	case bool:
+		closed = !additional
+	}
+
+	if items, ok := schema["items"].(map[string]any); ok {
+		if result.Items = c.convert(items); result.Items == nil {
+			return nil
+		}
+	}
+
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		variants, _ := schema[keyword].([]any)
+		for _, variant := range variants {
+			if variantSchema, ok := variant.(map[string]any); ok {
+				if converted := c.convert(variantSchema); converted != nil {
+					result.AnyOf = append(result.AnyOf, converted)
+				}
+			}
+		}
+	}
+
+	if len(notes) > 0 {
+		result.Description = strings.TrimSpace(result.Description + " " + strings.Join(notes, " "))
+	}
+
+	switch {
+	case mapValue != nil:
+		value := c.convert(mapValue)
+		if value == nil {
+			return nil
+		}
+		return mapEntries(result, value)
+	case len(result.AnyOf) > 0:
+		return result
+	case result.Type == "":
+		return jsonValue(result)
+	case result.Type == "object" && len(result.Properties) == 0:
+		if closed || len(properties) > 0 {
+			return nil
+		}
+		return jsonValue(result)
+	default:
+		return result
+	}
+}
+
+// convertRef inlines the definition a local $ref points to, unless it is
+// already being inlined maxRefDepth times. A description next to the $ref
+// overrides the definition's.
+func (c *schemaConverter) convertRef(ref string, schema map[string]any) *Schema {
+	var def map[string]any
+	if ref == "#" {
+		def = c.root
+	} else if defs, ok := c.root["$defs"].(map[string]any); ok {
+		def, _ = defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any)
+	}
+
+	if description, ok := schema["description"].(string); ok && def != nil {
+		def = maps.Clone(def)
+		def["description"] = description
+	}
+
+	switch {
+	case def == nil:
+		return jsonValue(&Schema{Description: fmt.Sprintf("Unresolved reference %s.", ref)})
+	case c.resolving[ref] >= maxRefDepth:
+		return nil
+	default:
+		c.resolving[ref]++
+		defer func() { c.resolving[ref]-- }()
+		return c.convert(def)
+	}
+}
+
+// emptyKind returns the type of the empty value that stands in for a
+// required property that is left out: "array", "object", or "" for null.
+func (c *schemaConverter) emptyKind(schema map[string]any) string {
+	if ref, ok := schema["$ref"].(string); ok {
+		if ref == "#" {
+			return "object"
+		}
+		defs, _ := c.root["$defs"].(map[string]any)
+		schema, _ = defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any)
+	}
+	switch typ := schema["type"].(type) {
+	case string:
+		if typ == "array" || typ == "object" {
+			return typ
+		}
+	case []any:
+		for _, t := range typ {
+			if t == "array" || t == "object" {
+				return t.(string)
+			}
+		}
+	}
+	return ""
+}
+
+// mapEntries returns the schema of an array of the entries of a map with
+// values matching value.
+func mapEntries(schema, value *Schema) *Schema {
+	return &Schema{
+		Type:        "array",
+		Title:       schema.Title,
+		Description: strings.TrimSpace(schema.Description + " Map entries, each with a string key and a value."),
+		Nullable:    schema.Nullable,
+		Items: &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"key":   {Type: "string"},
+				"value": value,
+			},
+			Required: []string{"key", "value"},
+		},
+		encoding: encodingMapEntries,
+	}
+}
+
+// jsonValue returns the schema of a string holding a value of schema encoded
+// as JSON.
+func jsonValue(schema *Schema) *Schema {
+	return &Schema{
+		Type:        "string",
+		Title:       schema.Title,
+		Description: strings.TrimSpace(schema.Description + " A JSON value, encoded as a string."),
+		Nullable:    schema.Nullable,
+		encoding:    encodingJSON,
+	}
+}
+
+// decode converts a value generated for the schema back into the shape of
+// the schema it was converted from, decoding map entries and JSON strings.
+// Objects and arrays are decoded in place.
+func (s *Schema) decode(value any) any {
+	if s == nil {
+		return value
+	}
+
+	switch s.encoding {
+	case encodingJSON:
+		text, ok := value.(string)
+		if !ok {
+			return value
+		}
+		var decoded any
+		if err := json.Unmarshal([]byte(text), &decoded); err != nil {
+			return value
+		}
+		return decoded
+	case encodingMapEntries:
+		entries, ok := value.([]any)
+		if !ok {
+			return value
+		}
+		result := make(map[string]any, len(entries))
+		for _, entry := range entries {
+			fields, _ := entry.(map[string]any)
+			if key, ok := fields["key"].(string); ok {
+				result[key] = s.Items.Properties["value"].decode(fields["value"])
+			}
+		}
+		return result
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		if object := s.variant("object", v); object != nil {
+			for name, field := range v {
+				v[name] = object.Properties[name].decode(field)
+			}
+			for name, kind := range object.omitted {
+				if _, ok := v[name]; !ok {
+					v[name] = emptyValue(kind)
+				}
+			}
+		}
+	case []any:
+		if array := s.variant("array", nil); array != nil {
+			for i, item := range v {
+				v[i] = array.Items.decode(item)
+			}
+		}
+	}
+	return value
+}
+
+// variant returns the schema if it has the given type, or else the first of
+// its anyOf variants that has the type and declares every field of object.
+func (s *Schema) variant(typ string, object map[string]any) *Schema {
+	if s.Type == typ {
+		return s
+	}
+	for _, variant := range s.AnyOf {
+		if variant.Type == typ && variant.encoding == encodingNone && declares(variant, object) {
+			return variant
+		}
+	}
+	return nil
+}
+
+func declares(schema *Schema, object map[string]any) bool {
+	for name := range object {
+		if _, ok := schema.Properties[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func emptyValue(kind string) any {
+	switch kind {
+	case "array":
+		return []any{}
+	case "object":
+		return map[string]any{}
+	default:
+		return nil
+	}
+}
+
+// encoded reports whether values of the schema are encoded anywhere.
+func (s *Schema) encoded() bool {
+	if s == nil {
+		return false
+	}
+	if s.encoding != encodingNone || len(s.omitted) > 0 || s.Items.encoded() {
+		return true
+	}
+	for _, property := range s.Properties {
+		if property.encoded() {
+			return true
+		}
+	}
+	for _, variant := range s.AnyOf {
+		if variant.encoded() {
+			return true
+		}
+	}
+	return false
+}
+
+// supportedFormat reports whether Gemini accepts format for the given type.
+func supportedFormat(typ, format string) bool {
+	switch typ {
+	case "string":
+		return format == "date-time" || format == "enum"
+	case "number":
+		return format == "float" || format == "double"
+	case "integer":
+		return format == "int32" || format == "int64"
+	default:
+		return false
+	}
+}
+
+// describe returns a short description of a schema's type for notes.
+func describe(schema *Schema) string {
+	switch {
+	case schema == nil || schema.encoding == encodingJSON:
+		return "any"
+	case schema.encoding == encodingMapEntries:
+		return "map"
+	case schema.Type == "array" && schema.Items != nil:
+		return describe(schema.Items) + " array"
+	case schema.Type != "":
+		return schema.Type
+	default:
+		return "any"
+	}
+}
+
+func stringValues(enum any) ([]string, bool) {
+	switch values := enum.(type) {
+	case []string:
+		return values, true
+	case []any:
+		result := make([]string, len(values))
+		for i, v := range values {
+			s, ok := v.(string)
+			if !ok {
+				return nil, false
+			}
+			result[i] = s
+		}
+		return result, true
+	default:
+		return nil, false
+	}
+}
+
+func formatValues(enum any) string {
+	var values []string
+	switch list := enum.(type) {
+	case []any:
+		for _, v := range list {
+			values = append(values, fmt.Sprint(v))
+		}
+	case []string:
+		values = list
+	default:
+		values = []string{fmt.Sprint(enum)}
+	}
+	return strings.Join(values, ", ")
+}
+
+func stringList(value any) []string {
+	if values, ok := stringValues(value); ok {
+		return values
+	}
+	if list, ok := value.([]any); ok {
+		var result []string
+		for _, v := range list {
+			if s, ok := v.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+func toFloat(value any) *float64 {
+	var f float64
+	switch n := value.(type) {
+	case float64:
+		f = n
+	case int:
+		f = float64(n)
+	default:
+		return nil
+	}
+	return &f
+}
+
+func toInt(value any) *int {
+	var i int
+	switch n := value.(type) {
+	case int:
+		i = n
+	case float64:
+		i = int(n)
+	default:
+		return nil
+	}
+	return &i
+}
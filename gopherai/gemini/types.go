@@ -28,24 +28,39 @@ type Tool struct {
 
 // FunctionDeclaration represents a function that can be called by the model.
 type FunctionDeclaration struct {
-	Name        string              `json:"name"`
-	Description string              `json:"description"`
-	Parameters  *FunctionParameters `json:"parameters"`
-}
-
-// FunctionParameters represents the parameters schema for a function.
-type FunctionParameters struct {
-	Type       string                    `json:"type"`
-	Properties map[string]PropertySchema `json:"properties"`
-	Required   []string                  `json:"required,omitempty"`
-}
-
-// PropertySchema represents the schema for a property.
-type PropertySchema struct {
-	Type        string          `json:"type"`
-	Description string          `json:"description,omitempty"`
-	Items       *PropertySchema `json:"items,omitempty"`
-	Enum        []string        `json:"enum,omitempty"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Parameters  *Schema `json:"parameters,omitempty"`
+}
+
+// Schema is the OpenAPI-subset schema Gemini accepts for function parameters
+// and response schemas.
+type Schema struct {
+	Type        string             `json:"type,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Nullable    bool               `json:"nullable,omitempty"`
+	Enum        []string           `json:"enum,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	MinItems    *int               `json:"minItems,omitempty"`
+	MaxItems    *int               `json:"maxItems,omitempty"`
+	MinLength   *int               `json:"minLength,omitempty"`
+	MaxLength   *int               `json:"maxLength,omitempty"`
+	Pattern     string             `json:"pattern,omitempty"`
+	Minimum     *float64           `json:"minimum,omitempty"`
+	Maximum     *float64           `json:"maximum,omitempty"`
+	Default     any                `json:"default,omitempty"`
+	Example     any                `json:"example,omitempty"`
+	AnyOf       []*Schema          `json:"anyOf,omitempty"`
+
+	// encoding is how values are encoded to fit the schema, and omitted
+	// holds the kind of empty value of each required property left out, for
+	// decoding values back.
+	encoding schemaEncoding
+	omitted  map[string]string
 }
 
 // FunctionCall represents a function call from the model.
@@ -66,6 +81,10 @@ type GenerationConfig struct {
 	MaxOutputTokens *int     `json:"maxOutputTokens,omitempty"`
 	TopP            *float64 `json:"topP,omitempty"`
 	TopK            *int     `json:"topK,omitempty"`
+	// ResponseMIMEType and ResponseSchema constrain the response to JSON
+	// matching a schema.
+	ResponseMIMEType string  `json:"responseMimeType,omitempty"`
+	ResponseSchema   *Schema `json:"responseSchema,omitempty"`
 }
 
 // SystemInstruction represents system-level instructions.
@@ -75,8 +94,16 @@ type SystemInstruction struct {
 
 // GenerateContentResponse represents the response from generateContent.
 type GenerateContentResponse struct {
-	Candidates    []Candidate    `json:"candidates"`
-	UsageMetadata *UsageMetadata `json:"usageMetadata,omitempty"`
+	Candidates     []Candidate     `json:"candidates"`
+	PromptFeedback *PromptFeedback `json:"promptFeedback,omitempty"`
+	UsageMetadata  *UsageMetadata  `json:"usageMetadata,omitempty"`
+	ModelVersion   string          `json:"modelVersion,omitempty"`
+}
+
+// PromptFeedback reports why the prompt was blocked, when it was.
+type PromptFeedback struct {
+	BlockReason   string         `json:"blockReason,omitempty"`
+	SafetyRatings []SafetyRating `json:"safetyRatings,omitempty"`
 }
 
 // Candidate represents a candidate response from the model.
@@ -95,9 +122,11 @@ type SafetyRating struct {
 
 // UsageMetadata represents token usage information.
 type UsageMetadata struct {
-	PromptTokenCount     int `json:"promptTokenCount"`
-	CandidatesTokenCount int `json:"candidatesTokenCount"`
-	TotalTokenCount      int `json:"totalTokenCount"`
+	PromptTokenCount        int `json:"promptTokenCount"`
+	CachedContentTokenCount int `json:"cachedContentTokenCount,omitempty"`
+	CandidatesTokenCount    int `json:"candidatesTokenCount"`
+	ThoughtsTokenCount      int `json:"thoughtsTokenCount,omitempty"`
+	TotalTokenCount         int `json:"totalTokenCount"`
 }
 
 // APIError represents an error response from the API.
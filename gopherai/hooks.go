@@ -0,0 +1,155 @@
+package gopherai
+
+import "context"
+
+// Hooks is called by the agent at fixed points of every run, in Run and
+// RunStream alike, for logging, metrics, redaction or policy enforcement.
+// The context passed to each method carries the run's RunInfo.
+//
+// Tool calls of a turn run concurrently, so BeforeToolCall and AfterToolCall
+// may be called from several goroutines at once. Embed BaseHooks to implement
+// only some of the methods.
+type Hooks interface {
+	// OnRunStart is called before the first model call with the conversation
+	// the run starts from. An error aborts the run.
+	OnRunStart(ctx context.Context, messages []Message) error
+	// OnRunEnd is called when a run completes.
+	OnRunEnd(ctx context.Context, result *RunResult)
+	// OnRunError is called when a run fails, including when it stops at a
+	// limit.
+	OnRunError(ctx context.Context, err error)
+
+	// BeforeModelCall is called before each model call, and may modify or
+	// replace the provider request. An error aborts the run.
+	BeforeModelCall(ctx context.Context, call *ModelCall) error
+	// AfterModelCall is called after each model call, and may modify the
+	// response before the agent acts on it. In streamed runs the text has
+	// already been streamed by then. An error aborts the run.
+	AfterModelCall(ctx context.Context, call *ModelCall, response *ModelResponse) error
+
+	// BeforeToolCall is called before each tool call, and may modify its
+	// arguments, which also replace the arguments recorded in the history. An
+	// error fails the call, which is then handled by the tool's error policy.
+	BeforeToolCall(ctx context.Context, call *ToolCall) error
+	// AfterToolCall is called with the result of each tool call, including
+	// failed calls reported to the model, and may modify it. An error aborts
+	// the run.
+	AfterToolCall(ctx context.Context, call ToolCall, result *ToolResult) error
+}
+
+// ModelCall is a model call about to be made.
+type ModelCall struct {
+	// Turn counts the model calls of the run, starting at 1.
+	Turn int
+	// Messages is the conversation the request was built from. It must not
+	// be modified.
+	Messages []Message
+	// Request is the provider request, as returned by BuildRequest.
+	Request any
+}
+
+// ModelResponse is the outcome of a model call.
+type ModelResponse struct {
+	// Message is the assistant message holding the response text and tool
+	// calls.
+	Message Message
+	// Usage is the usage reported by the provider, if any.
+	Usage *Usage
+	// Provider names the provider that answered, when the agent's provider is
+	// a RoutedProvider, or names it on the done event of its stream.
+	Provider string
+	// Raw is the provider response. It is nil in streamed runs.
+	Raw any
+}
+
+// BaseHooks implements Hooks with methods that do nothing.
+type BaseHooks struct{}
+
+// OnRunStart does nothing.
+func (BaseHooks) OnRunStart(context.Context, []Message) error { return nil }
+
+// OnRunEnd does nothing.
+func (BaseHooks) OnRunEnd(context.Context, *RunResult) {}
+
+// OnRunError does nothing.
+func (BaseHooks) OnRunError(context.Context, error) {}
+
+// BeforeModelCall does nothing.
+func (BaseHooks) BeforeModelCall(context.Context, *ModelCall) error { return nil }
+
+// AfterModelCall does nothing.
+func (BaseHooks) AfterModelCall(context.Context, *ModelCall, *ModelResponse) error { return nil }
+
+// BeforeToolCall does nothing.
+func (BaseHooks) BeforeToolCall(context.Context, *ToolCall) error { return nil }
+
+// AfterToolCall does nothing.
+func (BaseHooks) AfterToolCall(context.Context, ToolCall, *ToolResult) error { return nil }
+
+// WithHooks adds hooks to the agent. Hooks are called in the order they were
+// added, and the first error stops the chain.
+func WithHooks(hooks ...Hooks) AgentOption {
+	return func(a *Agent) {
+		a.hooks = append(a.hooks, hooks...)
+	}
+}
+
+// hookChain calls a list of hooks in order.
+type hookChain []Hooks
+
+func (c hookChain) runStart(ctx context.Context, messages []Message) error {
+	for _, h := range c {
+		if err := h.OnRunStart(ctx, messages); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c hookChain) runEnd(ctx context.Context, result *RunResult) {
+	for _, h := range c {
+		h.OnRunEnd(ctx, result)
+	}
+}
+
+func (c hookChain) runError(ctx context.Context, err error) {
+	for _, h := range c {
+		h.OnRunError(ctx, err)
+	}
+}
+
+func (c hookChain) beforeModelCall(ctx context.Context, call *ModelCall) error {
+	for _, h := range c {
+		if err := h.BeforeModelCall(ctx, call); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c hookChain) afterModelCall(ctx context.Context, call *ModelCall, response *ModelResponse) error {
+	for _, h := range c {
+		if err := h.AfterModelCall(ctx, call, response); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c hookChain) beforeToolCall(ctx context.Context, call *ToolCall) error {
+	for _, h := range c {
+		if err := h.BeforeToolCall(ctx, call); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c hookChain) afterToolCall(ctx context.Context, call ToolCall, result *ToolResult) error {
+	for _, h := range c {
+		if err := h.AfterToolCall(ctx, call, result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package gopherai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// ErrorParser converts an error response of a provider's API into an APIError,
+// classifying it from the provider's error payload.
+type ErrorParser func(statusCode int, header http.Header, body []byte) *APIError
+
+// HTTPClient sends the requests of a provider's API. It authenticates every
+// request, retries failed requests under its RetryPolicy and converts error
+// responses with the provider's ErrorParser, so that all providers handle
+// auth, retries and errors the same way. Requests are not retried by default.
+type HTTPClient struct {
+	client   *resty.Client
+	auth     Authenticator
+	retry    RetryPolicy
+	newError ErrorParser
+}
+
+// NewHTTPClient creates a client for the JSON API at baseURL, authenticating
+// requests with auth, which may be nil.
+func NewHTTPClient(baseURL string, auth Authenticator, newError ErrorParser) *HTTPClient {
+	c := &HTTPClient{
+		client:   resty.New(),
+		auth:     auth,
+		newError: newError,
+	}
+	c.client.SetBaseURL(baseURL)
+	c.client.SetHeader("Content-Type", "application/json")
+	c.client.SetPreRequestHook(c.authenticate)
+	return c
+}
+
+// SetBaseURL sets the URL request paths are relative to.
+func (c *HTTPClient) SetBaseURL(url string) {
+	c.client.SetBaseURL(url)
+}
+
+// SetHeader sets a header sent with every request.
+func (c *HTTPClient) SetHeader(name, value string) {
+	c.client.SetHeader(name, value)
+}
+
+// SetQueryParam sets a query parameter sent with every request.
+func (c *HTTPClient) SetQueryParam(name, value string) {
+	c.client.SetQueryParam(name, value)
+}
+
+// SetAuthenticator replaces the authenticator. A nil authenticator sends
+// requests without credentials.
+func (c *HTTPClient) SetAuthenticator(auth Authenticator) {
+	c.auth = auth
+}
+
+// SetRetryPolicy sets how failed requests are retried.
+func (c *HTTPClient) SetRetryPolicy(policy RetryPolicy) {
+	c.retry = policy
+}
+
+func (c *HTTPClient) authenticate(_ *resty.Client, r *http.Request) error {
+	if c.auth == nil {
+		return nil
+	}
+	return c.auth.Authenticate(r)
+}
+
+// Do sends a request with body, if not nil, encoded as JSON, and decodes the
+// JSON response into result, if not nil. An error response is returned as an
+// *APIError.
+func (c *HTTPClient) Do(ctx context.Context, method, path string, body, result any) error {
+	_, err := c.send(ctx, method, path, func() *resty.Request {
+		r := c.client.R().SetContext(ctx)
+		if body != nil {
+			r.SetBody(body)
+		}
+		if result != nil {
+			r.SetResult(result)
+		}
+		return r
+	})
+	return err
+}
+
+// Stream sends a request like Do, and returns the body of the response for
+// the caller to read as it arrives and to close.
+func (c *HTTPClient) Stream(ctx context.Context, method, path string, body any) (io.ReadCloser, error) {
+	resp, err := c.send(ctx, method, path, func() *resty.Request {
+		r := c.client.R().SetContext(ctx).SetDoNotParseResponse(true)
+		if body != nil {
+			r.SetBody(body)
+		}
+		return r
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.RawBody(), nil
+}
+
+// send executes the request built by newRequest, building a new one for each
+// attempt allowed by the retry policy. Error responses are converted with the
+// ErrorParser, and are not retried when it classifies them as not retryable,
+// unless the policy has its own Retryable check.
+func (c *HTTPClient) send(ctx context.Context, method, path string, newRequest func() *resty.Request) (*resty.Response, error) {
+	var resp *resty.Response
+	var apiErr *APIError
+
+	policy := c.retry
+	if policy.Retryable == nil {
+		policy.Retryable = func(r *http.Response, err error) bool {
+			return c.retry.retryable(r, err) && (apiErr == nil || apiErr.Retryable)
+		}
+	}
+
+	err := policy.Do(ctx, func() (*http.Response, error) {
+		var err error
+		apiErr = nil
+		resp, err = newRequest().Execute(method, path)
+		if resp == nil {
+			return nil, err
+		}
+		if err == nil && resp.IsError() {
+			apiErr = c.newError(resp.StatusCode(), resp.Header(), errorBody(resp))
+		}
+		return resp.RawResponse, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	return resp, nil
+}
+
+// errorBody returns the body of an error response, reading it from the
+// connection when the response was not parsed.
+func errorBody(resp *resty.Response) []byte {
+	if raw := resp.RawBody(); raw != nil && len(resp.Body()) == 0 {
+		defer func() { _ = raw.Close() }()
+		body, _ := io.ReadAll(raw)
+		return body
+	}
+	return resp.Body()
+}
@@ -0,0 +1,106 @@
+package gopherai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const defaultMaxTurns = 10
+
+// ErrLimitExceeded is matched by errors.Is for every LimitExceededError.
+var ErrLimitExceeded = errors.New("agent limit exceeded")
+
+// errRunTimeout is the cancellation cause of a run that reached its wall-clock limit.
+var errRunTimeout = errors.New("run timeout reached")
+
+// LimitKind identifies which agent loop limit stopped a run.
+type LimitKind string
+
+// Limit kind constants.
+const (
+	LimitMaxTurns     LimitKind = "max_turns"
+	LimitMaxToolCalls LimitKind = "max_tool_calls"
+	LimitTimeout      LimitKind = "timeout"
+)
+
+// LimitExceededError is returned when a run stops because it reached one of
+// the agent's limits. Result holds the partial run: its history ends with the
+// last complete exchange, so it can be passed back to Run to resume, or used
+// to summarize the work done so far.
+type LimitExceededError struct {
+	Limit     LimitKind
+	Turns     int
+	ToolCalls int
+	Result    *RunResult
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("agent limit exceeded: %s (after %d turns and %d tool calls)", e.Limit, e.Turns, e.ToolCalls)
+}
+
+// Unwrap returns ErrLimitExceeded, and ErrBudgetExceeded for budget limits.
+func (e *LimitExceededError) Unwrap() []error {
+	if e.Limit == LimitTokenBudget || e.Limit == LimitCostBudget {
+		return []error{ErrLimitExceeded, ErrBudgetExceeded}
+	}
+	return []error{ErrLimitExceeded}
+}
+
+// WithMaxTurns sets the maximum number of model calls in a single run.
+// The default is 10; a value of zero or less removes the limit.
+func WithMaxTurns(maxTurns int) AgentOption {
+	return func(a *Agent) {
+		a.maxTurns = maxTurns
+	}
+}
+
+// WithMaxToolCalls sets the maximum number of tool calls executed in a single run.
+// A run stops before executing a batch of calls that would exceed the limit.
+// The default is zero, which means no limit.
+func WithMaxToolCalls(maxToolCalls int) AgentOption {
+	return func(a *Agent) {
+		a.maxToolCalls = maxToolCalls
+	}
+}
+
+// WithTimeout sets the wall-clock time a single run may take, including model
+// calls and tool execution. The default is zero, which means no limit.
+func WithTimeout(timeout time.Duration) AgentOption {
+	return func(a *Agent) {
+		a.timeout = timeout
+	}
+}
+
+// startTurn reports whether another model call is allowed.
+func (s *runState) startTurn() bool {
+	if s.agent.maxTurns > 0 && s.turns >= s.agent.maxTurns {
+		return false
+	}
+	s.turns++
+	return true
+}
+
+// addToolCalls reports whether n more tool calls are allowed, counting them if so.
+func (s *runState) addToolCalls(n int) bool {
+	if s.agent.maxToolCalls > 0 && s.toolCalls+n > s.agent.maxToolCalls {
+		return false
+	}
+	s.toolCalls += n
+	return true
+}
+
+func (s *runState) exceeded(limit LimitKind) *LimitExceededError {
+	return &LimitExceededError{
+		Limit:     limit,
+		Turns:     s.turns,
+		ToolCalls: s.toolCalls,
+		Result:    s.result(""),
+	}
+}
+
+// timedOut reports whether ctx was cancelled by the run's own timeout.
+func timedOut(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), errRunTimeout)
+}
@@ -0,0 +1,236 @@
+package gopherai
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Role identifies the author of a message in a conversation.
+type Role string
+
+// Message role constants.
+const (
+	RoleUser      Role = "user"
+	RoleAssistant Role = "assistant"
+	RoleTool      Role = "tool"
+)
+
+// PartType identifies the kind of content held by a message part.
+type PartType string
+
+// Message part type constants.
+const (
+	PartTypeText       PartType = "text"
+	PartTypeToolCall   PartType = "tool_call"
+	PartTypeToolResult PartType = "tool_result"
+)
+
+// Part is a single piece of content within a message.
+// Exactly one of Text, ToolCall or ToolResult is set, according to Type.
+type Part struct {
+	Type       PartType    `json:"type"`
+	Text       string      `json:"text,omitempty"`
+	ToolCall   *ToolCall   `json:"tool_call,omitempty"`
+	ToolResult *ToolResult `json:"tool_result,omitempty"`
+}
+
+// Message is a provider-agnostic conversation message.
+// Providers translate messages to and from their own wire types.
+type Message struct {
+	Role     Role           `json:"role"`
+	Parts    []Part         `json:"parts"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// TextPart creates a text part.
+func TextPart(text string) Part {
+	return Part{Type: PartTypeText, Text: text}
+}
+
+// ToolCallPart creates a tool call part.
+func ToolCallPart(call ToolCall) Part {
+	return Part{Type: PartTypeToolCall, ToolCall: &call}
+}
+
+// ToolResultPart creates a tool result part.
+func ToolResultPart(result ToolResult) Part {
+	return Part{Type: PartTypeToolResult, ToolResult: &result}
+}
+
+// NewUserMessage creates a user message with the given text.
+func NewUserMessage(text string) Message {
+	return Message{
+		Role:  RoleUser,
+		Parts: []Part{TextPart(text)},
+	}
+}
+
+// NewAssistantMessage creates an assistant message with optional text and tool calls.
+// The text part is omitted when text is empty and tool calls are present.
+func NewAssistantMessage(text string, calls ...ToolCall) Message {
+	msg := Message{Role: RoleAssistant}
+	if text != "" || len(calls) == 0 {
+		msg.Parts = append(msg.Parts, TextPart(text))
+	}
+	for _, call := range calls {
+		msg.Parts = append(msg.Parts, ToolCallPart(call))
+	}
+	return msg
+}
+
+// NewToolResultMessage creates a tool message holding the given tool results.
+func NewToolResultMessage(results ...ToolResult) Message {
+	msg := Message{Role: RoleTool}
+	for _, result := range results {
+		msg.Parts = append(msg.Parts, ToolResultPart(result))
+	}
+	return msg
+}
+
+// Text returns the concatenated text parts of the message.
+func (m Message) Text() string {
+	var sb strings.Builder
+	for _, part := range m.Parts {
+		if part.Type == PartTypeText {
+			sb.WriteString(part.Text)
+		}
+	}
+	return sb.String()
+}
+
+// ToolCalls returns the tool calls contained in the message.
+func (m Message) ToolCalls() []ToolCall {
+	var calls []ToolCall
+	for _, part := range m.Parts {
+		if part.Type == PartTypeToolCall && part.ToolCall != nil {
+			calls = append(calls, *part.ToolCall)
+		}
+	}
+	return calls
+}
+
+// ToolResults returns the tool results contained in the message.
+func (m Message) ToolResults() []ToolResult {
+	var results []ToolResult
+	for _, part := range m.Parts {
+		if part.Type == PartTypeToolResult && part.ToolResult != nil {
+			results = append(results, *part.ToolResult)
+		}
+	}
+	return results
+}
+
+// CloneMessages returns a copy of the given messages that can be modified
+// without affecting the original slice.
+func CloneMessages(messages []Message) []Message {
+	if messages == nil {
+		return nil
+	}
+	cloned := make([]Message, len(messages))
+	for i, msg := range messages {
+		cloned[i] = msg
+		cloned[i].Parts = make([]Part, len(msg.Parts))
+		for j, part := range msg.Parts {
+			if part.ToolCall != nil {
+				call := *part.ToolCall
+				part.ToolCall = &call
+			}
+			if part.ToolResult != nil {
+				result := *part.ToolResult
+				part.ToolResult = &result
+			}
+			cloned[i].Parts[j] = part
+		}
+		if msg.Metadata != nil {
+			cloned[i].Metadata = make(map[string]any, len(msg.Metadata))
+			for k, v := range msg.Metadata {
+				cloned[i].Metadata[k] = v
+			}
+		}
+	}
+	return cloned
+}
+
+// NormalizeToolCallIDs returns a copy of the messages in which every tool call
+// has a non-empty call ID that is unique across the conversation. Tool results
+// are re-pointed at the renamed calls in order. Providers that require unique
+// call IDs use it to accept histories produced by providers that do not.
+func NormalizeToolCallIDs(messages []Message) []Message {
+	normalized := CloneMessages(messages)
+	seen := make(map[string]bool)
+	pending := make(map[string][]string)
+	next := 0
+
+	for i := range normalized {
+		for j := range normalized[i].Parts {
+			part := &normalized[i].Parts[j]
+			switch {
+			case part.Type == PartTypeToolCall && part.ToolCall != nil:
+				original := part.ToolCall.CallID
+				id := original
+				for id == "" || seen[id] {
+					next++
+					id = fmt.Sprintf("call_%d", next)
+				}
+				seen[id] = true
+				pending[original] = append(pending[original], id)
+				part.ToolCall.CallID = id
+
+			case part.Type == PartTypeToolResult && part.ToolResult != nil:
+				original := part.ToolResult.CallID
+				if ids := pending[original]; len(ids) > 0 {
+					part.ToolResult.CallID = ids[0]
+					pending[original] = ids[1:]
+				}
+			}
+		}
+	}
+
+	return normalized
+}
+
+// withUniqueToolCallIDs renames the tool calls of response, as
+// NormalizeToolCallIDs does, when their IDs are empty or not unique across the
+// conversation, so that each call of the response can be told apart.
+func withUniqueToolCallIDs(messages []Message, response Message) Message {
+	seen := make(map[string]bool)
+	for _, msg := range messages {
+		for _, call := range msg.ToolCalls() {
+			seen[call.CallID] = true
+		}
+	}
+	unique := true
+	for _, call := range response.ToolCalls() {
+		if call.CallID == "" || seen[call.CallID] {
+			unique = false
+			break
+		}
+		seen[call.CallID] = true
+	}
+	if unique {
+		return response
+	}
+
+	normalized := NormalizeToolCallIDs(append(messages[:len(messages):len(messages)], response))
+	return normalized[len(normalized)-1]
+}
+
+// withToolCallArguments returns a copy of message whose tool calls have the
+// arguments of the calls with the same IDs, as edited before they ran.
+func withToolCallArguments(message Message, calls []ToolCall) Message {
+	arguments := make(map[string]string, len(calls))
+	for _, call := range calls {
+		arguments[call.CallID] = call.Arguments
+	}
+
+	edited := CloneMessages([]Message{message})[0]
+	for _, part := range edited.Parts {
+		if part.ToolCall == nil {
+			continue
+		}
+		if args, ok := arguments[part.ToolCall.CallID]; ok {
+			part.ToolCall.Arguments = args
+		}
+	}
+	return edited
+}
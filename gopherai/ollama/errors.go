@@ -0,0 +1,22 @@
+package ollama
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+)
+
+const providerName = "ollama"
+
+// newAPIError converts an error response into a gopherai.APIError, reading
+// the message from the APIError payload when the body holds one.
+func newAPIError(statusCode int, header http.Header, body []byte) *gopherai.APIError {
+	e := gopherai.NewAPIError(providerName, statusCode, header, body)
+
+	var payload APIError
+	if err := json.Unmarshal(body, &payload); err == nil && payload.Error != "" {
+		e.Message = payload.Error
+	}
+	return e
+}
@@ -0,0 +1,88 @@
+package ollama
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ListModels returns the models available on the server.
+func (p *Provider) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	var result struct {
+		Models []ModelInfo `json:"models"`
+	}
+
+	if err := p.client.Do(ctx, http.MethodGet, "/api/tags", nil, &result); err != nil {
+		return nil, err
+	}
+
+	return result.Models, nil
+}
+
+// HasModel reports whether the named model is available on the server. A name
+// without a tag also matches the model's "latest" tag.
+func (p *Provider) HasModel(ctx context.Context, name string) (bool, error) {
+	models, err := p.ListModels(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, model := range models {
+		for _, candidate := range []string{model.Name, model.Model} {
+			if candidate == name || candidate == name+":latest" {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// PullModel downloads the named model. If progress is not nil, it is called
+// for each status update the server reports while the download runs.
+func (p *Provider) PullModel(ctx context.Context, name string, progress func(PullProgress)) error {
+	body, err := p.client.Stream(ctx, http.MethodPost, "/api/pull", map[string]any{"model": name, "stream": true})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = body.Close() }()
+
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var update PullProgress
+		if err := json.Unmarshal([]byte(line), &update); err != nil {
+			return fmt.Errorf("invalid pull progress: %w", err)
+		}
+		if update.Error != "" {
+			return fmt.Errorf("pull %s failed: %s", name, update.Error)
+		}
+		if progress != nil {
+			progress(update)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading pull progress: %w", err)
+	}
+
+	return nil
+}
+
+// EnsureModel pulls the named model unless it is already available, so that
+// an agent can be started against a fresh server.
+func (p *Provider) EnsureModel(ctx context.Context, name string, progress func(PullProgress)) error {
+	ok, err := p.HasModel(ctx, name)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return nil
+	}
+	return p.PullModel(ctx, name, progress)
+}
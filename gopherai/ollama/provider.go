@@ -0,0 +1,80 @@
+// Package ollama provides a native Ollama API provider implementation.
+package ollama
+
+import "github.com/marti-jorda-roca/gopher-ai/gopherai"
+
+const (
+	defaultBaseURL = "http://localhost:11434"
+)
+
+// Provider is the Ollama API provider.
+type Provider struct {
+	client  *gopherai.HTTPClient
+	model   string
+	options Options
+}
+
+// NewProvider creates a new Ollama provider for the server at the default
+// address, http://localhost:11434.
+func NewProvider() *Provider {
+	return &Provider{
+		client: gopherai.NewHTTPClient(defaultBaseURL, nil, newAPIError),
+		model:  "llama3.1",
+	}
+}
+
+// SetModel sets the model to use for requests.
+func (p *Provider) SetModel(model string) *Provider {
+	p.model = model
+	return p
+}
+
+// SetTemperature sets the temperature for requests.
+func (p *Provider) SetTemperature(temperature float64) *Provider {
+	p.options.Temperature = &temperature
+	return p
+}
+
+// SetMaxTokens sets the maximum number of tokens to generate (num_predict).
+func (p *Provider) SetMaxTokens(maxTokens int) *Provider {
+	p.options.NumPredict = &maxTokens
+	return p
+}
+
+// SetNumCtx sets the size of the context window (num_ctx).
+func (p *Provider) SetNumCtx(numCtx int) *Provider {
+	p.options.NumCtx = &numCtx
+	return p
+}
+
+// SetSeed sets the random seed, for reproducible responses.
+func (p *Provider) SetSeed(seed int) *Provider {
+	p.options.Seed = &seed
+	return p
+}
+
+// SetBaseURL sets a custom base URL for API requests.
+func (p *Provider) SetBaseURL(url string) *Provider {
+	p.client.SetBaseURL(url)
+	return p
+}
+
+// SetAuthenticator sets how requests are authenticated, for servers behind a
+// proxy that requires credentials, such as gopherai.BearerToken.
+func (p *Provider) SetAuthenticator(auth gopherai.Authenticator) *Provider {
+	p.client.SetAuthenticator(auth)
+	return p
+}
+
+// SetHeader sets a header sent with every request.
+func (p *Provider) SetHeader(name, value string) *Provider {
+	p.client.SetHeader(name, value)
+	return p
+}
+
+// SetRetryPolicy sets how failed requests are retried. Requests are not
+// retried by default; gopherai.DefaultRetryPolicy is a good starting point.
+func (p *Provider) SetRetryPolicy(policy gopherai.RetryPolicy) *Provider {
+	p.client.SetRetryPolicy(policy)
+	return p
+}
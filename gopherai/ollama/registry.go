@@ -0,0 +1,48 @@
+package ollama
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+)
+
+// init registers the "ollama" provider URI scheme, which accepts the num_ctx
+// and seed parameters. No API key is needed.
+func init() {
+	gopherai.RegisterProvider("ollama", gopherai.ProviderRegistration{
+		Params: []string{"num_ctx", "seed"},
+		New:    newFromConfig,
+	})
+}
+
+func newFromConfig(cfg gopherai.ProviderConfig) (gopherai.Provider, error) {
+	p := NewProvider()
+	if cfg.Model != "" {
+		p.SetModel(cfg.Model)
+	}
+	if cfg.BaseURL != "" {
+		p.SetBaseURL(cfg.BaseURL)
+	}
+	if cfg.Temperature != nil {
+		p.SetTemperature(*cfg.Temperature)
+	}
+	if cfg.MaxTokens != nil {
+		p.SetMaxTokens(*cfg.MaxTokens)
+	}
+	if value := cfg.Params.Get("num_ctx"); value != "" {
+		numCtx, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid num_ctx %q", value)
+		}
+		p.SetNumCtx(numCtx)
+	}
+	if value := cfg.Params.Get("seed"); value != "" {
+		seed, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid seed %q", value)
+		}
+		p.SetSeed(seed)
+	}
+	return p, nil
+}
@@ -0,0 +1,340 @@
+package ollama
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+)
+
+// CreateResponse sends a request to the /api/chat endpoint.
+func (p *Provider) CreateResponse(ctx context.Context, req any) (any, error) {
+	chatReq, ok := req.(*ChatRequest)
+	if !ok {
+		return nil, fmt.Errorf("invalid request type: expected *ChatRequest")
+	}
+
+	chatReq.Stream = false
+
+	var result ChatResponse
+
+	if err := p.client.Do(ctx, http.MethodPost, "/api/chat", chatReq, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// ConvertTool converts a gopherai.Tool to an Ollama Tool.
+func (p *Provider) ConvertTool(tool gopherai.Tool) any {
+	return Tool{
+		Type: "function",
+		Function: Function{
+			Name:        tool.Name,
+			Description: tool.Description,
+			Parameters:  tool.Parameters,
+		},
+	}
+}
+
+// ExtractToolCalls extracts tool calls from a response. Ollama may omit call
+// IDs, in which case positional IDs are generated.
+func (p *Provider) ExtractToolCalls(resp any) ([]gopherai.ToolCall, error) {
+	response, ok := resp.(*ChatResponse)
+	if !ok {
+		return nil, fmt.Errorf("invalid response type: expected *ChatResponse")
+	}
+	return toToolCalls(response.Message.ToolCalls, 0), nil
+}
+
+// ExtractText extracts text content from a response.
+func (p *Provider) ExtractText(resp any) string {
+	response, ok := resp.(*ChatResponse)
+	if !ok {
+		return ""
+	}
+	return response.Message.Content
+}
+
+// ExtractUsage extracts token usage from a response.
+func (p *Provider) ExtractUsage(resp any) (gopherai.Usage, bool) {
+	response, ok := resp.(*ChatResponse)
+	if !ok {
+		return gopherai.Usage{}, false
+	}
+	return toUsage(response), true
+}
+
+func toUsage(response *ChatResponse) gopherai.Usage {
+	return gopherai.Usage{
+		Model:        response.Model,
+		InputTokens:  response.PromptEvalCount,
+		OutputTokens: response.EvalCount,
+	}
+}
+
+// BuildRequest builds a ChatRequest from the given parameters.
+func (p *Provider) BuildRequest(messages []gopherai.Message, systemPrompt string, tools []any) any {
+	ollamaTools := make([]Tool, len(tools))
+	for i, tool := range tools {
+		ollamaTools[i] = tool.(Tool)
+	}
+
+	var chatMessages []Message
+	if systemPrompt != "" {
+		chatMessages = append(chatMessages, Message{Role: "system", Content: systemPrompt})
+	}
+	chatMessages = append(chatMessages, ConvertMessages(messages)...)
+
+	req := &ChatRequest{
+		Model:    p.model,
+		Messages: chatMessages,
+		Tools:    ollamaTools,
+	}
+	if p.options != (Options{}) {
+		options := p.options
+		req.Options = &options
+	}
+	return req
+}
+
+// SetOutputSchema constrains the response to JSON matching the schema using
+// the format field.
+func (p *Provider) SetOutputSchema(req any, schema gopherai.OutputSchema) error {
+	chatReq, ok := req.(*ChatRequest)
+	if !ok {
+		return fmt.Errorf("invalid request type: expected *ChatRequest")
+	}
+	chatReq.Format = schema.Schema
+	return nil
+}
+
+// ConvertMessages converts gopherai messages into Ollama messages. Each tool
+// result becomes a separate "tool" message named after its tool, which is
+// looked up by call ID when the result does not carry a name.
+func ConvertMessages(messages []gopherai.Message) []Message {
+	callNames := make(map[string]string)
+	result := make([]Message, 0, len(messages))
+
+	for _, msg := range messages {
+		switch msg.Role {
+		case gopherai.RoleAssistant:
+			ollamaMsg := Message{Role: "assistant", Content: msg.Text()}
+			for _, call := range msg.ToolCalls() {
+				callNames[call.CallID] = call.Name
+				ollamaMsg.ToolCalls = append(ollamaMsg.ToolCalls, ToolCall{
+					Function: FunctionCall{Name: call.Name, Arguments: toolArguments(call.Arguments)},
+				})
+			}
+			result = append(result, ollamaMsg)
+
+		case gopherai.RoleTool:
+			for _, res := range msg.ToolResults() {
+				name := res.Name
+				if name == "" {
+					name = callNames[res.CallID]
+				}
+				result = append(result, Message{Role: "tool", Content: res.Output, ToolName: name})
+			}
+
+		default:
+			result = append(result, Message{Role: "user", Content: msg.Text()})
+		}
+	}
+
+	return result
+}
+
+// ToMessages converts Ollama messages into gopherai messages. Ollama does not
+// keep call IDs in the history, so assistant tool calls are numbered in order
+// and each tool message is matched to the earliest pending call of its tool.
+func ToMessages(messages []Message) []gopherai.Message {
+	result := make([]gopherai.Message, 0, len(messages))
+	pending := make(map[string][]string)
+	next := 0
+
+	for _, msg := range messages {
+		switch msg.Role {
+		case "assistant":
+			var parts []gopherai.Part
+			if msg.Content != "" {
+				parts = append(parts, gopherai.TextPart(msg.Content))
+			}
+			for _, call := range toToolCalls(msg.ToolCalls, next) {
+				pending[call.Name] = append(pending[call.Name], call.CallID)
+				parts = append(parts, gopherai.ToolCallPart(call))
+			}
+			next += len(msg.ToolCalls)
+			result = append(result, gopherai.Message{Role: gopherai.RoleAssistant, Parts: parts})
+
+		case "tool":
+			var callID string
+			if ids := pending[msg.ToolName]; len(ids) > 0 {
+				callID = ids[0]
+				pending[msg.ToolName] = ids[1:]
+			}
+			part := gopherai.ToolResultPart(gopherai.ToolResult{
+				CallID: callID,
+				Name:   msg.ToolName,
+				Output: msg.Content,
+			})
+			if n := len(result); n > 0 && result[n-1].Role == gopherai.RoleTool {
+				result[n-1].Parts = append(result[n-1].Parts, part)
+				continue
+			}
+			result = append(result, gopherai.Message{Role: gopherai.RoleTool, Parts: []gopherai.Part{part}})
+
+		case "user":
+			result = append(result, gopherai.Message{Role: gopherai.RoleUser, Parts: []gopherai.Part{gopherai.TextPart(msg.Content)}})
+		}
+	}
+
+	return result
+}
+
+// toolArguments returns tool call arguments as a JSON object, since Ollama
+// expects an object rather than a string.
+func toolArguments(arguments string) json.RawMessage {
+	if !json.Valid([]byte(arguments)) || strings.TrimSpace(arguments) == "null" {
+		return json.RawMessage("{}")
+	}
+	return json.RawMessage(arguments)
+}
+
+// toToolCalls converts Ollama tool calls, generating "call_N" IDs from offset
+// when the server does not provide them.
+func toToolCalls(calls []ToolCall, offset int) []gopherai.ToolCall {
+	result := make([]gopherai.ToolCall, 0, len(calls))
+	for i, call := range calls {
+		id := call.ID
+		if id == "" {
+			id = fmt.Sprintf("call_%d", offset+i)
+		}
+		arguments := strings.TrimSpace(string(call.Function.Arguments))
+		if arguments == "" || arguments == "null" {
+			arguments = "{}"
+		}
+		result = append(result, gopherai.ToolCall{
+			Name:      call.Function.Name,
+			Arguments: arguments,
+			CallID:    id,
+		})
+	}
+	return result
+}
+
+// CreateResponseStream sends a streaming request to the /api/chat endpoint.
+// The response is newline-delimited JSON rather than server-sent events.
+func (p *Provider) CreateResponseStream(ctx context.Context, req any) (<-chan gopherai.StreamEvent, error) {
+	chatReq, ok := req.(*ChatRequest)
+	if !ok {
+		return nil, fmt.Errorf("invalid request type: expected *ChatRequest")
+	}
+
+	chatReq.Stream = true
+
+	body, err := p.client.Stream(ctx, http.MethodPost, "/api/chat", chatReq)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan gopherai.StreamEvent, 100)
+	go func() {
+		defer close(events)
+		defer func() { _ = body.Close() }()
+		parseNDJSONStreamReader(body, events)
+	}()
+
+	return events, nil
+}
+
+// ParseNDJSONStreamForTest exposes stream parsing for testing.
+func ParseNDJSONStreamForTest(r io.Reader) <-chan gopherai.StreamEvent {
+	events := make(chan gopherai.StreamEvent, 100)
+	go func() {
+		defer close(events)
+		parseNDJSONStreamReader(r, events)
+	}()
+	return events
+}
+
+// parseNDJSONStreamReader parses a stream of ChatResponse chunks, one JSON
+// object per line, until the chunk marked done.
+func parseNDJSONStreamReader(r io.Reader, events chan<- gopherai.StreamEvent) {
+	reader := bufio.NewReader(r)
+	var fullText strings.Builder
+	toolCalls := 0
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			events <- gopherai.StreamEvent{
+				Type:  gopherai.StreamEventTypeError,
+				Error: err,
+			}
+			return
+		}
+
+		if trimmed := strings.TrimSpace(line); trimmed != "" {
+			var chunk ChatResponse
+			if jsonErr := json.Unmarshal([]byte(trimmed), &chunk); jsonErr != nil {
+				events <- gopherai.StreamEvent{
+					Type:  gopherai.StreamEventTypeError,
+					Error: fmt.Errorf("invalid stream chunk: %w", jsonErr),
+				}
+				return
+			}
+
+			if chunk.Error != "" {
+				events <- gopherai.StreamEvent{
+					Type:  gopherai.StreamEventTypeError,
+					Error: &gopherai.APIError{Provider: providerName, Message: chunk.Error},
+				}
+				return
+			}
+
+			if chunk.Message.Content != "" {
+				fullText.WriteString(chunk.Message.Content)
+				events <- gopherai.StreamEvent{
+					Type:  gopherai.StreamEventTypeTextDelta,
+					Delta: chunk.Message.Content,
+				}
+			}
+
+			for _, call := range toToolCalls(chunk.Message.ToolCalls, toolCalls) {
+				events <- gopherai.StreamEvent{
+					Type:     gopherai.StreamEventTypeToolCall,
+					ToolCall: &call,
+				}
+			}
+			toolCalls += len(chunk.Message.ToolCalls)
+
+			if chunk.Done {
+				if fullText.Len() > 0 {
+					events <- gopherai.StreamEvent{
+						Type: gopherai.StreamEventTypeTextDone,
+						Text: fullText.String(),
+					}
+				}
+				usage := toUsage(&chunk)
+				events <- gopherai.StreamEvent{
+					Type:  gopherai.StreamEventTypeUsage,
+					Usage: &usage,
+				}
+				events <- gopherai.StreamEvent{
+					Type: gopherai.StreamEventTypeDone,
+				}
+				return
+			}
+		}
+
+		if err == io.EOF {
+			return
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package ollama
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ChatRequest represents a request to the /api/chat endpoint.
+type ChatRequest struct {
+	Model    string         `json:"model"`
+	Messages []Message      `json:"messages"`
+	Tools    []Tool         `json:"tools,omitempty"`
+	Format   map[string]any `json:"format,omitempty"`
+	Options  *Options       `json:"options,omitempty"`
+	// Stream must be sent explicitly, since the API streams by default.
+	Stream bool `json:"stream"`
+}
+
+// Options holds model parameters sent in the options block.
+type Options struct {
+	NumCtx      *int     `json:"num_ctx,omitempty"`
+	NumPredict  *int     `json:"num_predict,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+	Seed        *int     `json:"seed,omitempty"`
+}
+
+// Message represents a message in the conversation.
+type Message struct {
+	Role      string     `json:"role"`
+	Content   string     `json:"content"`
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	// ToolName names the tool whose result a "tool" message holds.
+	ToolName string `json:"tool_name,omitempty"`
+}
+
+// ToolCall represents a tool call requested by the model.
+type ToolCall struct {
+	ID       string       `json:"id,omitempty"`
+	Function FunctionCall `json:"function"`
+}
+
+// FunctionCall holds the function name and arguments of a tool call.
+type FunctionCall struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// Tool represents a tool definition.
+type Tool struct {
+	Type     string   `json:"type"`
+	Function Function `json:"function"`
+}
+
+// Function describes a function the model may call.
+type Function struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters"`
+}
+
+// ChatResponse represents a response, or a streamed chunk, from /api/chat.
+type ChatResponse struct {
+	Model           string    `json:"model"`
+	CreatedAt       time.Time `json:"created_at"`
+	Message         Message   `json:"message"`
+	Done            bool      `json:"done"`
+	DoneReason      string    `json:"done_reason,omitempty"`
+	TotalDuration   int64     `json:"total_duration,omitempty"`
+	PromptEvalCount int       `json:"prompt_eval_count,omitempty"`
+	EvalCount       int       `json:"eval_count,omitempty"`
+	Error           string    `json:"error,omitempty"`
+}
+
+// ModelInfo describes a model available on the server.
+type ModelInfo struct {
+	Name       string       `json:"name"`
+	Model      string       `json:"model"`
+	ModifiedAt time.Time    `json:"modified_at"`
+	Size       int64        `json:"size"`
+	Digest     string       `json:"digest"`
+	Details    ModelDetails `json:"details"`
+}
+
+// ModelDetails holds the format and family of a model.
+type ModelDetails struct {
+	Format            string `json:"format"`
+	Family            string `json:"family"`
+	ParameterSize     string `json:"parameter_size"`
+	QuantizationLevel string `json:"quantization_level"`
+}
+
+// PullProgress reports the progress of a model download.
+type PullProgress struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest,omitempty"`
+	Total     int64  `json:"total,omitempty"`
+	Completed int64  `json:"completed,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// APIError represents an error response from the API.
+type APIError struct {
+	Error string `json:"error"`
+}
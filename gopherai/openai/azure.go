@@ -0,0 +1,58 @@
+package openai
+
+import (
+	"strings"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+)
+
+// DefaultAzureAPIVersion is the api-version used when AzureConfig does not
+// set one.
+const DefaultAzureAPIVersion = "2025-04-01-preview"
+
+// AzureConfig describes an Azure OpenAI deployment.
+type AzureConfig struct {
+	// Endpoint is the resource endpoint, such as
+	// "https://my-resource.openai.azure.com".
+	Endpoint string
+	// Deployment is the name of the model deployment. It is sent as the model.
+	Deployment string
+	// APIVersion is sent as the api-version query parameter.
+	APIVersion string
+}
+
+func (c AzureConfig) apiVersion() string {
+	if c.APIVersion == "" {
+		return DefaultAzureAPIVersion
+	}
+	return c.APIVersion
+}
+
+func (c AzureConfig) endpoint() string {
+	return strings.TrimRight(c.Endpoint, "/")
+}
+
+// NewAzureProvider creates a Responses API provider for an Azure OpenAI
+// deployment, authenticated with the api-key header. To use Microsoft Entra ID
+// instead, pass an empty key and call SetAuthenticator with a bearer token
+// authenticator.
+func NewAzureProvider(apiKey string, cfg AzureConfig) *Provider {
+	p := NewProvider(apiKey).
+		SetBaseURL(cfg.endpoint() + "/openai").
+		SetModel(cfg.Deployment).
+		SetAuthenticator(gopherai.APIKeyHeader("api-key", apiKey))
+	p.client.SetQueryParam("api-version", cfg.apiVersion())
+	return p
+}
+
+// NewAzureChatProvider creates a Chat Completions provider for an Azure OpenAI
+// deployment, authenticated with the api-key header. Requests are sent to the
+// deployment's URL, /openai/deployments/{deployment}/chat/completions.
+func NewAzureChatProvider(apiKey string, cfg AzureConfig) *ChatProvider {
+	p := NewChatProvider(apiKey).
+		SetBaseURL(cfg.endpoint() + "/openai/deployments/" + cfg.Deployment).
+		SetModel(cfg.Deployment).
+		SetAuthenticator(gopherai.APIKeyHeader("api-key", apiKey))
+	p.client.SetQueryParam("api-version", cfg.apiVersion())
+	return p
+}
@@ -0,0 +1,444 @@
+package openai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+)
+
+// ChatProvider talks to the Chat Completions API (/chat/completions), which is
+// implemented by OpenAI and by most OpenAI-compatible servers, such as vLLM,
+// llama.cpp, LM Studio and Ollama. Use SetBaseURL to point it at them.
+type ChatProvider struct {
+	client      *gopherai.HTTPClient
+	model       string
+	temperature *float64
+	maxTokens   *int
+}
+
+// NewChatProvider creates a new Chat Completions provider with the given API
+// key. The key may be empty for local servers that do not require one.
+func NewChatProvider(apiKey string) *ChatProvider {
+	return &ChatProvider{
+		client: gopherai.NewHTTPClient(defaultBaseURL, gopherai.BearerToken(apiKey), newAPIError),
+		model:  "gpt-4.1",
+	}
+}
+
+// SetModel sets the model to use for requests.
+func (p *ChatProvider) SetModel(model string) *ChatProvider {
+	p.model = model
+	return p
+}
+
+// SetTemperature sets the temperature for requests.
+func (p *ChatProvider) SetTemperature(temperature float64) *ChatProvider {
+	p.temperature = &temperature
+	return p
+}
+
+// SetMaxTokens sets the maximum output tokens for requests.
+func (p *ChatProvider) SetMaxTokens(maxTokens int) *ChatProvider {
+	p.maxTokens = &maxTokens
+	return p
+}
+
+// SetBaseURL sets a custom base URL for API requests, such as
+// "http://localhost:8000/v1" for a local server.
+func (p *ChatProvider) SetBaseURL(url string) *ChatProvider {
+	p.client.SetBaseURL(url)
+	return p
+}
+
+// SetAuthenticator replaces the API key authentication, for example with
+// gopherai.APIKeyHeader or with a gateway that signs requests. A nil
+// authenticator sends requests without credentials.
+func (p *ChatProvider) SetAuthenticator(auth gopherai.Authenticator) *ChatProvider {
+	p.client.SetAuthenticator(auth)
+	return p
+}
+
+// SetOrganization sets the OpenAI-Organization header.
+func (p *ChatProvider) SetOrganization(organization string) *ChatProvider {
+	return p.SetHeader("OpenAI-Organization", organization)
+}
+
+// SetProject sets the OpenAI-Project header.
+func (p *ChatProvider) SetProject(project string) *ChatProvider {
+	return p.SetHeader("OpenAI-Project", project)
+}
+
+// SetHeader sets a header sent with every request.
+func (p *ChatProvider) SetHeader(name, value string) *ChatProvider {
+	p.client.SetHeader(name, value)
+	return p
+}
+
+// SetRetryPolicy sets how failed requests are retried. Requests are not
+// retried by default; gopherai.DefaultRetryPolicy is a good starting point.
+func (p *ChatProvider) SetRetryPolicy(policy gopherai.RetryPolicy) *ChatProvider {
+	p.client.SetRetryPolicy(policy)
+	return p
+}
+
+// CreateResponse sends a request to the Chat Completions API.
+func (p *ChatProvider) CreateResponse(ctx context.Context, req any) (any, error) {
+	chatReq, ok := req.(*ChatCompletionRequest)
+	if !ok {
+		return nil, fmt.Errorf("invalid request type: expected *ChatCompletionRequest")
+	}
+
+	var result ChatCompletionResponse
+
+	if err := p.client.Do(ctx, http.MethodPost, "/chat/completions", chatReq, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// ConvertTool converts a gopherai.Tool to a ChatTool. Strict mode is enabled
+// when the parameters schema allows it.
+func (p *ChatProvider) ConvertTool(tool gopherai.Tool) any {
+	var strict *bool
+	if isStrictSchema(tool.Parameters) {
+		enabled := true
+		strict = &enabled
+	}
+	return ChatTool{
+		Type: "function",
+		Function: ChatFunction{
+			Name:        tool.Name,
+			Description: tool.Description,
+			Parameters:  tool.Parameters,
+			Strict:      strict,
+		},
+	}
+}
+
+// ExtractToolCalls extracts tool calls from the first choice of a response.
+func (p *ChatProvider) ExtractToolCalls(resp any) ([]gopherai.ToolCall, error) {
+	response, ok := resp.(*ChatCompletionResponse)
+	if !ok {
+		return nil, fmt.Errorf("invalid response type: expected *ChatCompletionResponse")
+	}
+	if len(response.Choices) == 0 {
+		return nil, nil
+	}
+
+	var calls []gopherai.ToolCall
+	for _, call := range response.Choices[0].Message.ToolCalls {
+		calls = append(calls, gopherai.ToolCall{
+			Name:      call.Function.Name,
+			Arguments: call.Function.Arguments,
+			CallID:    call.ID,
+		})
+	}
+	return calls, nil
+}
+
+// ExtractText extracts the text content of the first choice of a response.
+func (p *ChatProvider) ExtractText(resp any) string {
+	response, ok := resp.(*ChatCompletionResponse)
+	if !ok || len(response.Choices) == 0 {
+		return ""
+	}
+	return response.Choices[0].Message.Content
+}
+
+// ExtractUsage extracts token usage from a response.
+func (p *ChatProvider) ExtractUsage(resp any) (gopherai.Usage, bool) {
+	response, ok := resp.(*ChatCompletionResponse)
+	if !ok || response.Usage == nil {
+		return gopherai.Usage{}, false
+	}
+	return toChatUsage(response.Model, response.Usage), true
+}
+
+func toChatUsage(model string, usage *ChatUsage) gopherai.Usage {
+	result := gopherai.Usage{
+		Model:        model,
+		InputTokens:  usage.PromptTokens,
+		OutputTokens: usage.CompletionTokens,
+	}
+	if details := usage.PromptTokensDetails; details != nil {
+		result.CachedInputTokens = details.CachedTokens
+	}
+	if details := usage.CompletionTokensDetails; details != nil {
+		result.ReasoningTokens = details.ReasoningTokens
+	}
+	return result
+}
+
+// BuildRequest builds a ChatCompletionRequest from the given parameters.
+func (p *ChatProvider) BuildRequest(messages []gopherai.Message, systemPrompt string, tools []any) any {
+	chatTools := make([]ChatTool, len(tools))
+	for i, tool := range tools {
+		chatTools[i] = tool.(ChatTool)
+	}
+
+	var chatMessages []ChatMessage
+	if systemPrompt != "" {
+		chatMessages = append(chatMessages, ChatMessage{Role: "system", Content: systemPrompt})
+	}
+	chatMessages = append(chatMessages, ConvertChatMessages(messages)...)
+
+	return &ChatCompletionRequest{
+		Model:       p.model,
+		Messages:    chatMessages,
+		Tools:       chatTools,
+		Temperature: p.temperature,
+		MaxTokens:   p.maxTokens,
+	}
+}
+
+// SetOutputSchema constrains the response to a JSON schema using the
+// json_schema response format.
+func (p *ChatProvider) SetOutputSchema(req any, schema gopherai.OutputSchema) error {
+	chatReq, ok := req.(*ChatCompletionRequest)
+	if !ok {
+		return fmt.Errorf("invalid request type: expected *ChatCompletionRequest")
+	}
+
+	strict := isStrictSchema(schema.Schema)
+	chatReq.ResponseFormat = &ChatResponseFormat{
+		Type: "json_schema",
+		JSONSchema: &ChatJSONSchema{
+			Name:   schema.Name,
+			Schema: schema.Schema,
+			Strict: &strict,
+		},
+	}
+	return nil
+}
+
+// ConvertChatMessages converts gopherai messages into chat messages. Each
+// tool result becomes a separate "tool" message.
+func ConvertChatMessages(messages []gopherai.Message) []ChatMessage {
+	result := make([]ChatMessage, 0, len(messages))
+
+	for _, msg := range gopherai.NormalizeToolCallIDs(messages) {
+		switch msg.Role {
+		case gopherai.RoleAssistant:
+			chatMsg := ChatMessage{Role: "assistant", Content: msg.Text()}
+			for _, call := range msg.ToolCalls() {
+				chatMsg.ToolCalls = append(chatMsg.ToolCalls, ChatToolCall{
+					ID:       call.CallID,
+					Type:     "function",
+					Function: ChatFunctionCall{Name: call.Name, Arguments: call.Arguments},
+				})
+			}
+			result = append(result, chatMsg)
+
+		case gopherai.RoleTool:
+			for _, res := range msg.ToolResults() {
+				result = append(result, ChatMessage{Role: "tool", Content: res.Output, ToolCallID: res.CallID})
+			}
+
+		default:
+			result = append(result, ChatMessage{Role: "user", Content: msg.Text()})
+		}
+	}
+
+	return result
+}
+
+// ChatToMessages converts chat messages into gopherai messages. System
+// messages are skipped and consecutive tool messages are grouped into a
+// single tool message.
+func ChatToMessages(messages []ChatMessage) []gopherai.Message {
+	var result []gopherai.Message
+	callNames := make(map[string]string)
+
+	for _, msg := range messages {
+		switch msg.Role {
+		case "assistant":
+			calls := make([]gopherai.ToolCall, len(msg.ToolCalls))
+			for i, call := range msg.ToolCalls {
+				calls[i] = gopherai.ToolCall{Name: call.Function.Name, Arguments: call.Function.Arguments, CallID: call.ID}
+				callNames[call.ID] = call.Function.Name
+			}
+			result = append(result, gopherai.NewAssistantMessage(msg.Content, calls...))
+
+		case "tool":
+			part := gopherai.ToolResultPart(gopherai.ToolResult{
+				CallID: msg.ToolCallID,
+				Name:   callNames[msg.ToolCallID],
+				Output: msg.Content,
+			})
+			if n := len(result); n > 0 && result[n-1].Role == gopherai.RoleTool {
+				result[n-1].Parts = append(result[n-1].Parts, part)
+				continue
+			}
+			result = append(result, gopherai.Message{Role: gopherai.RoleTool, Parts: []gopherai.Part{part}})
+
+		case "user":
+			result = append(result, gopherai.NewUserMessage(msg.Content))
+		}
+	}
+
+	return result
+}
+
+// CreateResponseStream sends a streaming request to the Chat Completions API.
+func (p *ChatProvider) CreateResponseStream(ctx context.Context, req any) (<-chan gopherai.StreamEvent, error) {
+	chatReq, ok := req.(*ChatCompletionRequest)
+	if !ok {
+		return nil, fmt.Errorf("invalid request type: expected *ChatCompletionRequest")
+	}
+
+	chatReq.Stream = true
+	chatReq.StreamOptions = &ChatStreamOptions{IncludeUsage: true}
+
+	body, err := p.client.Stream(ctx, http.MethodPost, "/chat/completions", chatReq)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan gopherai.StreamEvent, 100)
+	go func() {
+		defer close(events)
+		defer func() { _ = body.Close() }()
+		parseChatStreamReader(body, events)
+	}()
+
+	return events, nil
+}
+
+// ParseChatStreamForTest exposes chat stream parsing for testing.
+func ParseChatStreamForTest(r io.Reader) <-chan gopherai.StreamEvent {
+	events := make(chan gopherai.StreamEvent, 100)
+	go func() {
+		defer close(events)
+		parseChatStreamReader(r, events)
+	}()
+	return events
+}
+
+// parseChatStreamReader parses a chat completion SSE stream. Tool call names
+// and arguments arrive in fragments keyed by index; the calls are emitted once
+// the stream finishes, together with the usage sent in the last chunk.
+func parseChatStreamReader(r io.Reader, events chan<- gopherai.StreamEvent) {
+	reader := bufio.NewReader(r)
+	var fullText strings.Builder
+	toolCalls := make(map[int]*gopherai.ToolCall)
+	var usage *gopherai.Usage
+
+	finish := func() {
+		indexes := make([]int, 0, len(toolCalls))
+		for index := range toolCalls {
+			indexes = append(indexes, index)
+		}
+		sort.Ints(indexes)
+		for _, index := range indexes {
+			events <- gopherai.StreamEvent{
+				Type:     gopherai.StreamEventTypeToolCall,
+				ToolCall: toolCalls[index],
+			}
+		}
+		if fullText.Len() > 0 {
+			events <- gopherai.StreamEvent{
+				Type: gopherai.StreamEventTypeTextDone,
+				Text: fullText.String(),
+			}
+		}
+		if usage != nil {
+			events <- gopherai.StreamEvent{
+				Type:  gopherai.StreamEventTypeUsage,
+				Usage: usage,
+			}
+		}
+		events <- gopherai.StreamEvent{
+			Type: gopherai.StreamEventTypeDone,
+		}
+	}
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			if err != io.EOF {
+				events <- gopherai.StreamEvent{
+					Type:  gopherai.StreamEventTypeError,
+					Error: err,
+				}
+				return
+			}
+			break
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" || !strings.HasPrefix(line, "data:") {
+			continue
+		}
+
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk struct {
+			ChatCompletionChunk
+			Error *struct {
+				Message string `json:"message"`
+				Type    string `json:"type"`
+				Code    string `json:"code"`
+			} `json:"error,omitempty"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			continue
+		}
+		if chunk.Error != nil {
+			events <- gopherai.StreamEvent{
+				Type:  gopherai.StreamEventTypeError,
+				Error: newStreamError(chunk.Error.Code, chunk.Error.Type, chunk.Error.Message),
+			}
+			return
+		}
+		if chunk.Usage != nil {
+			chunkUsage := toChatUsage(chunk.Model, chunk.Usage)
+			usage = &chunkUsage
+		}
+
+		for _, choice := range chunk.Choices {
+			if choice.Index != 0 {
+				continue
+			}
+
+			if choice.Delta.Content != "" {
+				fullText.WriteString(choice.Delta.Content)
+				events <- gopherai.StreamEvent{
+					Type:  gopherai.StreamEventTypeTextDelta,
+					Delta: choice.Delta.Content,
+				}
+			}
+
+			for i, fragment := range choice.Delta.ToolCalls {
+				index := i
+				if fragment.Index != nil {
+					index = *fragment.Index
+				}
+				tc, ok := toolCalls[index]
+				if !ok {
+					tc = &gopherai.ToolCall{}
+					toolCalls[index] = tc
+				}
+				if fragment.ID != "" {
+					tc.CallID = fragment.ID
+				}
+				if fragment.Function.Name != "" {
+					tc.Name = fragment.Function.Name
+				}
+				tc.Arguments += fragment.Function.Arguments
+			}
+		}
+	}
+
+	finish()
+}
@@ -0,0 +1,120 @@
+package openai
+
+// ChatCompletionRequest represents a request to the Chat Completions API.
+type ChatCompletionRequest struct {
+	Model          string              `json:"model"`
+	Messages       []ChatMessage       `json:"messages"`
+	Tools          []ChatTool          `json:"tools,omitempty"`
+	Temperature    *float64            `json:"temperature,omitempty"`
+	MaxTokens      *int                `json:"max_tokens,omitempty"`
+	ResponseFormat *ChatResponseFormat `json:"response_format,omitempty"`
+	Stream         bool                `json:"stream,omitempty"`
+	StreamOptions  *ChatStreamOptions  `json:"stream_options,omitempty"`
+}
+
+// ChatStreamOptions configures a streamed chat completion.
+type ChatStreamOptions struct {
+	// IncludeUsage asks for a last chunk reporting the usage of the request.
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// ChatMessage represents a message in a chat completion conversation.
+type ChatMessage struct {
+	Role       string         `json:"role"`
+	Content    string         `json:"content"`
+	ToolCalls  []ChatToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string         `json:"tool_call_id,omitempty"`
+}
+
+// ChatToolCall represents a tool call requested by the model. In streaming
+// chunks, Index identifies the call that a fragment belongs to.
+type ChatToolCall struct {
+	Index    *int             `json:"index,omitempty"`
+	ID       string           `json:"id,omitempty"`
+	Type     string           `json:"type,omitempty"`
+	Function ChatFunctionCall `json:"function"`
+}
+
+// ChatFunctionCall holds the function name and JSON arguments of a tool call.
+type ChatFunctionCall struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments"`
+}
+
+// ChatTool represents a tool definition for the Chat Completions API.
+type ChatTool struct {
+	Type     string       `json:"type"`
+	Function ChatFunction `json:"function"`
+}
+
+// ChatFunction describes a function the model may call.
+type ChatFunction struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters,omitempty"`
+	Strict      *bool          `json:"strict,omitempty"`
+}
+
+// ChatResponseFormat constrains the response, for example to a JSON schema.
+type ChatResponseFormat struct {
+	Type       string          `json:"type"`
+	JSONSchema *ChatJSONSchema `json:"json_schema,omitempty"`
+}
+
+// ChatJSONSchema is the JSON schema of a json_schema response format.
+type ChatJSONSchema struct {
+	Name   string         `json:"name"`
+	Schema map[string]any `json:"schema"`
+	Strict *bool          `json:"strict,omitempty"`
+}
+
+// ChatCompletionResponse represents the response from the Chat Completions API.
+type ChatCompletionResponse struct {
+	ID      string       `json:"id"`
+	Object  string       `json:"object"`
+	Created int64        `json:"created"`
+	Model   string       `json:"model"`
+	Choices []ChatChoice `json:"choices"`
+	Usage   *ChatUsage   `json:"usage,omitempty"`
+}
+
+// ChatChoice represents a completion choice.
+type ChatChoice struct {
+	Index        int         `json:"index"`
+	Message      ChatMessage `json:"message"`
+	FinishReason string      `json:"finish_reason"`
+}
+
+// ChatUsage represents token usage information.
+type ChatUsage struct {
+	PromptTokens            int                      `json:"prompt_tokens"`
+	CompletionTokens        int                      `json:"completion_tokens"`
+	TotalTokens             int                      `json:"total_tokens"`
+	PromptTokensDetails     *PromptTokensDetails     `json:"prompt_tokens_details,omitempty"`
+	CompletionTokensDetails *CompletionTokensDetails `json:"completion_tokens_details,omitempty"`
+}
+
+// PromptTokensDetails provides details about prompt token usage.
+type PromptTokensDetails struct {
+	CachedTokens int `json:"cached_tokens"`
+}
+
+// CompletionTokensDetails provides details about completion token usage.
+type CompletionTokensDetails struct {
+	ReasoningTokens int `json:"reasoning_tokens"`
+}
+
+// ChatCompletionChunk represents a streamed chunk of a chat completion.
+type ChatCompletionChunk struct {
+	ID      string            `json:"id"`
+	Model   string            `json:"model,omitempty"`
+	Choices []ChatChunkChoice `json:"choices"`
+	Usage   *ChatUsage        `json:"usage,omitempty"`
+}
+
+// ChatChunkChoice represents the delta of a choice in a streamed chunk.
+type ChatChunkChoice struct {
+	Index        int         `json:"index"`
+	Delta        ChatMessage `json:"delta"`
+	FinishReason *string     `json:"finish_reason"`
+}
@@ -0,0 +1,57 @@
+package openai
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+)
+
+const providerName = "openai"
+
+// newAPIError converts an error response into a gopherai.APIError, reading
+// the code and message from the APIError payload when the body holds one.
+func newAPIError(statusCode int, header http.Header, body []byte) *gopherai.APIError {
+	e := gopherai.NewAPIError(providerName, statusCode, header, body)
+
+	var payload APIError
+	if err := json.Unmarshal(body, &payload); err == nil && payload.Error.Message != "" {
+		e.Message = payload.Error.Message
+		e.Code = payload.Error.Code
+		if e.Code == "" {
+			e.Code = payload.Error.Type
+		}
+		classify(e, payload.Error.Type)
+	}
+	return e
+}
+
+// newStreamError converts an error event received inside a stream.
+func newStreamError(code, errType, message string) *gopherai.APIError {
+	e := &gopherai.APIError{Provider: providerName, Code: code, Message: message}
+	if e.Code == "" {
+		e.Code = errType
+	}
+	e.Retryable = errType == "server_error" || code == "server_error" || code == "rate_limit_exceeded"
+	classify(e, errType)
+	return e
+}
+
+// classify sets the kind of error from the error code and type.
+func classify(e *gopherai.APIError, errType string) {
+	switch {
+	case e.Code == "context_length_exceeded" || strings.Contains(e.Message, "maximum context length"):
+		e.Kind = gopherai.ErrContextLengthExceeded
+	case e.Code == "content_filter" || e.Code == "content_policy_violation":
+		e.Kind = gopherai.ErrContentFiltered
+	case e.Code == "insufficient_quota":
+		// Retrying does not help until the account is topped up.
+		e.Kind = gopherai.ErrRateLimited
+		e.Retryable = false
+	case e.Code == "rate_limit_exceeded" || errType == "rate_limit_error":
+		e.Kind = gopherai.ErrRateLimited
+	case e.Code == "invalid_api_key" || errType == "authentication_error":
+		e.Kind = gopherai.ErrAuth
+	}
+}
@@ -1,9 +1,7 @@
 // Package openai provides an OpenAI API provider implementation.
 package openai
 
-import (
-	"github.com/go-resty/resty/v2"
-)
+import "github.com/marti-jorda-roca/gopher-ai/gopherai"
 
 const (
 	defaultBaseURL = "https://api.openai.com/v1"
@@ -11,9 +9,7 @@ const (
 
 // Provider is the OpenAI API provider.
 type Provider struct {
-	apiKey      string
-	baseURL     string
-	http        *resty.Client
+	client      *gopherai.HTTPClient
 	model       string
 	temperature *float64
 	maxTokens   *int
@@ -21,18 +17,10 @@ type Provider struct {
 
 // NewProvider creates a new OpenAI API provider with the given API key.
 func NewProvider(apiKey string) *Provider {
-	p := &Provider{
-		apiKey:  apiKey,
-		baseURL: defaultBaseURL,
-		http:    resty.New(),
-		model:   "gpt-4.1",
+	return &Provider{
+		client: gopherai.NewHTTPClient(defaultBaseURL, gopherai.BearerToken(apiKey), newAPIError),
+		model:  "gpt-4.1",
 	}
-
-	p.http.SetBaseURL(p.baseURL)
-	p.http.SetHeader("Authorization", "Bearer "+apiKey)
-	p.http.SetHeader("Content-Type", "application/json")
-
-	return p
 }
 
 // SetModel sets the model to use for requests.
@@ -55,7 +43,37 @@ func (p *Provider) SetMaxTokens(maxTokens int) *Provider {
 
 // SetBaseURL sets a custom base URL for API requests.
 func (p *Provider) SetBaseURL(url string) *Provider {
-	p.baseURL = url
-	p.http.SetBaseURL(url)
+	p.client.SetBaseURL(url)
+	return p
+}
+
+// SetAuthenticator replaces the API key authentication, for example with
+// gopherai.APIKeyHeader or with a gateway that signs requests. A nil
+// authenticator sends requests without credentials.
+func (p *Provider) SetAuthenticator(auth gopherai.Authenticator) *Provider {
+	p.client.SetAuthenticator(auth)
+	return p
+}
+
+// SetOrganization sets the OpenAI-Organization header.
+func (p *Provider) SetOrganization(organization string) *Provider {
+	return p.SetHeader("OpenAI-Organization", organization)
+}
+
+// SetProject sets the OpenAI-Project header.
+func (p *Provider) SetProject(project string) *Provider {
+	return p.SetHeader("OpenAI-Project", project)
+}
+
+// SetHeader sets a header sent with every request.
+func (p *Provider) SetHeader(name, value string) *Provider {
+	p.client.SetHeader(name, value)
+	return p
+}
+
+// SetRetryPolicy sets how failed requests are retried. Requests are not
+// retried by default; gopherai.DefaultRetryPolicy is a good starting point.
+func (p *Provider) SetRetryPolicy(policy gopherai.RetryPolicy) *Provider {
+	p.client.SetRetryPolicy(policy)
 	return p
 }
@@ -0,0 +1,110 @@
+package openai
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+)
+
+// Provider URI schemes registered by this package:
+//   - "openai" uses the Responses API and accepts the organization and
+//     project parameters
+//   - "openai-chat" uses the Chat Completions API; the key is optional, so
+//     with base_url it can reach OpenAI-compatible servers
+//   - "azure" uses an Azure OpenAI deployment named by the model, with the
+//     endpoint and api_version parameters; the endpoint defaults to
+//     AZURE_OPENAI_ENDPOINT
+func init() {
+	gopherai.RegisterProvider("openai", gopherai.ProviderRegistration{
+		EnvKeys: []string{"OPENAI_API_KEY"},
+		Params:  []string{"organization", "project"},
+		New:     newFromConfig,
+	})
+	gopherai.RegisterProvider("openai-chat", gopherai.ProviderRegistration{
+		EnvKeys:     []string{"OPENAI_API_KEY"},
+		KeyOptional: true,
+		Params:      []string{"organization", "project"},
+		New:         newChatFromConfig,
+	})
+	gopherai.RegisterProvider("azure", gopherai.ProviderRegistration{
+		EnvKeys: []string{"AZURE_OPENAI_API_KEY"},
+		Params:  []string{"endpoint", "api_version"},
+		New:     newAzureFromConfig,
+	})
+}
+
+func newFromConfig(cfg gopherai.ProviderConfig) (gopherai.Provider, error) {
+	p := NewProvider(cfg.APIKey)
+	if cfg.Model != "" {
+		p.SetModel(cfg.Model)
+	}
+	if cfg.BaseURL != "" {
+		p.SetBaseURL(cfg.BaseURL)
+	}
+	if cfg.Temperature != nil {
+		p.SetTemperature(*cfg.Temperature)
+	}
+	if cfg.MaxTokens != nil {
+		p.SetMaxTokens(*cfg.MaxTokens)
+	}
+	if organization := cfg.Params.Get("organization"); organization != "" {
+		p.SetOrganization(organization)
+	}
+	if project := cfg.Params.Get("project"); project != "" {
+		p.SetProject(project)
+	}
+	return p, nil
+}
+
+func newChatFromConfig(cfg gopherai.ProviderConfig) (gopherai.Provider, error) {
+	p := NewChatProvider(cfg.APIKey)
+	if cfg.Model != "" {
+		p.SetModel(cfg.Model)
+	}
+	if cfg.BaseURL != "" {
+		p.SetBaseURL(cfg.BaseURL)
+	}
+	if cfg.Temperature != nil {
+		p.SetTemperature(*cfg.Temperature)
+	}
+	if cfg.MaxTokens != nil {
+		p.SetMaxTokens(*cfg.MaxTokens)
+	}
+	if organization := cfg.Params.Get("organization"); organization != "" {
+		p.SetOrganization(organization)
+	}
+	if project := cfg.Params.Get("project"); project != "" {
+		p.SetProject(project)
+	}
+	return p, nil
+}
+
+func newAzureFromConfig(cfg gopherai.ProviderConfig) (gopherai.Provider, error) {
+	endpoint := cfg.Params.Get("endpoint")
+	if endpoint == "" {
+		endpoint = os.Getenv("AZURE_OPENAI_ENDPOINT")
+	}
+	if endpoint == "" {
+		return nil, fmt.Errorf("missing endpoint: set the endpoint parameter or AZURE_OPENAI_ENDPOINT")
+	}
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("missing deployment: use azure:<deployment>")
+	}
+
+	p := NewAzureProvider(cfg.APIKey, AzureConfig{
+		Endpoint:   endpoint,
+		Deployment: cfg.Model,
+		APIVersion: cfg.Params.Get("api_version"),
+	})
+	if cfg.BaseURL != "" {
+		p.SetBaseURL(cfg.BaseURL)
+	}
+	if cfg.Temperature != nil {
+		p.SetTemperature(*cfg.Temperature)
+	}
+	if cfg.MaxTokens != nil {
+		p.SetMaxTokens(*cfg.MaxTokens)
+	}
+	return p, nil
+}
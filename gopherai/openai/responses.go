@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 	"strings"
 
 	"github.com/marti-jorda-roca/gopher-ai/gopherai"
@@ -19,20 +20,9 @@ func (p *Provider) CreateResponse(ctx context.Context, req any) (any, error) {
 	}
 
 	var result Response
-	var apiErr APIError
-
-	resp, err := p.http.R().
-		SetContext(ctx).
-		SetBody(createReq).
-		SetResult(&result).
-		SetError(&apiErr).
-		Post("/responses")
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
 
-	if resp.IsError() {
-		return nil, fmt.Errorf("API error: %s - %s", apiErr.Error.Type, apiErr.Error.Message)
+	if err := p.client.Do(ctx, http.MethodPost, "/responses", createReq, &result); err != nil {
+		return nil, err
 	}
 
 	return &result, nil
@@ -72,9 +62,10 @@ func (r *Response) GetToolCalls() []OutputItem {
 	return calls
 }
 
-// ConvertTool converts a gopherai.Tool to an OpenAI FunctionTool.
+// ConvertTool converts a gopherai.Tool to an OpenAI FunctionTool. Strict mode
+// is enabled when the parameters schema allows it.
 func (p *Provider) ConvertTool(tool gopherai.Tool) any {
-	strict := true
+	strict := isStrictSchema(tool.Parameters)
 	return FunctionTool{
 		Type:        "function",
 		Name:        tool.Name,
@@ -113,33 +104,35 @@ func (p *Provider) ExtractText(resp any) string {
 	return response.GetOutputText()
 }
 
+// ExtractUsage extracts token usage from a response.
+func (p *Provider) ExtractUsage(resp any) (gopherai.Usage, bool) {
+	response, ok := resp.(*Response)
+	if !ok || response.Usage == nil {
+		return gopherai.Usage{}, false
+	}
+	return toUsage(response.Model, response.Usage), true
+}
+
+func toUsage(model string, usage *Usage) gopherai.Usage {
+	return gopherai.Usage{
+		Model:             model,
+		InputTokens:       usage.InputTokens,
+		CachedInputTokens: usage.InputTokensDetails.CachedTokens,
+		OutputTokens:      usage.OutputTokens,
+		ReasoningTokens:   usage.OutputTokensDetails.ReasoningTokens,
+	}
+}
+
 // BuildRequest builds a CreateResponseRequest from the given parameters.
-func (p *Provider) BuildRequest(input any, systemPrompt string, tools []any) any {
+func (p *Provider) BuildRequest(messages []gopherai.Message, systemPrompt string, tools []any) any {
 	functionTools := make([]FunctionTool, len(tools))
 	for i, tool := range tools {
 		functionTools[i] = tool.(FunctionTool)
 	}
 
-	convertedInput := input
-	if inputItems, ok := input.([]any); ok {
-		convertedItems := make([]InputItem, 0, len(inputItems))
-		for _, item := range inputItems {
-			if inputItem, ok := item.(InputItem); ok {
-				convertedItems = append(convertedItems, inputItem)
-			} else if inputStr, ok := item.(string); ok {
-				convertedItems = append(convertedItems, InputItem{
-					Type:    "message",
-					Role:    "user",
-					Content: inputStr,
-				})
-			}
-		}
-		convertedInput = convertedItems
-	}
-
 	req := &CreateResponseRequest{
 		Model:           p.model,
-		Input:           convertedInput,
+		Input:           ConvertMessages(messages),
 		Instructions:    systemPrompt,
 		Tools:           functionTools,
 		Temperature:     p.temperature,
@@ -149,28 +142,168 @@ func (p *Provider) BuildRequest(input any, systemPrompt string, tools []any) any
 	return req
 }
 
-// CreateFunctionCallInput creates a function call input item from a ToolCall.
-func (p *Provider) CreateFunctionCallInput(call gopherai.ToolCall) any {
-	return InputItem{
-		Type:      "function_call",
-		CallID:    call.CallID,
-		Name:      call.Name,
-		Arguments: call.Arguments,
+// SetOutputSchema constrains the response text to a JSON schema using the
+// json_schema text format. Strict mode is enabled when the schema allows it,
+// that is when every object lists all its properties as required and forbids
+// additional properties.
+func (p *Provider) SetOutputSchema(req any, schema gopherai.OutputSchema) error {
+	createReq, ok := req.(*CreateResponseRequest)
+	if !ok {
+		return fmt.Errorf("invalid request type: expected *CreateResponseRequest")
+	}
+
+	strict := isStrictSchema(schema.Schema)
+	createReq.Text = &TextConfig{
+		Format: &TextFormat{
+			Type:   "json_schema",
+			Name:   schema.Name,
+			Schema: schema.Schema,
+			Strict: &strict,
+		},
+	}
+	return nil
+}
+
+// isStrictSchema reports whether a schema satisfies the requirements of strict
+// mode: objects list all their properties as required and forbid additional
+// properties.
+func isStrictSchema(schema map[string]any) bool {
+	if additional, ok := schema["additionalProperties"]; ok && additional != false {
+		return false
+	}
+	if properties, ok := schema["properties"].(map[string]any); ok {
+		if schema["additionalProperties"] != false {
+			return false
+		}
+		required := make(map[string]bool)
+		switch list := schema["required"].(type) {
+		case []string:
+			for _, name := range list {
+				required[name] = true
+			}
+		case []any:
+			for _, name := range list {
+				if s, ok := name.(string); ok {
+					required[s] = true
+				}
+			}
+		}
+		for name, prop := range properties {
+			if !required[name] {
+				return false
+			}
+			if propSchema, ok := prop.(map[string]any); ok && !isStrictSchema(propSchema) {
+				return false
+			}
+		}
+	}
+	if items, ok := schema["items"].(map[string]any); ok && !isStrictSchema(items) {
+		return false
+	}
+	if _, ok := schema["oneOf"]; ok {
+		return false
+	}
+	if variants, ok := schema["anyOf"].([]any); ok {
+		for _, variant := range variants {
+			if variantSchema, ok := variant.(map[string]any); ok && !isStrictSchema(variantSchema) {
+				return false
+			}
+		}
+	}
+	if defs, ok := schema["$defs"].(map[string]any); ok {
+		for _, def := range defs {
+			if defSchema, ok := def.(map[string]any); ok && !isStrictSchema(defSchema) {
+				return false
+			}
+		}
 	}
+	return true
 }
 
-// CreateFunctionCallOutput creates a function call output item.
-func (p *Provider) CreateFunctionCallOutput(callID, output string) any {
-	return NewFunctionCallOutput(callID, output)
+// ConvertMessages converts gopherai messages into Responses API input items.
+// Tool call IDs are normalized first, since the API rejects histories with
+// duplicate call IDs such as those produced by other providers.
+func ConvertMessages(messages []gopherai.Message) []InputItem {
+	items := make([]InputItem, 0, len(messages))
+	for _, msg := range gopherai.NormalizeToolCallIDs(messages) {
+		for _, part := range msg.Parts {
+			switch part.Type {
+			case gopherai.PartTypeText:
+				role := "user"
+				if msg.Role == gopherai.RoleAssistant {
+					role = "assistant"
+				}
+				items = append(items, InputItem{
+					Type:    "message",
+					Role:    role,
+					Content: part.Text,
+				})
+
+			case gopherai.PartTypeToolCall:
+				if part.ToolCall == nil {
+					continue
+				}
+				items = append(items, InputItem{
+					Type:      "function_call",
+					CallID:    part.ToolCall.CallID,
+					Name:      part.ToolCall.Name,
+					Arguments: part.ToolCall.Arguments,
+				})
+
+			case gopherai.PartTypeToolResult:
+				if part.ToolResult == nil {
+					continue
+				}
+				items = append(items, NewFunctionCallOutput(part.ToolResult.CallID, part.ToolResult.Output))
+			}
+		}
+	}
+	return items
 }
 
-// CreateAssistantMessage creates an assistant message input item.
-func (p *Provider) CreateAssistantMessage(text string) any {
-	return InputItem{
-		Type:    "message",
-		Role:    "assistant",
-		Content: text,
+// ToMessages converts Responses API input items into gopherai messages, so a
+// conversation stored in OpenAI's wire format can be continued on any provider.
+// Consecutive function calls are grouped into a single assistant message and
+// consecutive function call outputs into a single tool message.
+func ToMessages(items []InputItem) []gopherai.Message {
+	var messages []gopherai.Message
+	callNames := make(map[string]string)
+
+	appendPart := func(role gopherai.Role, part gopherai.Part, merge bool) {
+		if n := len(messages); merge && n > 0 && messages[n-1].Role == role {
+			messages[n-1].Parts = append(messages[n-1].Parts, part)
+			return
+		}
+		messages = append(messages, gopherai.Message{Role: role, Parts: []gopherai.Part{part}})
 	}
+
+	for _, item := range items {
+		switch item.Type {
+		case "message", "":
+			role := gopherai.RoleUser
+			if item.Role == "assistant" {
+				role = gopherai.RoleAssistant
+			}
+			appendPart(role, gopherai.TextPart(item.Content), false)
+
+		case "function_call":
+			callNames[item.CallID] = item.Name
+			appendPart(gopherai.RoleAssistant, gopherai.ToolCallPart(gopherai.ToolCall{
+				Name:      item.Name,
+				Arguments: item.Arguments,
+				CallID:    item.CallID,
+			}), true)
+
+		case "function_call_output":
+			appendPart(gopherai.RoleTool, gopherai.ToolResultPart(gopherai.ToolResult{
+				CallID: item.CallID,
+				Name:   callNames[item.CallID],
+				Output: item.Output,
+			}), true)
+		}
+	}
+
+	return messages
 }
 
 // CreateResponseStream sends a streaming request to the OpenAI Responses API.
@@ -183,27 +316,13 @@ func (p *Provider) CreateResponseStream(ctx context.Context, req any) (<-chan go
 	stream := true
 	createReq.Stream = &stream
 
-	events := make(chan gopherai.StreamEvent, 100)
-
-	resp, err := p.http.R().
-		SetContext(ctx).
-		SetBody(createReq).
-		SetDoNotParseResponse(true).
-		Post("/responses")
+	body, err := p.client.Stream(ctx, http.MethodPost, "/responses", createReq)
 	if err != nil {
-		close(events)
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-
-	if resp.IsError() {
-		body := resp.RawBody()
-		defer func() { _ = body.Close() }()
-		bodyBytes, _ := io.ReadAll(body)
-		close(events)
-		return nil, fmt.Errorf("API error: %s", string(bodyBytes))
+		return nil, err
 	}
 
-	go p.parseSSEStream(resp.RawBody(), events)
+	events := make(chan gopherai.StreamEvent, 100)
+	go p.parseSSEStream(body, events)
 
 	return events, nil
 }
@@ -219,6 +338,7 @@ func ParseSSEStreamForTest(r io.Reader) <-chan gopherai.StreamEvent {
 }
 
 func (p *Provider) parseSSEStream(body io.ReadCloser, events chan<- gopherai.StreamEvent) {
+	defer close(events)
 	defer func() { _ = body.Close() }()
 	parseSSEStreamReader(body, events)
 }
@@ -291,6 +411,13 @@ func parseSSEStreamReader(r io.Reader, events chan<- gopherai.StreamEvent) {
 			}
 
 		case "response.completed":
+			if response := eventData.Response; response != nil && response.Usage != nil {
+				usage := toUsage(response.Model, response.Usage)
+				events <- gopherai.StreamEvent{
+					Type:  gopherai.StreamEventTypeUsage,
+					Usage: &usage,
+				}
+			}
 			events <- gopherai.StreamEvent{
 				Type: gopherai.StreamEventTypeDone,
 			}
@@ -298,7 +425,7 @@ func parseSSEStreamReader(r io.Reader, events chan<- gopherai.StreamEvent) {
 		case "error":
 			events <- gopherai.StreamEvent{
 				Type:  gopherai.StreamEventTypeError,
-				Error: fmt.Errorf("%s: %s", eventData.Code, eventData.Message),
+				Error: newStreamError(eventData.Code, "", eventData.Message),
 			}
 		}
 	}
@@ -69,6 +69,20 @@ type CreateResponseRequest struct {
 	MaxOutputTokens   *int           `json:"max_output_tokens,omitempty"`
 	Store             *bool          `json:"store,omitempty"`
 	Stream            *bool          `json:"stream,omitempty"`
+	Text              *TextConfig    `json:"text,omitempty"`
+}
+
+// TextConfig configures the text output of a response.
+type TextConfig struct {
+	Format *TextFormat `json:"format,omitempty"`
+}
+
+// TextFormat specifies the format of the text output, such as a JSON schema.
+type TextFormat struct {
+	Type   string         `json:"type"`
+	Name   string         `json:"name,omitempty"`
+	Schema map[string]any `json:"schema,omitempty"`
+	Strict *bool          `json:"strict,omitempty"`
 }
 
 // Response represents the API response from a create response request.
@@ -0,0 +1,68 @@
+package gopherai
+
+import "regexp"
+
+// Pricer turns token usage into a cost in USD.
+type Pricer interface {
+	// Cost returns the cost of usage, and false if the model's price is not
+	// known.
+	Cost(usage Usage) (float64, bool)
+}
+
+// ModelPrice is the price of a model in USD per million tokens.
+type ModelPrice struct {
+	Input float64
+	// CachedInput is the price of prompt tokens read from the cache. Zero
+	// means they are billed as Input.
+	CachedInput float64
+	// Output is the price of response tokens, reasoning included.
+	Output float64
+}
+
+// PriceTable is a Pricer with a price per model name. A model missing from the
+// table is priced as the same name without a date suffix, such as
+// "-2025-04-14" or "-20250929", so that an entry for "gpt-4.1" also prices
+// dated snapshots such as "gpt-4.1-2025-04-14". Other variants, such as
+// "gpt-4.1-mini", need their own entries.
+type PriceTable map[string]ModelPrice
+
+// dateSuffix matches the date that identifies a model snapshot.
+var dateSuffix = regexp.MustCompile(`-(\d{4}-\d{2}-\d{2}|\d{8})$`)
+
+// Price returns the price of a model.
+func (t PriceTable) Price(model string) (ModelPrice, bool) {
+	if price, ok := t[model]; ok {
+		return price, true
+	}
+	if base := dateSuffix.ReplaceAllString(model, ""); base != model {
+		price, ok := t[base]
+		return price, ok
+	}
+	return ModelPrice{}, false
+}
+
+// Cost returns the cost of usage at the price of its model.
+func (t PriceTable) Cost(usage Usage) (float64, bool) {
+	price, ok := t.Price(usage.Model)
+	if !ok {
+		return 0, false
+	}
+
+	cachedPrice := price.CachedInput
+	if cachedPrice == 0 {
+		cachedPrice = price.Input
+	}
+	uncached := usage.InputTokens - usage.CachedInputTokens
+	cost := float64(uncached)*price.Input +
+		float64(usage.CachedInputTokens)*cachedPrice +
+		float64(usage.OutputTokens)*price.Output
+	return cost / 1_000_000, true
+}
+
+// WithPricing sets the Pricer used to report the cost of runs in RunResult.
+// Without one, runs report usage but no cost.
+func WithPricing(pricer Pricer) AgentOption {
+	return func(a *Agent) {
+		a.pricer = pricer
+	}
+}
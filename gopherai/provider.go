@@ -4,25 +4,28 @@ import "context"
 
 // ToolCall represents a function call request from the AI.
 type ToolCall struct {
-	Name      string
-	Arguments string
-	CallID    string
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+	CallID    string `json:"call_id"`
 }
 
-// FunctionCallOutput represents the result of a function call.
-type FunctionCallOutput struct {
-	CallID string
-	Output string
+// ToolResult represents the result of a tool call.
+type ToolResult struct {
+	CallID string `json:"call_id"`
+	Name   string `json:"name,omitempty"`
+	Output string `json:"output"`
+	// IsError marks Output as an error message rather than a successful result.
+	IsError bool `json:"is_error,omitempty"`
 }
 
 // Provider defines the interface for AI providers.
+// Providers translate the provider-agnostic Message history into their own
+// request types in BuildRequest, and expose the response content through
+// ExtractText and ExtractToolCalls.
 type Provider interface {
 	CreateResponse(ctx context.Context, req any) (any, error)
-	BuildRequest(input any, systemPrompt string, tools []any) any
+	BuildRequest(messages []Message, systemPrompt string, tools []any) any
 	ConvertTool(tool Tool) any
 	ExtractToolCalls(resp any) ([]ToolCall, error)
 	ExtractText(resp any) string
-	CreateFunctionCallInput(call ToolCall) any
-	CreateFunctionCallOutput(callID, output string) any
-	CreateAssistantMessage(text string) any
 }
@@ -0,0 +1,310 @@
+package gopherai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const rateLimitWindow = time.Minute
+
+// RateLimits configures a RateLimiter. A zero field means no limit.
+type RateLimits struct {
+	RequestsPerMinute int
+	TokensPerMinute   int
+	// MaxInFlight caps the requests running at once; a stream counts until
+	// it ends.
+	MaxInFlight int
+}
+
+// RateLimiter enforces RateLimits over a sliding one-minute window. It is safe
+// for concurrent use, and may be shared by several providers that draw on the
+// same quota.
+type RateLimiter struct {
+	limits RateLimits
+
+	mu       sync.Mutex
+	window   []*reservation
+	inFlight int
+	// changed is closed and replaced whenever capacity is freed.
+	changed chan struct{}
+}
+
+// reservation is the capacity taken by one request.
+type reservation struct {
+	limiter  *RateLimiter
+	at       time.Time
+	tokens   int
+	released bool
+}
+
+// NewRateLimiter creates a RateLimiter with the given limits.
+func NewRateLimiter(limits RateLimits) *RateLimiter {
+	return &RateLimiter{limits: limits, changed: make(chan struct{})}
+}
+
+// acquire blocks until a request of the estimated size fits within the
+// limits, or ctx is done. A request larger than the token limit is let
+// through once the window is empty.
+func (l *RateLimiter) acquire(ctx context.Context, tokens int) (*reservation, error) {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		wait, ok := l.reserveDelay(now, tokens)
+		if ok {
+			r := &reservation{limiter: l, at: now, tokens: tokens}
+			l.window = append(l.window, r)
+			l.inFlight++
+			l.mu.Unlock()
+			return r, nil
+		}
+		changed := l.changed
+		l.mu.Unlock()
+
+		var timer *time.Timer
+		var expired <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			expired = timer.C
+		}
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return nil, fmt.Errorf("waiting for rate limit: %w", ctx.Err())
+		case <-changed:
+		case <-expired:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// reserveDelay drops reservations that left the window, and reports whether
+// a request fits now. If it does not, it returns how long until the window
+// frees enough capacity, or zero if only a finishing request can free it.
+func (l *RateLimiter) reserveDelay(now time.Time, tokens int) (time.Duration, bool) {
+	start := 0
+	for start < len(l.window) && now.Sub(l.window[start].at) >= rateLimitWindow {
+		start++
+	}
+	l.window = l.window[start:]
+
+	var wait time.Duration
+	fits := true
+	expiry := func(i int) time.Duration {
+		return l.window[i].at.Add(rateLimitWindow).Sub(now)
+	}
+
+	if l.limits.MaxInFlight > 0 && l.inFlight >= l.limits.MaxInFlight {
+		fits = false
+	}
+	if rpm := l.limits.RequestsPerMinute; rpm > 0 && len(l.window) >= rpm {
+		fits = false
+		wait = max(wait, expiry(len(l.window)-rpm))
+	}
+	if tpm := l.limits.TokensPerMinute; tpm > 0 {
+		used := 0
+		for _, r := range l.window {
+			used += r.tokens
+		}
+		for i := 0; used > 0 && used+tokens > tpm; i++ {
+			fits = false
+			used -= l.window[i].tokens
+			wait = max(wait, expiry(i))
+		}
+	}
+	return wait, fits
+}
+
+// settle replaces the estimated tokens with the tokens actually used.
+func (r *reservation) settle(tokens int) {
+	l := r.limiter
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if tokens < r.tokens {
+		l.notify()
+	}
+	r.tokens = tokens
+}
+
+// release ends the request, freeing its in-flight slot. It is safe to call
+// more than once.
+func (r *reservation) release() {
+	l := r.limiter
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if r.released {
+		return
+	}
+	r.released = true
+	l.inFlight--
+	l.notify()
+}
+
+// notify wakes the waiting requests. The caller must hold mu.
+func (l *RateLimiter) notify() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// EstimateTokens roughly estimates the input tokens of a request as a quarter
+// of the size of its JSON encoding.
+func EstimateTokens(req any) int {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return 0
+	}
+	return len(data)/4 + 1
+}
+
+// RateLimitedProvider wraps a Provider so that its requests wait for capacity
+// in a RateLimiter. Requests are charged the estimated input tokens when they
+// start, and the tokens the response reports once it arrives, if the wrapped
+// provider implements UsageProvider or sends a usage stream event.
+//
+// It implements the optional provider interfaces whatever the wrapped
+// provider supports, with the wrapped provider's behavior where it has it.
+// Otherwise, streaming requests are sent as a single request whose response
+// is delivered as events, as FallbackProvider does; SetOutputSchema fails as
+// the agent does for providers without structured output; and ExtractUsage
+// and RespondedBy report nothing, as for providers without usage or routing.
+type RateLimitedProvider struct {
+	provider Provider
+	limiter  *RateLimiter
+	estimate func(req any) int
+}
+
+// NewRateLimitedProvider wraps provider with limiter.
+func NewRateLimitedProvider(provider Provider, limiter *RateLimiter) *RateLimitedProvider {
+	return &RateLimitedProvider{
+		provider: provider,
+		limiter:  limiter,
+		estimate: EstimateTokens,
+	}
+}
+
+// SetTokenEstimator replaces EstimateTokens as the estimate of the tokens a
+// request will use, charged before it is sent.
+func (p *RateLimitedProvider) SetTokenEstimator(estimate func(req any) int) *RateLimitedProvider {
+	p.estimate = estimate
+	return p
+}
+
+// CreateResponse waits for capacity and sends the request.
+func (p *RateLimitedProvider) CreateResponse(ctx context.Context, req any) (any, error) {
+	r, err := p.limiter.acquire(ctx, p.estimate(req))
+	if err != nil {
+		return nil, err
+	}
+	defer r.release()
+
+	resp, err := p.provider.CreateResponse(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if usage, ok := p.ExtractUsage(resp); ok {
+		r.settle(usage.TotalTokens())
+	}
+	return resp, nil
+}
+
+// CreateResponseStream waits for capacity and opens the stream. The request
+// stays in flight until the stream ends. If the wrapped provider does not
+// stream, its response is sent as events.
+func (p *RateLimitedProvider) CreateResponseStream(ctx context.Context, req any) (<-chan StreamEvent, error) {
+	streamProvider, ok := p.provider.(StreamProvider)
+	if !ok {
+		resp, err := p.CreateResponse(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return responseEvents(p.provider, resp)
+	}
+
+	r, err := p.limiter.acquire(ctx, p.estimate(req))
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := streamProvider.CreateResponseStream(ctx, req)
+	if err != nil {
+		r.release()
+		return nil, err
+	}
+
+	out := make(chan StreamEvent, 100)
+	go func() {
+		defer close(out)
+		defer r.release()
+		for event := range events {
+			switch event.Type {
+			case StreamEventTypeUsage:
+				if event.Usage != nil {
+					r.settle(event.Usage.TotalTokens())
+				}
+			case StreamEventTypeDone, StreamEventTypeError:
+				r.release()
+			}
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				go drain(events)
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// SetOutputSchema sets the schema on the wrapped provider's request.
+func (p *RateLimitedProvider) SetOutputSchema(req any, schema OutputSchema) error {
+	structuredProvider, ok := p.provider.(StructuredOutputProvider)
+	if !ok {
+		return fmt.Errorf("provider does not support structured output")
+	}
+	return structuredProvider.SetOutputSchema(req, schema)
+}
+
+// BuildRequest builds the wrapped provider's request.
+func (p *RateLimitedProvider) BuildRequest(messages []Message, systemPrompt string, tools []any) any {
+	return p.provider.BuildRequest(messages, systemPrompt, tools)
+}
+
+// ConvertTool converts a tool for the wrapped provider.
+func (p *RateLimitedProvider) ConvertTool(tool Tool) any {
+	return p.provider.ConvertTool(tool)
+}
+
+// ExtractToolCalls extracts tool calls using the wrapped provider.
+func (p *RateLimitedProvider) ExtractToolCalls(resp any) ([]ToolCall, error) {
+	return p.provider.ExtractToolCalls(resp)
+}
+
+// ExtractText extracts text using the wrapped provider.
+func (p *RateLimitedProvider) ExtractText(resp any) string {
+	return p.provider.ExtractText(resp)
+}
+
+// ExtractUsage extracts token usage using the wrapped provider, if it reports
+// usage.
+func (p *RateLimitedProvider) ExtractUsage(resp any) (Usage, bool) {
+	usageProvider, ok := p.provider.(UsageProvider)
+	if !ok {
+		return Usage{}, false
+	}
+	return usageProvider.ExtractUsage(resp)
+}
+
+// RespondedBy reports the provider that answered, if the wrapped provider is
+// a RoutedProvider.
+func (p *RateLimitedProvider) RespondedBy(resp any) string {
+	if routed, ok := p.provider.(RoutedProvider); ok {
+		return routed.RespondedBy(resp)
+	}
+	return ""
+}
@@ -0,0 +1,180 @@
+package gopherai
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownProvider is matched by errors.Is when a provider URI names a
+// scheme that no imported package has registered.
+var ErrUnknownProvider = errors.New("unknown provider")
+
+// Query parameters accepted by every provider URI.
+const (
+	paramTemperature = "temperature"
+	paramMaxTokens   = "max_tokens"
+	paramBaseURL     = "base_url"
+	paramAPIKeyEnv   = "api_key_env"
+)
+
+// ProviderConfig holds the settings parsed from a provider URI.
+type ProviderConfig struct {
+	// Model is the part of the URI after the scheme. It is empty when the URI
+	// names only the scheme, in which case the provider's default applies.
+	Model string
+	// APIKey is read from the environment variable named by the api_key_env
+	// parameter, or from the registration's EnvKeys.
+	APIKey      string
+	BaseURL     string
+	Temperature *float64
+	MaxTokens   *int
+	// Params holds the provider-specific query parameters.
+	Params url.Values
+}
+
+// ProviderRegistration describes how to build a provider from a URI.
+type ProviderRegistration struct {
+	// EnvKeys lists the environment variables checked, in order, for the API key.
+	EnvKeys []string
+	// KeyOptional allows the provider to be created without an API key, as
+	// for local servers.
+	KeyOptional bool
+	// Params lists the provider-specific query parameters the factory reads.
+	// Other parameters, apart from the common ones, are rejected.
+	Params []string
+	// New creates the provider.
+	New func(cfg ProviderConfig) (Provider, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]ProviderRegistration)
+)
+
+// RegisterProvider makes a provider available to NewProviderFromURI under the
+// given scheme. Provider packages register themselves when imported, so
+// importing a package for its side effect is enough:
+//
+//	import _ "github.com/marti-jorda-roca/gopher-ai/gopherai/openai"
+//
+// RegisterProvider panics if the scheme is registered twice or the
+// registration has no New function.
+func RegisterProvider(scheme string, reg ProviderRegistration) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if reg.New == nil {
+		panic("gopherai: RegisterProvider with nil New for " + scheme)
+	}
+	if _, ok := registry[scheme]; ok {
+		panic("gopherai: RegisterProvider called twice for " + scheme)
+	}
+	registry[scheme] = reg
+}
+
+// RegisteredProviders returns the sorted schemes of the registered providers.
+func RegisteredProviders() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	schemes := make([]string, 0, len(registry))
+	for scheme := range registry {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// NewProviderFromURI creates a provider from a URI of the form
+// "scheme:model?param=value", such as "openai:gpt-4.1" or
+// "gemini:gemini-2.0-flash?temperature=0.2". The model may itself contain
+// colons, as in "ollama:llama3.1:8b", and may be omitted to use the
+// provider's default.
+//
+// Every provider accepts the temperature, max_tokens and base_url parameters,
+// and api_key_env to name the environment variable holding the API key.
+// Providers document the other parameters they accept.
+func NewProviderFromURI(uri string) (Provider, error) {
+	rest, rawQuery, _ := strings.Cut(uri, "?")
+	scheme, model, _ := strings.Cut(rest, ":")
+	if scheme == "" {
+		return nil, fmt.Errorf("invalid provider URI %q: missing scheme", uri)
+	}
+
+	registryMu.RLock()
+	reg, ok := registry[scheme]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %q (registered: %s)", ErrUnknownProvider, scheme, strings.Join(RegisteredProviders(), ", "))
+	}
+
+	params, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("invalid provider URI %q: %w", uri, err)
+	}
+
+	cfg, err := parseProviderConfig(reg, model, params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid provider URI %q: %w", uri, err)
+	}
+
+	provider, err := reg.New(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("creating %s provider: %w", scheme, err)
+	}
+	return provider, nil
+}
+
+func parseProviderConfig(reg ProviderRegistration, model string, params url.Values) (ProviderConfig, error) {
+	cfg := ProviderConfig{Model: model, Params: url.Values{}}
+
+	for name, values := range params {
+		value := values[len(values)-1]
+		switch name {
+		case paramTemperature:
+			temperature, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return cfg, fmt.Errorf("invalid temperature %q", value)
+			}
+			cfg.Temperature = &temperature
+		case paramMaxTokens:
+			maxTokens, err := strconv.Atoi(value)
+			if err != nil {
+				return cfg, fmt.Errorf("invalid max_tokens %q", value)
+			}
+			cfg.MaxTokens = &maxTokens
+		case paramBaseURL:
+			cfg.BaseURL = value
+		case paramAPIKeyEnv:
+			// Read below, once all parameters are known.
+		default:
+			if !slices.Contains(reg.Params, name) {
+				return cfg, fmt.Errorf("unknown parameter %q", name)
+			}
+			cfg.Params[name] = values
+		}
+	}
+
+	envKeys := reg.EnvKeys
+	if name := params.Get(paramAPIKeyEnv); name != "" {
+		envKeys = []string{name}
+	}
+	for _, name := range envKeys {
+		if key := os.Getenv(name); key != "" {
+			cfg.APIKey = key
+			break
+		}
+	}
+	if cfg.APIKey == "" && !reg.KeyOptional && len(envKeys) > 0 {
+		return cfg, fmt.Errorf("missing API key: set %s", strings.Join(envKeys, " or "))
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,190 @@
+package gopherai
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how providers retry failed HTTP requests. Requests are
+// retried on network errors and on 408, 409, 429 and 5xx responses, except
+// those the provider classifies as an APIError that is not Retryable, such as
+// an exhausted quota. The delay
+// before a retry is the one the server asks for in the Retry-After,
+// retry-after-ms or x-ratelimit-reset-* headers, or else an exponential
+// backoff with jitter.
+//
+// Streaming requests are only retried while no event has been delivered:
+// when the connection fails or the server answers with an error status.
+//
+// The zero value makes a single attempt. Zero fields other than MaxAttempts
+// take their values from DefaultRetryPolicy.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the computed backoff. Delays asked for by the server
+	// are honored even when longer, unless they pass the context deadline.
+	MaxBackoff time.Duration
+	// Multiplier scales the backoff after each attempt.
+	Multiplier float64
+	// Jitter is the fraction of the backoff, between 0 and 1, that is
+	// randomized to spread out retries from concurrent clients.
+	Jitter float64
+	// Retryable, if set, replaces the default check of which failures are
+	// retried. resp is nil when the request failed without a response.
+	Retryable func(resp *http.Response, err error) bool
+}
+
+// DefaultRetryPolicy returns a policy of 4 attempts with a backoff starting
+// at 500ms, doubling up to 30s, with 20% jitter.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// Do calls send until it returns a response that should not be retried, or
+// the attempts run out. It returns the error of the last attempt; the caller
+// inspects the last response it received for its status. The bodies of
+// responses that are retried are closed.
+func (p RetryPolicy) Do(ctx context.Context, send func() (*http.Response, error)) error {
+	p = p.withDefaults()
+	backoff := p.InitialBackoff
+
+	for attempt := 1; ; attempt++ {
+		resp, err := send()
+		if attempt >= p.MaxAttempts || ctx.Err() != nil || !p.retryable(resp, err) {
+			return err
+		}
+
+		delay, ok := RetryDelay(resp, time.Now())
+		if !ok {
+			delay = p.jitter(backoff)
+			backoff = min(time.Duration(float64(backoff)*p.Multiplier), p.MaxBackoff)
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+
+		if resp != nil && resp.Body != nil {
+			_ = resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	defaults := DefaultRetryPolicy()
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = defaults.InitialBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = defaults.MaxBackoff
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = defaults.Multiplier
+	}
+	if p.Jitter <= 0 || p.Jitter > 1 {
+		p.Jitter = defaults.Jitter
+	}
+	return p
+}
+
+func (p RetryPolicy) retryable(resp *http.Response, err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(resp, err)
+	}
+	if err != nil {
+		var netErr net.Error
+		return errors.As(err, &netErr) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	return resp != nil && IsRetryableStatus(resp.StatusCode)
+}
+
+func (p RetryPolicy) jitter(backoff time.Duration) time.Duration {
+	spread := float64(backoff) * p.Jitter
+	return time.Duration(float64(backoff) - spread + rand.Float64()*2*spread) //nolint:gosec // jitter does not need a secure source
+}
+
+// IsRetryableStatus reports whether an HTTP status code indicates a failure
+// that may succeed when retried: request timeouts, conflicts, rate limits and
+// server errors.
+func IsRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout, http.StatusConflict, http.StatusTooManyRequests:
+		return true
+	default:
+		return code >= 500
+	}
+}
+
+// RetryDelay returns the delay a response asks the client to wait before
+// retrying. It reads retry-after-ms, then Retry-After (in seconds or as an
+// HTTP date), then the x-ratelimit-reset-* header of every rate limit whose
+// x-ratelimit-remaining-* header is 0, taking the longest.
+func RetryDelay(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	header := resp.Header
+
+	if ms, err := strconv.ParseFloat(header.Get("retry-after-ms"), 64); err == nil && ms >= 0 {
+		return time.Duration(ms * float64(time.Millisecond)), true
+	}
+	if value := header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+			return time.Duration(seconds * float64(time.Second)), true
+		}
+		if date, err := http.ParseTime(value); err == nil {
+			return max(date.Sub(now), 0), true
+		}
+	}
+
+	var delay time.Duration
+	found := false
+	for name := range header {
+		limit, ok := strings.CutPrefix(strings.ToLower(name), "x-ratelimit-reset-")
+		if !ok || header.Get("x-ratelimit-remaining-"+limit) != "0" {
+			continue
+		}
+		if reset, ok := parseReset(header.Get(name), now); ok {
+			delay = max(delay, reset)
+			found = true
+		}
+	}
+	return delay, found
+}
+
+// parseReset parses a rate limit reset value, which providers send as a
+// duration such as "1s" or "6m0s", a number of seconds, or a timestamp.
+func parseReset(value string, now time.Time) (time.Duration, bool) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return max(d, 0), true
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && !math.IsNaN(seconds) {
+		return max(time.Duration(seconds*float64(time.Second)), 0), true
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,438 @@
+package gopherai
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType         = reflect.TypeFor[time.Time]()
+	rawMessageType   = reflect.TypeFor[json.RawMessage]()
+	customSchemaType = reflect.TypeFor[CustomSchema]()
+)
+
+// CustomSchema is implemented by types that describe their own JSON schema,
+// such as tagged unions, custom ID types or types with a custom UnmarshalJSON.
+// Schema generation uses JSONSchema instead of reflecting on the type, at any
+// nesting depth. JSONSchema is called on a zero value and must not depend on
+// its receiver. Definitions under "$defs" are moved to the root schema.
+type CustomSchema interface {
+	JSONSchema() map[string]any
+}
+
+// OneOf returns a schema matching exactly one of the variants. Each variant is
+// either a schema map or a Go value whose type's schema is generated.
+// It is meant to be returned from a JSONSchema method to describe polymorphic
+// values; the variants should be distinguishable, for example by an "enum"
+// tagged kind field with a single value.
+func OneOf(variants ...any) map[string]any {
+	return unionSchema("oneOf", variants)
+}
+
+// AnyOf returns a schema matching at least one of the variants. Variants are
+// given as for OneOf.
+func AnyOf(variants ...any) map[string]any {
+	return unionSchema("anyOf", variants)
+}
+
+func unionSchema(keyword string, variants []any) map[string]any {
+	defs := make(map[string]any)
+	schemas := make([]any, len(variants))
+	for i, variant := range variants {
+		var schema map[string]any
+		if m, ok := variant.(map[string]any); ok {
+			schema = cloneSchema(m)
+		} else {
+			schema = schemaOf(reflect.TypeOf(variant))
+		}
+		hoistDefs(schema, defs)
+		schemas[i] = schema
+	}
+
+	schema := map[string]any{keyword: schemas}
+	if len(defs) > 0 {
+		schema["$defs"] = defs
+	}
+	return schema
+}
+
+// schemaGenerator builds the JSON schema of a Go type. Nested structs are
+// inlined, except recursive ones, which are defined once under $defs and
+// referenced with $ref. References to the root type point at the root schema.
+type schemaGenerator struct {
+	root      reflect.Type
+	names     map[reflect.Type]string
+	visiting  map[reflect.Type]bool
+	recursive map[reflect.Type]bool
+	defs      map[string]any
+}
+
+func newSchemaGenerator(root reflect.Type) *schemaGenerator {
+	g := &schemaGenerator{
+		root:      root,
+		names:     make(map[reflect.Type]string),
+		visiting:  make(map[reflect.Type]bool),
+		recursive: make(map[reflect.Type]bool),
+		defs:      make(map[string]any),
+	}
+	if root != nil {
+		g.visiting[root] = true
+	}
+	return g
+}
+
+// generateSchema returns the JSON schema of the struct type t.
+func generateSchema(t reflect.Type) map[string]any {
+	g := newSchemaGenerator(t)
+
+	schema, ok := g.customSchema(t)
+	if !ok {
+		schema = g.structSchema(t)
+	}
+	return g.withDefs(schema)
+}
+
+// schemaOf returns the JSON schema of any type, as a standalone schema whose
+// references all point into its own $defs.
+func schemaOf(t reflect.Type) map[string]any {
+	g := newSchemaGenerator(nil)
+	return g.withDefs(g.typeSchema(t))
+}
+
+func (g *schemaGenerator) withDefs(schema map[string]any) map[string]any {
+	if len(g.defs) > 0 {
+		schema["$defs"] = g.defs
+	}
+	return schema
+}
+
+// customSchema returns the schema of a type implementing CustomSchema.
+func (g *schemaGenerator) customSchema(t reflect.Type) (map[string]any, bool) {
+	t = indirectType(t)
+	if !reflect.PointerTo(t).Implements(customSchemaType) {
+		return nil, false
+	}
+
+	custom, _ := reflect.New(t).Interface().(CustomSchema)
+	schema := cloneSchema(custom.JSONSchema())
+	hoistDefs(schema, g.defs)
+	return schema, true
+}
+
+// typeSchema returns the schema of any supported Go type.
+func (g *schemaGenerator) typeSchema(t reflect.Type) map[string]any {
+	if schema, ok := g.customSchema(t); ok {
+		return schema
+	}
+	t = indirectType(t)
+
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case rawMessageType:
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Struct:
+		return g.nestedStructSchema(t)
+	case reflect.Map:
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": g.typeSchema(t.Elem()),
+		}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json encodes byte slices as base64 strings.
+			return map[string]any{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]any{"type": "array", "items": g.typeSchema(t.Elem())}
+	case reflect.Array:
+		return map[string]any{
+			"type":     "array",
+			"items":    g.typeSchema(t.Elem()),
+			"minItems": t.Len(),
+			"maxItems": t.Len(),
+		}
+	case reflect.Interface:
+		return map[string]any{}
+	default:
+		return map[string]any{"type": "string"}
+	}
+}
+
+// nestedStructSchema inlines the schema of a struct, or returns a $ref when
+// the struct is recursive.
+func (g *schemaGenerator) nestedStructSchema(t reflect.Type) map[string]any {
+	if t == g.root {
+		return map[string]any{"$ref": "#"}
+	}
+
+	if g.visiting[t] || g.recursive[t] {
+		g.recursive[t] = true
+		return g.ref(t)
+	}
+
+	g.visiting[t] = true
+	schema := g.structSchema(t)
+	delete(g.visiting, t)
+
+	if g.recursive[t] {
+		g.defs[g.defName(t)] = schema
+		return g.ref(t)
+	}
+	return schema
+}
+
+func (g *schemaGenerator) ref(t reflect.Type) map[string]any {
+	return map[string]any{"$ref": "#/$defs/" + g.defName(t)}
+}
+
+// defName returns the unique $defs name of a struct type.
+func (g *schemaGenerator) defName(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+
+	base := t.Name()
+	if base == "" {
+		base = "Object"
+	}
+	name := base
+	for i := 2; g.nameTaken(name); i++ {
+		name = base + strconv.Itoa(i)
+	}
+	g.names[t] = name
+	return name
+}
+
+func (g *schemaGenerator) nameTaken(name string) bool {
+	if _, ok := g.defs[name]; ok {
+		return true
+	}
+	for _, taken := range g.names {
+		if taken == name {
+			return true
+		}
+	}
+	return false
+}
+
+// structSchema returns the object schema of a struct. Fields without a json
+// tag are skipped, and pointer fields are optional.
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	var required []string
+	g.addFields(t, properties, &required, false)
+
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	schema["additionalProperties"] = false
+
+	return schema
+}
+
+// addFields adds the fields of t to properties. Fields of embedded structs are
+// promoted as encoding/json does, unless a shallower field has the same name.
+// They are optional when the struct is embedded by pointer.
+func (g *schemaGenerator) addFields(t reflect.Type, properties map[string]any, required *[]string, optional bool) {
+	var embedded []reflect.StructField
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		jsonTag := field.Tag.Get("json")
+		if jsonTag == "-" {
+			continue
+		}
+		jsonName := strings.Split(jsonTag, ",")[0]
+
+		if field.Anonymous && jsonName == "" && indirectType(field.Type).Kind() == reflect.Struct {
+			embedded = append(embedded, field)
+			continue
+		}
+		if jsonTag == "" || !field.IsExported() {
+			continue
+		}
+		if jsonName == "" {
+			jsonName = field.Name
+		}
+		if _, exists := properties[jsonName]; exists {
+			continue
+		}
+
+		properties[jsonName] = g.fieldSchema(field)
+
+		if !optional && field.Type.Kind() != reflect.Pointer {
+			*required = append(*required, jsonName)
+		}
+	}
+
+	for _, field := range embedded {
+		embeddedType := indirectType(field.Type)
+		if g.visiting[embeddedType] {
+			continue
+		}
+		g.visiting[embeddedType] = true
+		g.addFields(embeddedType, properties, required, optional || field.Type.Kind() == reflect.Pointer)
+		delete(g.visiting, embeddedType)
+
+		// A field referring back to the embedded struct needs its definition.
+		if name := g.names[embeddedType]; g.recursive[embeddedType] && g.defs[name] == nil {
+			g.visiting[embeddedType] = true
+			g.defs[name] = g.structSchema(embeddedType)
+			delete(g.visiting, embeddedType)
+		}
+	}
+}
+
+// fieldSchema returns the schema of a struct field, including its tags.
+func (g *schemaGenerator) fieldSchema(field reflect.StructField) map[string]any {
+	prop := g.typeSchema(field.Type)
+
+	if desc := field.Tag.Get("description"); desc != "" {
+		prop["description"] = desc
+	}
+
+	if enum := field.Tag.Get("enum"); enum != "" {
+		enumValues := strings.Split(enum, ",")
+		for i := range enumValues {
+			enumValues[i] = strings.TrimSpace(enumValues[i])
+		}
+		prop["enum"] = enumValues
+
+		if prop["type"] != "string" {
+			values := make([]any, len(enumValues))
+			for i, value := range enumValues {
+				values[i] = tagValue(value, prop)
+			}
+			prop["enum"] = values
+		}
+	}
+
+	for _, keyword := range []string{"minimum", "maximum"} {
+		if tag := field.Tag.Get(keyword); tag != "" {
+			n, err := strconv.ParseFloat(tag, 64)
+			if err != nil {
+				panic(fmt.Sprintf("invalid %s tag %q on field %s: %v", keyword, tag, field.Name, err))
+			}
+			prop[keyword] = n
+		}
+	}
+
+	for _, keyword := range []string{"minLength", "maxLength", "minItems", "maxItems"} {
+		if tag := field.Tag.Get(keyword); tag != "" {
+			n, err := strconv.Atoi(tag)
+			if err != nil || n < 0 {
+				panic(fmt.Sprintf("invalid %s tag %q on field %s: must be a non-negative integer", keyword, tag, field.Name))
+			}
+			prop[keyword] = n
+		}
+	}
+
+	if pattern := field.Tag.Get("pattern"); pattern != "" {
+		if _, err := regexp.Compile(pattern); err != nil {
+			panic(fmt.Sprintf("invalid pattern tag %q on field %s: %v", pattern, field.Name, err))
+		}
+		prop["pattern"] = pattern
+	}
+
+	if format := field.Tag.Get("format"); format != "" {
+		prop["format"] = format
+	}
+
+	if def, ok := field.Tag.Lookup("default"); ok {
+		prop["default"] = tagValue(def, prop)
+	}
+
+	if example, ok := field.Tag.Lookup("example"); ok {
+		prop["examples"] = []any{tagValue(example, prop)}
+	}
+
+	return prop
+}
+
+// tagValue parses a default or example tag as a JSON value, unless the field
+// is a string, in which case the tag is used as is.
+func tagValue(tag string, prop map[string]any) any {
+	if prop["type"] == "string" {
+		return tag
+	}
+	var value any
+	if err := json.Unmarshal([]byte(tag), &value); err != nil {
+		return tag
+	}
+	return value
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// hoistDefs moves the $defs of schema into defs, keeping existing definitions.
+func hoistDefs(schema map[string]any, defs map[string]any) {
+	schemaDefs, ok := schema["$defs"].(map[string]any)
+	if !ok {
+		return
+	}
+	delete(schema, "$defs")
+	for name, def := range schemaDefs {
+		if _, exists := defs[name]; !exists {
+			defs[name] = def
+		}
+	}
+}
+
+// cloneSchema deep-copies the maps and slices of a schema.
+func cloneSchema(schema map[string]any) map[string]any {
+	if schema == nil {
+		return map[string]any{}
+	}
+	clone := make(map[string]any, len(schema))
+	for key, value := range schema {
+		clone[key] = cloneSchemaValue(value)
+	}
+	return clone
+}
+
+func cloneSchemaValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		return cloneSchema(v)
+	case []map[string]any:
+		clone := make([]any, len(v))
+		for i, item := range v {
+			clone[i] = cloneSchema(item)
+		}
+		return clone
+	case []any:
+		clone := make([]any, len(v))
+		for i, item := range v {
+			clone[i] = cloneSchemaValue(item)
+		}
+		return clone
+	default:
+		return value
+	}
+}
@@ -0,0 +1,118 @@
+package gopherai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ConversationVersion is the current version of the conversation JSON format.
+const ConversationVersion = 1
+
+// ErrSessionNotFound is returned by a SessionStore when a session does not exist.
+var ErrSessionNotFound = errors.New("session not found")
+
+// Conversation is the versioned JSON representation of a conversation.
+type Conversation struct {
+	Version   int            `json:"version"`
+	ID        string         `json:"id,omitempty"`
+	Messages  []Message      `json:"messages"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// NewConversation creates a conversation in the current format version.
+func NewConversation(id string, messages []Message) *Conversation {
+	now := time.Now().UTC()
+	return &Conversation{
+		Version:   ConversationVersion,
+		ID:        id,
+		Messages:  messages,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// MarshalConversation encodes a conversation as JSON.
+func MarshalConversation(conv *Conversation) ([]byte, error) {
+	if conv.Version == 0 {
+		conv.Version = ConversationVersion
+	}
+	return json.Marshal(conv)
+}
+
+// UnmarshalConversation decodes a conversation from JSON, rejecting versions
+// this package does not understand.
+func UnmarshalConversation(data []byte) (*Conversation, error) {
+	var conv Conversation
+	if err := json.Unmarshal(data, &conv); err != nil {
+		return nil, fmt.Errorf("failed to decode conversation: %w", err)
+	}
+	if conv.Version < 1 || conv.Version > ConversationVersion {
+		return nil, fmt.Errorf("unsupported conversation version: %d", conv.Version)
+	}
+	return &conv, nil
+}
+
+// SessionStore persists conversations by session ID.
+type SessionStore interface {
+	// Load returns the messages of a session, or ErrSessionNotFound.
+	Load(ctx context.Context, sessionID string) ([]Message, error)
+	// Append adds messages to a session, creating it if needed.
+	Append(ctx context.Context, sessionID string, messages ...Message) error
+	// Delete removes a session. Deleting a missing session is not an error.
+	Delete(ctx context.Context, sessionID string) error
+}
+
+// MemorySessionStore is a SessionStore that keeps conversations in memory.
+type MemorySessionStore struct {
+	mu       sync.RWMutex
+	sessions map[string]*Conversation
+}
+
+// NewMemorySessionStore creates an empty in-memory session store.
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{
+		sessions: make(map[string]*Conversation),
+	}
+}
+
+// Load returns the messages of a session.
+func (s *MemorySessionStore) Load(_ context.Context, sessionID string) ([]Message, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	conv, ok := s.sessions[sessionID]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	return CloneMessages(conv.Messages), nil
+}
+
+// Append adds messages to a session.
+func (s *MemorySessionStore) Append(_ context.Context, sessionID string, messages ...Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conv, ok := s.sessions[sessionID]
+	if !ok {
+		conv = NewConversation(sessionID, nil)
+		s.sessions[sessionID] = conv
+	}
+	conv.Messages = append(conv.Messages, CloneMessages(messages)...)
+	conv.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// Delete removes a session.
+func (s *MemorySessionStore) Delete(_ context.Context, sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, sessionID)
+	return nil
+}
@@ -0,0 +1,143 @@
+package gopherai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// FileSessionStore is a SessionStore that keeps each conversation in a JSON
+// file named after its session ID. Session IDs may only contain letters,
+// digits, '.', '_' and '-', and must not start with '.'.
+type FileSessionStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileSessionStore creates a file-system session store rooted at dir,
+// creating the directory if it does not exist.
+func NewFileSessionStore(dir string) (*FileSessionStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create session directory: %w", err)
+	}
+	return &FileSessionStore{dir: dir}, nil
+}
+
+// Load returns the messages of a session.
+func (s *FileSessionStore) Load(_ context.Context, sessionID string) ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conv, err := s.read(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return conv.Messages, nil
+}
+
+// Append adds messages to a session.
+func (s *FileSessionStore) Append(_ context.Context, sessionID string, messages ...Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conv, err := s.read(sessionID)
+	if errors.Is(err, ErrSessionNotFound) {
+		conv = NewConversation(sessionID, nil)
+	} else if err != nil {
+		return err
+	}
+
+	conv.Messages = append(conv.Messages, messages...)
+	conv.UpdatedAt = time.Now().UTC()
+	return s.write(conv)
+}
+
+// Delete removes a session.
+func (s *FileSessionStore) Delete(_ context.Context, sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path, err := s.path(sessionID)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+func (s *FileSessionStore) path(sessionID string) (string, error) {
+	return jsonFilePath(s.dir, "session", sessionID)
+}
+
+func (s *FileSessionStore) read(sessionID string) (*Conversation, error) {
+	path, err := s.path(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path) // #nosec G304 -- path is built from a validated session ID
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+	return UnmarshalConversation(data)
+}
+
+// write replaces the session file atomically by writing to a temporary file first.
+func (s *FileSessionStore) write(conv *Conversation) error {
+	path, err := s.path(conv.ID)
+	if err != nil {
+		return err
+	}
+
+	data, err := MarshalConversation(conv)
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+
+	if err := writeFileAtomic(s.dir, conv.ID, path, data); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	return nil
+}
+
+// validFileID matches IDs that are safe to use as file names: letters,
+// digits, '_', '-' and '.', not starting with '.'.
+var validFileID = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]*$`)
+
+// jsonFilePath returns the path of the JSON file of an ID in dir, rejecting
+// IDs that are not safe file names. kind names the ID in errors.
+func jsonFilePath(dir, kind, id string) (string, error) {
+	if !validFileID.MatchString(id) {
+		return "", fmt.Errorf("invalid %s ID: %q", kind, id)
+	}
+	return filepath.Join(dir, id+".json"), nil
+}
+
+// writeFileAtomic replaces the file at path by writing data to a temporary
+// file in dir, named after name, and renaming it.
+func writeFileAtomic(dir, name, path string, data []byte) error {
+	tmp, err := os.CreateTemp(dir, name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,119 @@
+package gopherai
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const sqlSessionSchema = `CREATE TABLE IF NOT EXISTS gopherai_session_messages (
+	session_id TEXT NOT NULL,
+	seq INTEGER NOT NULL,
+	version INTEGER NOT NULL,
+	message TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (session_id, seq)
+)`
+
+// SQLSessionStore is a SessionStore backed by a database/sql database using
+// the SQLite dialect. It works with embedded pure Go drivers such as
+// modernc.org/sqlite, which the caller opens and registers:
+//
+//	db, err := sql.Open("sqlite", "sessions.db")
+//	store, err := gopherai.NewSQLSessionStore(ctx, db)
+//
+// Each message is stored as a row holding its versioned JSON encoding.
+type SQLSessionStore struct {
+	db *sql.DB
+}
+
+// NewSQLSessionStore creates a SQL session store, creating its table if needed.
+func NewSQLSessionStore(ctx context.Context, db *sql.DB) (*SQLSessionStore, error) {
+	if _, err := db.ExecContext(ctx, sqlSessionSchema); err != nil {
+		return nil, fmt.Errorf("failed to create session table: %w", err)
+	}
+	return &SQLSessionStore{db: db}, nil
+}
+
+// Load returns the messages of a session, or ErrSessionNotFound when it has no
+// rows, as a session has none until messages are appended to it.
+func (s *SQLSessionStore) Load(ctx context.Context, sessionID string) ([]Message, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT version, message FROM gopherai_session_messages WHERE session_id = ? ORDER BY seq`,
+		sessionID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var messages []Message
+	for rows.Next() {
+		var version int
+		var data string
+		if err := rows.Scan(&version, &data); err != nil {
+			return nil, fmt.Errorf("failed to load session: %w", err)
+		}
+		if version < 1 || version > ConversationVersion {
+			return nil, fmt.Errorf("unsupported conversation version: %d", version)
+		}
+		var msg Message
+		if err := json.Unmarshal([]byte(data), &msg); err != nil {
+			return nil, fmt.Errorf("failed to decode message: %w", err)
+		}
+		messages = append(messages, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to load session: %w", err)
+	}
+
+	if len(messages) == 0 {
+		return nil, ErrSessionNotFound
+	}
+	return messages, nil
+}
+
+// Append adds messages to a session. Each message takes the next sequence
+// number of the session in the statement that inserts it, so that concurrent
+// appends to a session do not pick the same one: SQLite runs them one after
+// the other, without interleaving their messages.
+func (s *SQLSessionStore) Append(ctx context.Context, sessionID string, messages ...Message) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to append to session: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now().UTC()
+	for _, msg := range messages {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return fmt.Errorf("failed to encode message: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO gopherai_session_messages (session_id, seq, version, message, created_at)
+			SELECT ?, COALESCE(MAX(seq), 0) + 1, ?, ?, ? FROM gopherai_session_messages WHERE session_id = ?`,
+			sessionID, ConversationVersion, string(data), now, sessionID,
+		); err != nil {
+			return fmt.Errorf("failed to append to session: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to append to session: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a session.
+func (s *SQLSessionStore) Delete(ctx context.Context, sessionID string) error {
+	if _, err := s.db.ExecContext(ctx,
+		`DELETE FROM gopherai_session_messages WHERE session_id = ?`,
+		sessionID,
+	); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
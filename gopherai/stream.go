@@ -10,8 +10,12 @@ const (
 	StreamEventTypeTextDelta StreamEventType = "text_delta"
 	StreamEventTypeTextDone  StreamEventType = "text_done"
 	StreamEventTypeToolCall  StreamEventType = "tool_call"
+	StreamEventTypeUsage     StreamEventType = "usage"
 	StreamEventTypeError     StreamEventType = "error"
-	StreamEventTypeDone      StreamEventType = "done"
+	// StreamEventTypeInterrupted ends the stream of a run interrupted for tool
+	// call approval, in place of done.
+	StreamEventTypeInterrupted StreamEventType = "interrupted"
+	StreamEventTypeDone        StreamEventType = "done"
 )
 
 // StreamEvent represents an event emitted during streaming.
@@ -20,7 +24,18 @@ type StreamEvent struct {
 	Delta    string
 	Text     string
 	ToolCall *ToolCall
+	// Usage is set on usage events, sent before done by providers that
+	// report the usage of each model call.
+	Usage *Usage
+	// Provider is set on done events by providers that route requests to
+	// several, as FallbackProvider does, to name the one that answered. The
+	// done event of an agent's stream names the provider of its last model
+	// call, as RunResult.Provider does.
+	Provider string
 	Error    error
+	// State is set on interrupted events, to continue the run with
+	// Agent.ResumeStream.
+	State *RunState
 }
 
 // StreamProvider extends Provider with streaming capabilities.
@@ -0,0 +1,199 @@
+package gopherai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const defaultOutputRetries = 2
+
+// ErrInvalidOutput is matched by errors.Is when a structured output run
+// does not produce a valid response within its retries.
+var ErrInvalidOutput = errors.New("invalid structured output")
+
+// OutputSchema describes the JSON schema the model's final answer must conform to.
+type OutputSchema struct {
+	Name   string
+	Schema map[string]any
+}
+
+// StructuredOutputProvider extends Provider with JSON-schema constrained responses.
+type StructuredOutputProvider interface {
+	Provider
+	SetOutputSchema(req any, schema OutputSchema) error
+}
+
+// WithOutputSchema constrains the agent's final answers to the given JSON schema.
+// The provider must implement StructuredOutputProvider.
+func WithOutputSchema(schema OutputSchema) AgentOption {
+	return func(a *Agent) {
+		a.outputSchema = &schema
+	}
+}
+
+// WithOutputType constrains the agent's final answers to the JSON schema
+// generated from the struct type T, as NewTool does for tool parameters.
+func WithOutputType[T any]() AgentOption {
+	schema := outputSchemaFor[T]()
+	return WithOutputSchema(schema)
+}
+
+// WithOutputRetries sets how many times RunTyped re-prompts the model when its
+// answer cannot be decoded or does not match the schema. The default is 2.
+func WithOutputRetries(retries int) AgentOption {
+	return func(a *Agent) {
+		a.outputRetries = retries
+	}
+}
+
+// TypedRunResult is the result of RunTyped, holding the decoded answer.
+type TypedRunResult[T any] struct {
+	*RunResult
+	Output T
+}
+
+// RunTyped runs the agent with its answers constrained to the JSON schema of T
+// and decodes the final answer into T. When the answer is not valid, the error
+// is sent back to the model and it is asked to answer again, up to the agent's
+// output retries. The retries continue the same run, so they count towards its
+// limits and budget, and its usage, and its hooks and checkpoint span all of
+// them. A run interrupted for tool call approval is returned with a zero Output,
+// to be continued with ResumeTyped.
+func RunTyped[T any](ctx context.Context, agent *Agent, prompt string, history ...[]Message) (*TypedRunResult[T], error) {
+	typed, schema := typedAgent[T](agent)
+	state := typed.newRunState(ctx, typed.startConversation(prompt, history))
+	return runTyped[T](state, schema, func() (*RunResult, error) {
+		return typed.runLoop(ctx, state, typed.callModel)
+	})
+}
+
+// ResumeTyped is the counterpart of Agent.Resume for runs started by RunTyped:
+// it continues the run with its answers constrained to the JSON schema of T and
+// decodes the final answer into T.
+func ResumeTyped[T any](ctx context.Context, agent *Agent, state *RunState, decisions []ToolDecision) (*TypedRunResult[T], error) {
+	typed, schema := typedAgent[T](agent)
+	run, err := typed.resumeRunState(ctx, state, decisions)
+	if err != nil {
+		return nil, err
+	}
+	return runTyped[T](run, schema, func() (*RunResult, error) {
+		return typed.continueRun(ctx, run, state)
+	})
+}
+
+// ResumeRunTyped is the counterpart of Agent.ResumeRun for runs started by
+// RunTyped: it continues the run from its latest checkpoint with its answers
+// constrained to the JSON schema of T and decodes the final answer into T.
+func ResumeRunTyped[T any](ctx context.Context, agent *Agent, runID string) (*TypedRunResult[T], error) {
+	typed, schema := typedAgent[T](agent)
+	run, state, err := typed.loadCheckpoint(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	return runTyped[T](run, schema, func() (*RunResult, error) {
+		return typed.continueCheckpoint(ctx, run, state)
+	})
+}
+
+// typedAgent returns a copy of agent whose answers are constrained to the JSON
+// schema of T.
+func typedAgent[T any](agent *Agent) (*Agent, OutputSchema) {
+	typed := *agent
+	schema := outputSchemaFor[T]()
+	typed.outputSchema = &schema
+	return &typed, schema
+}
+
+// runTyped runs a typed run with run, checking its final answers against the
+// schema and decoding the valid one into T.
+func runTyped[T any](state *runState, schema OutputSchema, run func() (*RunResult, error)) (*TypedRunResult[T], error) {
+	var output T
+	state.checkOutput = func(text string) error {
+		var err error
+		output, err = decodeOutput[T](text, schema.Schema)
+		return err
+	}
+
+	result, err := run()
+	if err != nil {
+		return nil, err
+	}
+	if result.Interrupted {
+		return &TypedRunResult[T]{RunResult: result}, nil
+	}
+	return &TypedRunResult[T]{RunResult: result, Output: output}, nil
+}
+
+// retryOutput checks a final answer with the run's checkOutput. When the answer
+// is not valid, it appends a prompt asking the model to answer again and
+// returns true, until the agent's output retries are used up.
+func (s *runState) retryOutput(text string) (bool, error) {
+	if s.checkOutput == nil {
+		return false, nil
+	}
+	err := s.checkOutput(text)
+	if err == nil {
+		return false, nil
+	}
+	if s.outputAttempts >= s.agent.outputRetries {
+		return false, fmt.Errorf("%w after %d attempts: %w", ErrInvalidOutput, s.outputAttempts+1, err)
+	}
+
+	s.outputAttempts++
+	retryPrompt := fmt.Sprintf(
+		"Your previous answer was not valid: %v. Answer again with only a JSON value that matches the required schema.",
+		err,
+	)
+	s.messages = append(s.messages, NewUserMessage(retryPrompt))
+	return true, nil
+}
+
+func outputSchemaFor[T any]() OutputSchema {
+	var zero T
+	t := reflect.TypeOf(zero)
+	if t == nil || t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("RunTyped: type parameter T must be a struct, got %v", t))
+	}
+
+	return OutputSchema{
+		Name:   outputSchemaName(t),
+		Schema: generateSchema(t),
+	}
+}
+
+// outputSchemaName derives a schema name from a Go type name, keeping only the
+// characters accepted by providers.
+func outputSchemaName(t reflect.Type) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' {
+			return r
+		}
+		return '_'
+	}, t.Name())
+	if name == "" {
+		return "output"
+	}
+	return name
+}
+
+// decodeOutput decodes text into T after checking it against the schema.
+func decodeOutput[T any](text string, schema map[string]any) (T, error) {
+	var output T
+
+	text = strings.TrimSpace(text)
+	text = strings.TrimPrefix(text, "```json")
+	text = strings.TrimPrefix(text, "```")
+	text = strings.TrimSuffix(text, "```")
+
+	if _, err := validateJSON(schema, []byte(text)); err != nil {
+		return output, err
+	}
+	if err := json.Unmarshal([]byte(text), &output); err != nil {
+		return output, fmt.Errorf("response does not match the schema: %w", err)
+	}
+	return output, nil
+}
@@ -1,9 +1,92 @@
 package gopherai
 
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrToolTimeout is matched by errors.Is when a tool call exceeds its timeout.
+var ErrToolTimeout = errors.New("tool call timed out")
+
 // Tool represents a function that can be called by the AI.
 type Tool struct {
 	Name        string
 	Description string
 	Parameters  map[string]any
 	Handler     func(args string) (string, error)
+	// ContextHandler, when set, is called instead of Handler with the run
+	// context, which is cancelled with the run or when Timeout expires.
+	ContextHandler func(ctx context.Context, args string) (string, error)
+	// Timeout limits how long a single call may take. A call that times out
+	// is reported to the model. Zero means no timeout.
+	Timeout time.Duration
+	// ErrorPolicy overrides the agent's tool error policy for this tool.
+	ErrorPolicy *ToolErrorPolicy
+	// RequiresApproval stops the run before the tool is called, until a human
+	// approves the call with Agent.Resume.
+	RequiresApproval bool
+}
+
+// WithTimeout returns a copy of the tool whose calls are cancelled after timeout.
+func (t Tool) WithTimeout(timeout time.Duration) Tool {
+	t.Timeout = timeout
+	return t
+}
+
+// WithErrorPolicy returns a copy of the tool that handles its errors with the given policy.
+func (t Tool) WithErrorPolicy(policy ToolErrorPolicy) Tool {
+	t.ErrorPolicy = &policy
+	return t
+}
+
+// WithApproval returns a copy of the tool whose calls must be approved before
+// they run.
+func (t Tool) WithApproval() Tool {
+	t.RequiresApproval = true
+	return t
+}
+
+// call runs the tool's handler, preferring the context-aware one.
+func (t Tool) call(ctx context.Context, args string) (string, error) {
+	if t.ContextHandler != nil {
+		return t.ContextHandler(ctx, args)
+	}
+	return t.Handler(args)
+}
+
+// ToolErrorAction selects what the agent does with a failed tool call.
+type ToolErrorAction string
+
+// Tool error action constants.
+const (
+	// ToolErrorAbort fails the whole run with the tool's error.
+	ToolErrorAbort ToolErrorAction = "abort"
+	// ToolErrorReport sends the error message back to the model as the tool
+	// output, so it can fix its arguments or choose another tool.
+	ToolErrorReport ToolErrorAction = "report"
+)
+
+// ToolErrorPolicy decides how failed tool calls are handled.
+// A failing handler is called again up to Retries times before Action applies.
+type ToolErrorPolicy struct {
+	Action  ToolErrorAction
+	Retries int
+}
+
+// AbortOnToolError returns a policy that fails the run on the first tool error.
+// It is the default policy.
+func AbortOnToolError() ToolErrorPolicy {
+	return ToolErrorPolicy{Action: ToolErrorAbort}
+}
+
+// ReportToolErrors returns a policy that reports tool errors to the model.
+func ReportToolErrors() ToolErrorPolicy {
+	return ToolErrorPolicy{Action: ToolErrorReport}
+}
+
+// RetryToolErrors returns a policy that retries a failing tool up to retries
+// times and then applies the given action.
+func RetryToolErrors(retries int, then ToolErrorAction) ToolErrorPolicy {
+	return ToolErrorPolicy{Action: then, Retries: retries}
 }
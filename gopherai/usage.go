@@ -0,0 +1,54 @@
+package gopherai
+
+// Usage reports the tokens consumed by a model call.
+type Usage struct {
+	// Model names the model that reported the usage, if known. It is empty in
+	// a sum over calls to different models.
+	Model string `json:"model,omitempty"`
+	// InputTokens counts the whole prompt, including CachedInputTokens.
+	InputTokens int `json:"input_tokens"`
+	// CachedInputTokens counts the prompt tokens read from the provider's
+	// prompt cache, which are usually billed at a discount.
+	CachedInputTokens int `json:"cached_input_tokens,omitempty"`
+	// OutputTokens counts the whole response, including ReasoningTokens.
+	OutputTokens int `json:"output_tokens"`
+	// ReasoningTokens counts the tokens the model spent thinking.
+	ReasoningTokens int `json:"reasoning_tokens,omitempty"`
+}
+
+// TotalTokens returns the input and output tokens together.
+func (u Usage) TotalTokens() int {
+	return u.InputTokens + u.OutputTokens
+}
+
+// Add returns the sum of u and other.
+func (u Usage) Add(other Usage) Usage {
+	model := u.Model
+	if u.TotalTokens() == 0 && u.Model == "" {
+		model = other.Model
+	} else if other.Model != u.Model {
+		model = ""
+	}
+	return Usage{
+		Model:             model,
+		InputTokens:       u.InputTokens + other.InputTokens,
+		CachedInputTokens: u.CachedInputTokens + other.CachedInputTokens,
+		OutputTokens:      u.OutputTokens + other.OutputTokens,
+		ReasoningTokens:   u.ReasoningTokens + other.ReasoningTokens,
+	}
+}
+
+// UsageProvider extends Provider with the token usage of responses.
+type UsageProvider interface {
+	Provider
+	// ExtractUsage returns the usage reported in a response, and false if the
+	// response does not report it.
+	ExtractUsage(resp any) (Usage, bool)
+}
+
+// TurnUsage is the usage of one model call in a run, and its cost in USD
+// according to the agent's Pricer.
+type TurnUsage struct {
+	Usage
+	Cost float64 `json:"cost"`
+}
@@ -0,0 +1,348 @@
+package gopherai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrInvalidArguments is matched by errors.Is when a tool created with NewTool
+// is called with arguments that do not match its parameter schema. Such calls
+// are always reported to the model, whatever the tool error policy.
+var ErrInvalidArguments = errors.New("invalid tool arguments")
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Violation describes a value that does not satisfy a schema constraint.
+type Violation struct {
+	// Path locates the value, such as "$.addresses[0].city".
+	Path string `json:"path"`
+	// Constraint is the schema keyword that failed, such as "minimum".
+	Constraint string `json:"constraint"`
+	Message    string `json:"message"`
+}
+
+// ValidationError lists the violations found when checking a JSON value
+// against a schema.
+type ValidationError struct {
+	Violations []Violation `json:"violations"`
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Path + ": " + v.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// validateJSON checks the JSON document data against schema.
+func validateJSON(schema map[string]any, data []byte) (any, error) {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, &ValidationError{Violations: []Violation{{
+			Path:       "$",
+			Constraint: "json",
+			Message:    fmt.Sprintf("is not valid JSON: %v", err),
+		}}}
+	}
+
+	v := &validator{root: schema}
+	v.validate(schema, value, "$")
+	if len(v.violations) > 0 {
+		return nil, &ValidationError{Violations: v.violations}
+	}
+	return value, nil
+}
+
+// validator checks values against the subset of JSON Schema produced by
+// generateSchema.
+type validator struct {
+	root       map[string]any
+	violations []Violation
+}
+
+func (v *validator) fail(path, constraint, format string, args ...any) {
+	v.violations = append(v.violations, Violation{
+		Path:       path,
+		Constraint: constraint,
+		Message:    fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) validate(schema map[string]any, value any, path string) {
+	schema = v.resolve(schema)
+	// encoding/json decodes null into the zero value of any field.
+	if value == nil || schema == nil {
+		return
+	}
+
+	if typ, ok := schema["type"].(string); ok && !matchesType(typ, value) {
+		v.fail(path, "type", "must be of type %s, got %s", typ, jsonTypeOf(value))
+		return
+	}
+	if enum, ok := schema["enum"]; ok && !inEnum(enum, value) {
+		v.fail(path, "enum", "must be one of %v", enum)
+	}
+	if variants, ok := schema["anyOf"].([]any); ok && v.matching(variants, value, path) == 0 {
+		v.fail(path, "anyOf", "must match at least one of the allowed schemas")
+	}
+	if variants, ok := schema["oneOf"].([]any); ok {
+		if n := v.matching(variants, value, path); n != 1 {
+			v.fail(path, "oneOf", "must match exactly one of the allowed schemas, matched %d", n)
+		}
+	}
+
+	switch val := value.(type) {
+	case float64:
+		v.validateNumber(schema, val, path)
+	case string:
+		v.validateString(schema, val, path)
+	case []any:
+		v.validateArray(schema, val, path)
+	case map[string]any:
+		v.validateObject(schema, val, path)
+	}
+}
+
+// matching returns how many of the variant schemas value is valid against.
+func (v *validator) matching(variants []any, value any, path string) int {
+	n := 0
+	for _, variant := range variants {
+		schema, ok := variant.(map[string]any)
+		if !ok {
+			continue
+		}
+		sub := &validator{root: v.root}
+		sub.validate(schema, value, path)
+		if len(sub.violations) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// resolve follows local $ref pointers into the root schema.
+func (v *validator) resolve(schema map[string]any) map[string]any {
+	for range 32 {
+		ref, ok := schema["$ref"].(string)
+		if !ok {
+			return schema
+		}
+		if ref == "#" {
+			schema = v.root
+			continue
+		}
+		defs, _ := v.root["$defs"].(map[string]any)
+		schema, _ = defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any)
+	}
+	return schema
+}
+
+func (v *validator) validateNumber(schema map[string]any, value float64, path string) {
+	if minimum, ok := toFloat(schema["minimum"]); ok && value < minimum {
+		v.fail(path, "minimum", "must be greater than or equal to %v", minimum)
+	}
+	if maximum, ok := toFloat(schema["maximum"]); ok && value > maximum {
+		v.fail(path, "maximum", "must be less than or equal to %v", maximum)
+	}
+}
+
+func (v *validator) validateString(schema map[string]any, value, path string) {
+	length := utf8.RuneCountInString(value)
+	if minLength, ok := toFloat(schema["minLength"]); ok && float64(length) < minLength {
+		v.fail(path, "minLength", "must be at least %v characters long", minLength)
+	}
+	if maxLength, ok := toFloat(schema["maxLength"]); ok && float64(length) > maxLength {
+		v.fail(path, "maxLength", "must be at most %v characters long", maxLength)
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(value) {
+			v.fail(path, "pattern", "must match the pattern %s", pattern)
+		}
+	}
+	if format, ok := schema["format"].(string); ok && !matchesFormat(format, value) {
+		v.fail(path, "format", "must be a valid %s", format)
+	}
+}
+
+func (v *validator) validateArray(schema map[string]any, value []any, path string) {
+	if minItems, ok := toFloat(schema["minItems"]); ok && float64(len(value)) < minItems {
+		v.fail(path, "minItems", "must contain at least %v items", minItems)
+	}
+	if maxItems, ok := toFloat(schema["maxItems"]); ok && float64(len(value)) > maxItems {
+		v.fail(path, "maxItems", "must contain at most %v items", maxItems)
+	}
+	if items, ok := schema["items"].(map[string]any); ok {
+		for i, item := range value {
+			v.validate(items, item, path+"["+strconv.Itoa(i)+"]")
+		}
+	}
+}
+
+func (v *validator) validateObject(schema map[string]any, value map[string]any, path string) {
+	for _, name := range toStrings(schema["required"]) {
+		if _, ok := value[name]; !ok {
+			v.fail(path+"."+name, "required", "is required")
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]any)
+	additional, _ := schema["additionalProperties"].(map[string]any)
+	closed := schema["additionalProperties"] == false
+
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if prop, ok := properties[name].(map[string]any); ok {
+			v.validate(prop, value[name], path+"."+name)
+		} else if additional != nil {
+			v.validate(additional, value[name], path+"."+name)
+		} else if closed {
+			v.fail(path+"."+name, "additionalProperties", "is not an allowed property")
+		}
+	}
+}
+
+func matchesType(typ string, value any) bool {
+	switch typ {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	default:
+		return true
+	}
+}
+
+func jsonTypeOf(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	default:
+		return "null"
+	}
+}
+
+func matchesFormat(format, value string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "date":
+		_, err := time.Parse(time.DateOnly, value)
+		return err == nil
+	case "time":
+		_, err := time.Parse(time.TimeOnly, value)
+		return err == nil
+	case "email":
+		addr, err := mail.ParseAddress(value)
+		return err == nil && addr.Address == value
+	case "uri":
+		u, err := url.Parse(value)
+		return err == nil && u.Scheme != ""
+	case "uuid":
+		return uuidPattern.MatchString(value)
+	case "ipv4":
+		ip := net.ParseIP(value)
+		return ip != nil && ip.To4() != nil
+	case "ipv6":
+		ip := net.ParseIP(value)
+		return ip != nil && ip.To4() == nil
+	default:
+		return true
+	}
+}
+
+func inEnum(enum any, value any) bool {
+	switch values := enum.(type) {
+	case []string:
+		s, ok := value.(string)
+		if !ok {
+			return false
+		}
+		for _, e := range values {
+			if e == s {
+				return true
+			}
+		}
+		return false
+	case []any:
+		switch value.(type) {
+		case map[string]any, []any:
+			return false
+		}
+		for _, e := range values {
+			if e == value {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+func toFloat(value any) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+func toStrings(value any) []string {
+	switch list := value.(type) {
+	case []string:
+		return list
+	case []any:
+		var result []string
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
@@ -50,7 +50,7 @@ func TestNewAgent_AppliesToolsOption(t *testing.T) {
 
 func TestNewAgent_AppliesConversationHistoryOption(t *testing.T) {
 	provider := &mockProvider{}
-	history := []any{"msg1", "msg2"}
+	history := []gopherai.Message{gopherai.NewUserMessage("msg1"), gopherai.NewAssistantMessage("msg2")}
 
 	agent := gopherai.NewAgent(
 		provider,
@@ -70,7 +70,7 @@ func TestNewAgent_AppliesAllOptions(t *testing.T) {
 	tool := gopherai.NewTool("test", "description", func(_ testParams) (string, error) {
 		return "", nil
 	})
-	history := []any{"msg"}
+	history := []gopherai.Message{gopherai.NewUserMessage("msg")}
 
 	agent := gopherai.NewAgent(
 		provider,
@@ -113,7 +113,7 @@ func (m *mockProvider) CreateResponse(_ context.Context, _ any) (any, error) {
 	return &mockResponse{text: m.text}, nil
 }
 
-func (m *mockProvider) BuildRequest(_ any, _ string, _ []any) any {
+func (m *mockProvider) BuildRequest(_ []gopherai.Message, _ string, _ []any) any {
 	return &mockRequest{}
 }
 
@@ -132,18 +132,6 @@ func (m *mockProvider) ExtractText(resp any) string {
 	return ""
 }
 
-func (m *mockProvider) CreateFunctionCallInput(call gopherai.ToolCall) any {
-	return call
-}
-
-func (m *mockProvider) CreateFunctionCallOutput(callID, output string) any {
-	return gopherai.FunctionCallOutput{CallID: callID, Output: output}
-}
-
-func (m *mockProvider) CreateAssistantMessage(text string) any {
-	return text
-}
-
 type mockRequest struct{}
 
 type mockResponse struct {
@@ -405,7 +393,10 @@ func TestRunStream_WithHistory(t *testing.T) {
 		},
 	}
 	agent := gopherai.NewAgent(provider)
-	history := []any{"previous message", "previous response"}
+	history := []gopherai.Message{
+		gopherai.NewUserMessage("previous message"),
+		gopherai.NewAssistantMessage("previous response"),
+	}
 
 	events, err := agent.RunStream(context.Background(), "new prompt", history)
 	if err != nil {
@@ -430,7 +421,7 @@ func TestRunStream_WithConversationHistoryOption(t *testing.T) {
 			{Type: gopherai.StreamEventTypeDone},
 		},
 	}
-	history := []any{"preset message"}
+	history := []gopherai.Message{gopherai.NewUserMessage("preset message")}
 	agent := gopherai.NewAgent(provider, gopherai.WithConversationHistory(history))
 
 	events, err := agent.RunStream(context.Background(), "new prompt")
@@ -581,7 +572,7 @@ func (m *mockProviderWithError) CreateResponse(_ context.Context, _ any) (any, e
 	return nil, m.err
 }
 
-func (m *mockProviderWithError) BuildRequest(_ any, _ string, _ []any) any {
+func (m *mockProviderWithError) BuildRequest(_ []gopherai.Message, _ string, _ []any) any {
 	return &mockRequest{}
 }
 
@@ -597,18 +588,6 @@ func (m *mockProviderWithError) ExtractText(_ any) string {
 	return ""
 }
 
-func (m *mockProviderWithError) CreateFunctionCallInput(call gopherai.ToolCall) any {
-	return call
-}
-
-func (m *mockProviderWithError) CreateFunctionCallOutput(callID, output string) any {
-	return gopherai.FunctionCallOutput{CallID: callID, Output: output}
-}
-
-func (m *mockProviderWithError) CreateAssistantMessage(text string) any {
-	return text
-}
-
 type mockProviderWithSubagentCall struct {
 	subagentToolName string
 	callCount        *int
@@ -619,7 +598,7 @@ func (m *mockProviderWithSubagentCall) CreateResponse(_ context.Context, _ any)
 	return &mockResponse{text: m.finalResponse}, nil
 }
 
-func (m *mockProviderWithSubagentCall) BuildRequest(_ any, _ string, _ []any) any {
+func (m *mockProviderWithSubagentCall) BuildRequest(_ []gopherai.Message, _ string, _ []any) any {
 	return &mockRequest{}
 }
 
@@ -648,14 +627,70 @@ func (m *mockProviderWithSubagentCall) ExtractText(resp any) string {
 	return ""
 }
 
-func (m *mockProviderWithSubagentCall) CreateFunctionCallInput(call gopherai.ToolCall) any {
-	return call
-}
+func TestRun_RecordsTypedMessageHistory(t *testing.T) {
+	subagent := gopherai.NewAgent(&mockProvider{text: "subagent result"})
+
+	callCount := 0
+	provider := &mockProviderWithSubagentCall{
+		subagentToolName: "researcher",
+		callCount:        &callCount,
+		finalResponse:    "final answer",
+	}
+	agent := gopherai.NewAgent(provider,
+		gopherai.WithTools(subagent.AsTool("researcher", "Researches topics")),
+	)
+
+	result, err := agent.Run(context.Background(), "research something")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
-func (m *mockProviderWithSubagentCall) CreateFunctionCallOutput(callID, output string) any {
-	return gopherai.FunctionCallOutput{CallID: callID, Output: output}
+	history := result.MessageHistory()
+	expectedRoles := []gopherai.Role{
+		gopherai.RoleUser,
+		gopherai.RoleAssistant,
+		gopherai.RoleTool,
+		gopherai.RoleAssistant,
+	}
+	if len(history) != len(expectedRoles) {
+		t.Fatalf("expected %d messages, got %d", len(expectedRoles), len(history))
+	}
+	for i, role := range expectedRoles {
+		if history[i].Role != role {
+			t.Errorf("expected message %d role '%s', got '%s'", i, role, history[i].Role)
+		}
+	}
+
+	calls := history[1].ToolCalls()
+	if len(calls) != 1 || calls[0].CallID != "call_subagent_1" {
+		t.Errorf("expected tool call 'call_subagent_1', got %v", calls)
+	}
+
+	results := history[2].ToolResults()
+	if len(results) != 1 || results[0].Output != "subagent result" || results[0].Name != "researcher" {
+		t.Errorf("expected tool result from researcher, got %v", results)
+	}
 }
 
-func (m *mockProviderWithSubagentCall) CreateAssistantMessage(text string) any {
-	return text
+func TestAgent_WithProvider_ContinuesHistoryOnNewProvider(t *testing.T) {
+	first := gopherai.NewAgent(&mockProvider{text: "first answer"}, gopherai.WithSystemPrompt("prompt"))
+
+	result, err := first.Run(context.Background(), "first question")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	second := first.WithProvider(&mockProvider{text: "second answer"})
+	followUp, err := second.Run(context.Background(), "second question", result.MessageHistory())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if followUp.Text != "second answer" {
+		t.Errorf("expected 'second answer', got '%s'", followUp.Text)
+	}
+
+	if len(followUp.MessageHistory()) != 4 {
+		t.Errorf("expected 4 messages in history, got %d", len(followUp.MessageHistory()))
+	}
 }
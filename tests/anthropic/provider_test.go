@@ -0,0 +1,76 @@
+package anthropic_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+	"github.com/marti-jorda-roca/gopher-ai/gopherai/anthropic"
+)
+
+func TestNewProvider_CreatesProvider(t *testing.T) {
+	provider := anthropic.NewProvider("test-api-key")
+
+	if provider == nil {
+		t.Fatal("expected provider to be created")
+	}
+}
+
+func TestProvider_MethodChainingWorks(t *testing.T) {
+	provider := anthropic.NewProvider("test-api-key").
+		SetModel("claude-opus-4-1").
+		SetTemperature(0.2).
+		SetMaxTokens(1024).
+		SetBaseURL("http://localhost")
+
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil).(*anthropic.MessagesRequest)
+	if req.Model != "claude-opus-4-1" {
+		t.Errorf("expected model 'claude-opus-4-1', got '%s'", req.Model)
+	}
+	if req.MaxTokens != 1024 {
+		t.Errorf("expected max tokens 1024, got %d", req.MaxTokens)
+	}
+	if req.Temperature == nil || *req.Temperature != 0.2 {
+		t.Errorf("expected temperature 0.2, got %v", req.Temperature)
+	}
+}
+
+func TestBuildRequest_SetsDefaultMaxTokens(t *testing.T) {
+	provider := anthropic.NewProvider("test-api-key")
+
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil).(*anthropic.MessagesRequest)
+	if req.MaxTokens <= 0 {
+		t.Errorf("expected a default max tokens, got %d", req.MaxTokens)
+	}
+}
+
+var _ gopherai.StreamProvider = (*anthropic.Provider)(nil)
+
+func TestSetAuthenticator_ReplacesAPIKeyHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if key := r.Header.Get("x-api-key"); key != "" {
+			t.Errorf("expected no x-api-key header, got %q", key)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer gateway-token" {
+			t.Errorf("expected gateway token, got %q", auth)
+		}
+		if custom := r.Header.Get("anthropic-beta"); custom != "tools-2024" {
+			t.Errorf("expected custom header, got %q", custom)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"text","text":"ok"}]}`))
+	}))
+	defer server.Close()
+
+	provider := anthropic.NewProvider("test-key").
+		SetBaseURL(server.URL).
+		SetAuthenticator(gopherai.BearerToken("gateway-token")).
+		SetHeader("anthropic-beta", "tools-2024")
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil)
+
+	if _, err := provider.CreateResponse(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
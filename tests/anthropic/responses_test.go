@@ -0,0 +1,490 @@
+package anthropic_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+	"github.com/marti-jorda-roca/gopher-ai/gopherai/anthropic"
+)
+
+func TestConvertTool_CreatesToolWithInputSchema(t *testing.T) {
+	provider := anthropic.NewProvider("test-key")
+	tool := gopherai.Tool{
+		Name:        "get_weather",
+		Description: "Gets the weather",
+		Parameters:  map[string]any{"type": "object"},
+	}
+
+	converted, ok := provider.ConvertTool(tool).(anthropic.Tool)
+	if !ok {
+		t.Fatal("expected result to be Tool")
+	}
+	if converted.Name != "get_weather" || converted.InputSchema["type"] != "object" {
+		t.Errorf("unexpected tool: %+v", converted)
+	}
+}
+
+func TestBuildRequest_SetsSystemPromptAndTools(t *testing.T) {
+	provider := anthropic.NewProvider("test-key")
+	tools := []any{provider.ConvertTool(gopherai.Tool{Name: "lookup"})}
+
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "be brief", tools).(*anthropic.MessagesRequest)
+	if req.System != "be brief" {
+		t.Errorf("expected system 'be brief', got '%s'", req.System)
+	}
+	if len(req.Tools) != 1 || req.Tools[0].Name != "lookup" {
+		t.Errorf("expected lookup tool, got %+v", req.Tools)
+	}
+	if len(req.Messages) != 1 || req.Messages[0].Role != "user" {
+		t.Errorf("expected 1 user message, got %+v", req.Messages)
+	}
+}
+
+func TestConvertMessages_CreatesToolUseAndToolResultBlocks(t *testing.T) {
+	call := gopherai.ToolCall{Name: "get_weather", Arguments: `{"city":"Paris"}`, CallID: "toolu_1"}
+	messages := []gopherai.Message{
+		gopherai.NewUserMessage("weather?"),
+		gopherai.NewAssistantMessage("Let me check.", call),
+		gopherai.NewToolResultMessage(gopherai.ToolResult{CallID: "toolu_1", Name: "get_weather", Output: "sunny"}),
+		gopherai.NewUserMessage("thanks"),
+	}
+
+	converted := anthropic.ConvertMessages(messages)
+	if len(converted) != 3 {
+		t.Fatalf("expected 3 alternating messages, got %d: %+v", len(converted), converted)
+	}
+
+	assistant := converted[1]
+	if assistant.Role != "assistant" || len(assistant.Content) != 2 {
+		t.Fatalf("unexpected assistant message: %+v", assistant)
+	}
+	toolUse := assistant.Content[1]
+	if toolUse.Type != "tool_use" || toolUse.ID != "toolu_1" || string(toolUse.Input) != `{"city":"Paris"}` {
+		t.Errorf("unexpected tool_use block: %+v", toolUse)
+	}
+
+	user := converted[2]
+	if user.Role != "user" || len(user.Content) != 2 {
+		t.Fatalf("expected tool result and text merged into one user message, got %+v", user)
+	}
+	if user.Content[0].Type != "tool_result" || user.Content[0].ToolUseID != "toolu_1" || user.Content[0].Content != "sunny" {
+		t.Errorf("unexpected tool_result block: %+v", user.Content[0])
+	}
+}
+
+func TestConvertMessages_SendsEmptyObjectForMissingArguments(t *testing.T) {
+	call := gopherai.ToolCall{Name: "now", CallID: "toolu_1"}
+
+	converted := anthropic.ConvertMessages([]gopherai.Message{gopherai.NewAssistantMessage("", call)})
+
+	data, err := json.Marshal(converted[0].Content[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(data), `"input":{}`) {
+		t.Errorf("expected empty input object, got %s", data)
+	}
+}
+
+func TestConvertMessages_SendsEmptyObjectForInvalidArguments(t *testing.T) {
+	call := gopherai.ToolCall{Name: "now", Arguments: `{"city":`, CallID: "toolu_1"}
+
+	converted := anthropic.ConvertMessages([]gopherai.Message{gopherai.NewAssistantMessage("", call)})
+
+	data, err := json.Marshal(converted)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(data), `"input":{}`) {
+		t.Errorf("expected empty input object, got %s", data)
+	}
+}
+
+func TestConvertMessages_SkipsPartsWithoutToolCallOrResult(t *testing.T) {
+	messages := []gopherai.Message{
+		{Role: gopherai.RoleAssistant, Parts: []gopherai.Part{
+			gopherai.TextPart("checking"),
+			{Type: gopherai.PartTypeToolCall},
+		}},
+		{Role: gopherai.RoleTool, Parts: []gopherai.Part{{Type: gopherai.PartTypeToolResult}}},
+	}
+
+	converted := anthropic.ConvertMessages(messages)
+
+	if len(converted) != 1 || len(converted[0].Content) != 1 || converted[0].Content[0].Type != "text" {
+		t.Errorf("expected only the text block, got %+v", converted)
+	}
+}
+
+func TestToMessages_ConvertsBlocksToMessages(t *testing.T) {
+	messages := []anthropic.Message{
+		{Role: "user", Content: []anthropic.ContentBlock{anthropic.NewTextBlock("weather?")}},
+		{Role: "assistant", Content: []anthropic.ContentBlock{
+			anthropic.NewToolUseBlock("toolu_1", "get_weather", json.RawMessage(`{"city":"Paris"}`)),
+		}},
+		{Role: "user", Content: []anthropic.ContentBlock{anthropic.NewToolResultBlock("toolu_1", "sunny", false)}},
+	}
+
+	converted := anthropic.ToMessages(messages)
+	if len(converted) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(converted))
+	}
+	if calls := converted[1].ToolCalls(); len(calls) != 1 || calls[0].Arguments != `{"city":"Paris"}` {
+		t.Errorf("unexpected tool calls: %+v", calls)
+	}
+	if converted[2].Role != gopherai.RoleTool || converted[2].ToolResults()[0].Output != "sunny" {
+		t.Errorf("unexpected tool message: %+v", converted[2])
+	}
+}
+
+func TestExtractToolCallsAndText_ReadContentBlocks(t *testing.T) {
+	provider := anthropic.NewProvider("test-key")
+	resp := &anthropic.MessagesResponse{
+		Content: []anthropic.ContentBlock{
+			anthropic.NewTextBlock("Checking."),
+			anthropic.NewToolUseBlock("toolu_1", "get_weather", json.RawMessage(`{"city":"Paris"}`)),
+		},
+	}
+
+	calls, err := provider.ExtractToolCalls(resp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(calls) != 1 || calls[0].CallID != "toolu_1" || calls[0].Name != "get_weather" {
+		t.Errorf("unexpected calls: %+v", calls)
+	}
+	if text := provider.ExtractText(resp); text != "Checking." {
+		t.Errorf("expected 'Checking.', got '%s'", text)
+	}
+}
+
+func TestExtractUsage_IncludesCachedInputTokens(t *testing.T) {
+	provider := anthropic.NewProvider("test-key")
+	resp := &anthropic.MessagesResponse{Usage: anthropic.Usage{
+		InputTokens:              20,
+		CacheCreationInputTokens: 100,
+		CacheReadInputTokens:     300,
+		OutputTokens:             50,
+	}}
+
+	usage, ok := provider.ExtractUsage(resp)
+	if !ok {
+		t.Fatal("expected usage to be reported")
+	}
+	if usage.InputTokens != 420 || usage.OutputTokens != 50 {
+		t.Errorf("unexpected usage: %+v", usage)
+	}
+}
+
+func TestExtractToolCalls_ReturnsErrorForInvalidType(t *testing.T) {
+	provider := anthropic.NewProvider("test-key")
+
+	if _, err := provider.ExtractToolCalls("invalid"); err == nil {
+		t.Error("expected error for invalid response type")
+	}
+}
+
+func TestCreateResponse_PostsToMessagesEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/messages" {
+			t.Errorf("expected path /messages, got %s", r.URL.Path)
+		}
+		if r.Header.Get("x-api-key") != "test-key" || r.Header.Get("anthropic-version") == "" {
+			t.Errorf("missing authentication headers: %v", r.Header)
+		}
+
+		var req anthropic.MessagesRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		if req.System != "be brief" {
+			t.Errorf("expected system prompt, got '%s'", req.System)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"text","text":"Hello!"}],"stop_reason":"end_turn","usage":{"input_tokens":5,"output_tokens":2}}`))
+	}))
+	defer server.Close()
+
+	provider := anthropic.NewProvider("test-key").SetBaseURL(server.URL)
+	agent := gopherai.NewAgent(provider, gopherai.WithSystemPrompt("be brief"))
+
+	result, err := agent.Run(context.Background(), "hi")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Text != "Hello!" {
+		t.Errorf("expected 'Hello!', got '%s'", result.Text)
+	}
+}
+
+func TestCreateResponse_ReturnsAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"type":"error","error":{"type":"invalid_request_error","message":"max_tokens is required"}}`))
+	}))
+	defer server.Close()
+
+	provider := anthropic.NewProvider("test-key").SetBaseURL(server.URL)
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil)
+
+	_, err := provider.CreateResponse(context.Background(), req)
+	if err == nil || !strings.Contains(err.Error(), "max_tokens is required") {
+		t.Errorf("expected API error message, got %v", err)
+	}
+}
+
+const toolUseStream = `event: message_start
+data: {"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","content":[]}}
+
+event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Let me "}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"check."}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":0}
+
+event: content_block_start
+data: {"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{}}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"city\": "}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"\"Paris\"}"}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":1}
+
+event: ping
+data: {"type":"ping"}
+
+event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":12}}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+`
+
+func TestParseSSEStream_ParsesTextAndToolUseEvents(t *testing.T) {
+	events := anthropic.ParseSSEStreamForTest(strings.NewReader(toolUseStream))
+
+	var deltas []string
+	var toolCall *gopherai.ToolCall
+	var text string
+	var done bool
+	for event := range events {
+		switch event.Type {
+		case gopherai.StreamEventTypeTextDelta:
+			deltas = append(deltas, event.Delta)
+		case gopherai.StreamEventTypeTextDone:
+			text = event.Text
+		case gopherai.StreamEventTypeToolCall:
+			toolCall = event.ToolCall
+		case gopherai.StreamEventTypeDone:
+			done = true
+		case gopherai.StreamEventTypeError:
+			t.Fatalf("unexpected error event: %v", event.Error)
+		}
+	}
+
+	if strings.Join(deltas, "") != "Let me check." || text != "Let me check." {
+		t.Errorf("unexpected text: deltas=%v text=%q", deltas, text)
+	}
+	if toolCall == nil || toolCall.CallID != "toolu_1" || toolCall.Arguments != `{"city": "Paris"}` {
+		t.Errorf("unexpected tool call: %+v", toolCall)
+	}
+	if !done {
+		t.Error("expected done event")
+	}
+}
+
+func TestParseSSEStream_ParsesErrorEvents(t *testing.T) {
+	stream := "event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n"
+
+	var streamErr error
+	for event := range anthropic.ParseSSEStreamForTest(strings.NewReader(stream)) {
+		if event.Type == gopherai.StreamEventTypeError {
+			streamErr = event.Error
+		}
+	}
+
+	if streamErr == nil || !strings.Contains(streamErr.Error(), "Overloaded") {
+		t.Errorf("expected overloaded error, got %v", streamErr)
+	}
+}
+
+func TestCreateResponseStream_RunsAgentAgainstServer(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var req anthropic.MessagesRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream {
+			t.Error("expected stream to be enabled")
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		if requests == 1 {
+			_, _ = w.Write([]byte(toolUseStream))
+			return
+		}
+		_, _ = w.Write([]byte(`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Sunny."}}
+
+data: {"type":"message_stop"}
+
+`))
+	}))
+	defer server.Close()
+
+	weather := gopherai.NewTool("get_weather", "Gets the weather", func(p struct {
+		City string `json:"city"`
+	}) (string, error) {
+		return "sunny in " + p.City, nil
+	})
+	provider := anthropic.NewProvider("test-key").SetBaseURL(server.URL)
+	agent := gopherai.NewAgent(provider, gopherai.WithTools(weather))
+
+	events, err := agent.RunStream(context.Background(), "weather in Paris?")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var final string
+	for event := range events {
+		if event.Type == gopherai.StreamEventTypeError {
+			t.Fatalf("unexpected error: %v", event.Error)
+		}
+		if event.Type == gopherai.StreamEventTypeTextDone {
+			final = event.Text
+		}
+	}
+
+	if final != "Sunny." {
+		t.Errorf("expected final text 'Sunny.', got '%s'", final)
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+}
+
+func TestCreateResponse_ReturnsTypedAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("request-id", "req_abc")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long: 210000 tokens > 200000 maximum"}}`))
+	}))
+	defer server.Close()
+
+	provider := anthropic.NewProvider("test-key").SetBaseURL(server.URL)
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil)
+
+	_, err := provider.CreateResponse(context.Background(), req)
+	var apiErr *gopherai.APIError
+	if !errors.As(err, &apiErr) || !errors.Is(err, gopherai.ErrContextLengthExceeded) {
+		t.Fatalf("expected typed context length error, got %v", err)
+	}
+	if apiErr.RequestID != "req_abc" || apiErr.Code != "invalid_request_error" {
+		t.Errorf("unexpected error: %+v", apiErr)
+	}
+}
+
+func TestParseSSEStream_ReturnsRetryableOverloadedError(t *testing.T) {
+	stream := "event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n"
+
+	var apiErr *gopherai.APIError
+	for event := range anthropic.ParseSSEStreamForTest(strings.NewReader(stream)) {
+		if event.Type == gopherai.StreamEventTypeError && !errors.As(event.Error, &apiErr) {
+			t.Fatalf("expected *gopherai.APIError, got %T", event.Error)
+		}
+	}
+
+	if apiErr == nil || !apiErr.Retryable || apiErr.Code != "overloaded_error" {
+		t.Errorf("expected retryable overloaded error, got %+v", apiErr)
+	}
+}
+
+func TestParseSSEStream_EmitsUsageFromMessageEvents(t *testing.T) {
+	stream := `event: message_start
+data: {"type":"message_start","message":{"model":"claude-sonnet-4-5","usage":{"input_tokens":10,"cache_read_input_tokens":200,"output_tokens":1}}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hi"}}
+
+event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":25}}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+`
+	var usage *gopherai.Usage
+	for event := range anthropic.ParseSSEStreamForTest(strings.NewReader(stream)) {
+		if event.Type == gopherai.StreamEventTypeUsage {
+			usage = event.Usage
+		}
+	}
+
+	want := gopherai.Usage{Model: "claude-sonnet-4-5", InputTokens: 210, CachedInputTokens: 200, OutputTokens: 25}
+	if usage == nil || *usage != want {
+		t.Errorf("expected usage %+v, got %+v", want, usage)
+	}
+}
+
+func TestCreateResponse_ReturnsContentFilteredErrorForRefusal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-5","content":[],"stop_reason":"refusal","usage":{"input_tokens":10,"output_tokens":0}}`))
+	}))
+	defer server.Close()
+
+	provider := anthropic.NewProvider("test-key").SetBaseURL(server.URL)
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil)
+
+	_, err := provider.CreateResponse(context.Background(), req)
+	if !errors.Is(err, gopherai.ErrContentFiltered) {
+		t.Fatalf("expected content filtered error, got %v", err)
+	}
+}
+
+func TestParseSSEStream_ReturnsContentFilteredErrorForRefusal(t *testing.T) {
+	stream := `event: message_start
+data: {"type":"message_start","message":{"model":"claude-sonnet-4-5","usage":{"input_tokens":10,"output_tokens":1}}}
+
+event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"refusal"},"usage":{"output_tokens":1}}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+`
+
+	var streamErr error
+	done := false
+	for event := range anthropic.ParseSSEStreamForTest(strings.NewReader(stream)) {
+		switch event.Type {
+		case gopherai.StreamEventTypeError:
+			streamErr = event.Error
+		case gopherai.StreamEventTypeDone:
+			done = true
+		}
+	}
+
+	if !errors.Is(streamErr, gopherai.ErrContentFiltered) || done {
+		t.Errorf("expected the stream to end with a content filtered error, got %v (done %v)", streamErr, done)
+	}
+}
@@ -0,0 +1,244 @@
+package gopherai_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+)
+
+// approvalTool is an echo tool that requires approval and records the values
+// it is called with.
+func approvalTool(received *[]string) gopherai.Tool {
+	return gopherai.NewTool("echo", "echoes the value", func(p limitTestParams) (string, error) {
+		*received = append(*received, p.Value)
+		return p.Value, nil
+	}).WithApproval()
+}
+
+// roundTrip encodes and decodes the state, as a state stored between the
+// interruption and the resumption would be.
+func roundTrip(t *testing.T, state *gopherai.RunState) *gopherai.RunState {
+	t.Helper()
+	data, err := json.Marshal(state)
+	if err != nil {
+		t.Fatalf("failed to encode state: %v", err)
+	}
+	var decoded gopherai.RunState
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed to decode state: %v", err)
+	}
+	return &decoded
+}
+
+func TestRun_InterruptsBeforeToolsRequiringApproval(t *testing.T) {
+	var received []string
+	agent := gopherai.NewAgent(meteredProvider(), gopherai.WithTools(approvalTool(&received)))
+
+	result, err := agent.Run(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !result.Interrupted {
+		t.Fatal("expected the run to be interrupted")
+	}
+	if len(received) != 0 {
+		t.Errorf("expected the tool not to run, got calls %v", received)
+	}
+	pending := result.PendingToolCalls()
+	if len(pending) != 1 || pending[0].CallID != "call_1" {
+		t.Fatalf("expected call_1 pending, got %+v", pending)
+	}
+	if result.State.RunID != result.RunID || result.State.Turns != 1 {
+		t.Errorf("expected state of run %s after 1 turn, got %+v", result.RunID, result.State)
+	}
+	if len(result.MessageHistory()) != 1 {
+		t.Errorf("expected only the prompt in history, got %d messages", len(result.MessageHistory()))
+	}
+}
+
+func TestResume_RunsApprovedCallWithEditedArguments(t *testing.T) {
+	var received []string
+	provider := meteredProvider()
+	agent := gopherai.NewAgent(provider, gopherai.WithTools(approvalTool(&received)))
+
+	interrupted, err := agent.Run(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resumer := gopherai.NewAgent(provider, gopherai.WithTools(approvalTool(&received)))
+	result, err := resumer.Resume(context.Background(), roundTrip(t, interrupted.State), []gopherai.ToolDecision{
+		gopherai.ApproveWithArguments("call_1", `{"value":"y"}`),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Interrupted || result.Text != "done" {
+		t.Fatalf("expected the run to complete with 'done', got %+v", result)
+	}
+	if len(received) != 1 || received[0] != "y" {
+		t.Errorf("expected the tool to run once with the edited value, got %v", received)
+	}
+	if result.RunID != interrupted.RunID {
+		t.Errorf("expected the resumed run to keep ID %s, got %s", interrupted.RunID, result.RunID)
+	}
+	history := result.MessageHistory()
+	if len(history) != 4 {
+		t.Fatalf("expected 4 messages in history, got %d", len(history))
+	}
+	if args := history[1].ToolCalls()[0].Arguments; args != `{"value":"y"}` {
+		t.Errorf("expected edited arguments in history, got %s", args)
+	}
+	if len(result.Turns) != 2 || result.Usage.TotalTokens() != 2350 {
+		t.Errorf("expected usage of both turns, got %d turns and %d tokens", len(result.Turns), result.Usage.TotalTokens())
+	}
+}
+
+func TestResume_ReportsRejectedCallToModel(t *testing.T) {
+	var received []string
+	agent := gopherai.NewAgent(meteredProvider(), gopherai.WithTools(approvalTool(&received)))
+
+	interrupted, err := agent.Run(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, err := agent.Resume(context.Background(), interrupted.State, []gopherai.ToolDecision{
+		gopherai.Reject("call_1", "not today"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(received) != 0 {
+		t.Errorf("expected the rejected tool not to run, got calls %v", received)
+	}
+	toolResult := result.MessageHistory()[2].ToolResults()[0]
+	if !toolResult.IsError || !strings.Contains(toolResult.Output, "not today") {
+		t.Errorf("expected the rejection to be reported, got %+v", toolResult)
+	}
+}
+
+func TestResume_RequiresADecisionForEachPendingCall(t *testing.T) {
+	var received []string
+	agent := gopherai.NewAgent(meteredProvider(), gopherai.WithTools(approvalTool(&received)))
+
+	interrupted, err := agent.Run(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := agent.Resume(context.Background(), interrupted.State, nil); err == nil {
+		t.Error("expected an error without decisions")
+	}
+	if _, err := agent.Resume(context.Background(), interrupted.State, []gopherai.ToolDecision{
+		gopherai.Approve("call_1"),
+		gopherai.Approve("call_9"),
+	}); err == nil {
+		t.Error("expected an error for a call that is not pending")
+	}
+}
+
+func TestResume_SavesInterruptedSessionOnCompletion(t *testing.T) {
+	var received []string
+	store := gopherai.NewMemorySessionStore()
+	agent := gopherai.NewAgent(meteredProvider(),
+		gopherai.WithTools(approvalTool(&received)),
+		gopherai.WithSessionStore(store),
+	)
+
+	interrupted, err := agent.RunSession(context.Background(), "s1", "go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if messages, _ := store.Load(context.Background(), "s1"); len(messages) != 0 {
+		t.Fatalf("expected nothing saved while interrupted, got %d messages", len(messages))
+	}
+
+	if _, err := agent.Resume(context.Background(), interrupted.State, []gopherai.ToolDecision{gopherai.Approve("call_1")}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	messages, err := store.Load(context.Background(), "s1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(messages) != 4 {
+		t.Errorf("expected the whole run saved, got %d messages", len(messages))
+	}
+}
+
+func TestRunStream_EndsWithInterruptedEvent(t *testing.T) {
+	var received []string
+	agent := gopherai.NewAgent(meteredProvider(), gopherai.WithTools(approvalTool(&received)))
+
+	events, err := agent.RunStream(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var last gopherai.StreamEvent
+	for event := range events {
+		last = event
+	}
+	if last.Type != gopherai.StreamEventTypeInterrupted || last.State == nil {
+		t.Fatalf("expected an interrupted event last, got %+v", last)
+	}
+
+	resumed, err := agent.ResumeStream(context.Background(), last.State, []gopherai.ToolDecision{gopherai.Approve("call_1")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var text string
+	for event := range resumed {
+		switch event.Type {
+		case gopherai.StreamEventTypeTextDone:
+			text = event.Text
+		case gopherai.StreamEventTypeError:
+			t.Fatalf("unexpected error: %v", event.Error)
+		}
+	}
+	if text != "done" || len(received) != 1 {
+		t.Errorf("expected the resumed stream to run the tool and answer, got %q and calls %v", text, received)
+	}
+}
+
+func TestResume_DecidesOnEachOfTwoCallsToTheSameTool(t *testing.T) {
+	var received []string
+	// The two calls have the same ID, as providers that do not identify calls may give them.
+	provider := &mockScriptedProvider{turns: []mockTurn{{calls: []gopherai.ToolCall{
+		{Name: "echo", Arguments: `{"value":"a"}`, CallID: "echo"},
+		{Name: "echo", Arguments: `{"value":"b"}`, CallID: "echo"},
+	}}}}
+	agent := gopherai.NewAgent(provider,
+		gopherai.WithTools(approvalTool(&received)),
+	)
+
+	interrupted, err := agent.Run(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pending := interrupted.PendingToolCalls()
+	if len(pending) != 2 || pending[0].CallID == pending[1].CallID {
+		t.Fatalf("expected 2 pending calls with distinct IDs, got %+v", pending)
+	}
+
+	result, err := agent.Resume(context.Background(), interrupted.State, []gopherai.ToolDecision{
+		gopherai.Approve(pending[0].CallID),
+		gopherai.Reject(pending[1].CallID, "not this one"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(received) != 1 || received[0] != "a" {
+		t.Errorf("expected only the approved call to run, got %v", received)
+	}
+	results := result.MessageHistory()[2].ToolResults()
+	if len(results) != 2 || results[0].IsError || !results[1].IsError {
+		t.Errorf("expected the first call to succeed and the second to be rejected, got %+v", results)
+	}
+}
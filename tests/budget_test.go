@@ -0,0 +1,161 @@
+package gopherai_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+)
+
+// spending returns the usage of a response that spends tokens.
+func spending(tokens int) *gopherai.Usage {
+	return &gopherai.Usage{Model: "gpt-4.1", InputTokens: tokens}
+}
+
+func TestRun_StopsWhenTokenBudgetIsReached(t *testing.T) {
+	provider := &mockScriptedProvider{then: loopingTurns("echo", `{"value":"x"}`, 1, spending(1000))}
+	agent := gopherai.NewAgent(provider,
+		gopherai.WithTools(echoTool()),
+		gopherai.WithBudget(2500, 0),
+	)
+
+	_, err := agent.Run(context.Background(), "loop")
+	if !errors.Is(err, gopherai.ErrBudgetExceeded) || !errors.Is(err, gopherai.ErrLimitExceeded) {
+		t.Fatalf("expected ErrBudgetExceeded, got %v", err)
+	}
+
+	var limitErr *gopherai.LimitExceededError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("expected LimitExceededError, got %v", err)
+	}
+	if limitErr.Limit != gopherai.LimitTokenBudget {
+		t.Errorf("expected limit 'token_budget', got '%s'", limitErr.Limit)
+	}
+	if provider.responses() != 3 {
+		t.Errorf("expected 3 model calls, got %d", provider.responses())
+	}
+	if limitErr.Result.Usage.TotalTokens() != 3000 {
+		t.Errorf("expected partial result with 3000 tokens, got %d", limitErr.Result.Usage.TotalTokens())
+	}
+	if len(limitErr.Result.MessageHistory()) != 7 {
+		t.Errorf("expected prompt and 3 tool exchanges in history, got %d messages", len(limitErr.Result.MessageHistory()))
+	}
+}
+
+func TestRun_StopsWhenCostBudgetIsReached(t *testing.T) {
+	provider := &mockScriptedProvider{then: loopingTurns("echo", `{"value":"x"}`, 1, spending(100_000))}
+	agent := gopherai.NewAgent(provider,
+		gopherai.WithTools(echoTool()),
+		gopherai.WithPricing(gopherai.PriceTable{"gpt-4.1": {Input: 2}}),
+		gopherai.WithBudget(0, 0.5),
+	)
+
+	_, err := agent.Run(context.Background(), "loop")
+
+	var limitErr *gopherai.LimitExceededError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("expected LimitExceededError, got %v", err)
+	}
+	if limitErr.Limit != gopherai.LimitCostBudget {
+		t.Errorf("expected limit 'cost_budget', got '%s'", limitErr.Limit)
+	}
+	if provider.responses() != 3 {
+		t.Errorf("expected 3 model calls of $0.20, got %d", provider.responses())
+	}
+}
+
+func TestRun_CompletesWithinBudget(t *testing.T) {
+	agent := gopherai.NewAgent(&mockScriptedProvider{then: answeringTurns("done", spending(100))}, gopherai.WithBudget(1000, 0))
+
+	result, err := agent.Run(context.Background(), "hi")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Text != "done" {
+		t.Errorf("expected 'done', got %q", result.Text)
+	}
+}
+
+func TestRun_SubAgentsDrawFromParentBudget(t *testing.T) {
+	subProvider := &mockScriptedProvider{then: answeringTurns("found it", spending(1000))}
+	subAgent := gopherai.NewAgent(subProvider)
+
+	parentProvider := &mockScriptedProvider{then: loopingTurns("researcher", `{"task":"research"}`, 1, spending(100))}
+	parent := gopherai.NewAgent(parentProvider,
+		gopherai.WithTools(subAgent.AsTool("researcher", "researches a topic")),
+		gopherai.WithBudget(1500, 0),
+	)
+
+	_, err := parent.Run(context.Background(), "research")
+
+	var limitErr *gopherai.LimitExceededError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("expected LimitExceededError, got %v", err)
+	}
+	if limitErr.Limit != gopherai.LimitTokenBudget {
+		t.Errorf("expected limit 'token_budget', got '%s'", limitErr.Limit)
+	}
+	if parentProvider.responses() != 2 || subProvider.responses() != 2 {
+		t.Errorf("expected 2 parent and 2 sub-agent calls, got %d and %d", parentProvider.responses(), subProvider.responses())
+	}
+}
+
+func TestRun_SubAgentStopsOnParentBudget(t *testing.T) {
+	subProvider := &mockScriptedProvider{then: loopingTurns("echo", `{"value":"x"}`, 1, spending(1000))}
+	subAgent := gopherai.NewAgent(subProvider, gopherai.WithTools(echoTool()))
+
+	parentProvider := &mockScriptedProvider{then: loopingTurns("researcher", `{"task":"research"}`, 1, spending(100))}
+	parent := gopherai.NewAgent(parentProvider,
+		gopherai.WithTools(subAgent.AsTool("researcher", "researches a topic")),
+		gopherai.WithBudget(2500, 0),
+	)
+
+	_, err := parent.Run(context.Background(), "research")
+
+	var limitErr *gopherai.LimitExceededError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("expected LimitExceededError, got %v", err)
+	}
+	if limitErr.Turns != 1 {
+		t.Errorf("expected the parent to stop after its first turn, got %d turns", limitErr.Turns)
+	}
+	if subProvider.responses() != 3 {
+		t.Errorf("expected the sub-agent to stop after 3 calls, got %d", subProvider.responses())
+	}
+}
+
+func TestRunTyped_RetriesDrawFromTheRunBudget(t *testing.T) {
+	provider := &mockStructuredProvider{}
+	provider.then = answeringTurns("not json", spending(100))
+	agent := gopherai.NewAgent(provider, gopherai.WithBudget(150, 0), gopherai.WithOutputRetries(5))
+
+	_, err := gopherai.RunTyped[weatherReport](context.Background(), agent, "Weather?")
+
+	var limitErr *gopherai.LimitExceededError
+	if !errors.As(err, &limitErr) || limitErr.Limit != gopherai.LimitTokenBudget {
+		t.Fatalf("expected token budget error, got %v", err)
+	}
+	if provider.responses() != 2 {
+		t.Errorf("expected 2 model calls within the budget, got %d", provider.responses())
+	}
+	if limitErr.Result.Usage.TotalTokens() != 200 {
+		t.Errorf("expected partial result with the usage of both attempts, got %d tokens", limitErr.Result.Usage.TotalTokens())
+	}
+}
+
+func TestRunTyped_RetriesCountTowardsMaxTurns(t *testing.T) {
+	provider := &mockStructuredProvider{}
+	provider.then = answeringTurns("not json", spending(100))
+	agent := gopherai.NewAgent(provider, gopherai.WithMaxTurns(2), gopherai.WithOutputRetries(5))
+
+	_, err := gopherai.RunTyped[weatherReport](context.Background(), agent, "Weather?")
+
+	var limitErr *gopherai.LimitExceededError
+	if !errors.As(err, &limitErr) || limitErr.Limit != gopherai.LimitMaxTurns {
+		t.Fatalf("expected max turns error, got %v", err)
+	}
+	if provider.responses() != 2 {
+		t.Errorf("expected 2 model calls, got %d", provider.responses())
+	}
+}
@@ -0,0 +1,243 @@
+package gopherai_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+)
+
+func testCheckpointer(t *testing.T, checkpointer gopherai.Checkpointer) {
+	t.Helper()
+	ctx := context.Background()
+
+	if _, err := checkpointer.Load(ctx, "missing"); !errors.Is(err, gopherai.ErrCheckpointNotFound) {
+		t.Errorf("expected ErrCheckpointNotFound, got %v", err)
+	}
+
+	response := gopherai.NewAssistantMessage("", gopherai.ToolCall{Name: "echo", Arguments: `{"value":"x"}`, CallID: "call_1"})
+	state := &gopherai.RunState{
+		RunID:     "run_1",
+		Messages:  []gopherai.Message{gopherai.NewUserMessage("go")},
+		Response:  &response,
+		Turns:     1,
+		ToolCalls: 1,
+		Usage:     gopherai.Usage{Model: "gpt-4.1", InputTokens: 100, OutputTokens: 10},
+	}
+	if err := checkpointer.Save(ctx, state); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	state.Turns = 2
+	if err := checkpointer.Save(ctx, state); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	loaded, err := checkpointer.Load(ctx, "run_1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loaded.Turns != 2 || loaded.Usage.TotalTokens() != 110 || len(loaded.Messages) != 1 {
+		t.Errorf("expected the latest checkpoint, got %+v", loaded)
+	}
+	if loaded.Response == nil || loaded.Response.ToolCalls()[0].CallID != "call_1" {
+		t.Errorf("expected the response with its tool call, got %+v", loaded.Response)
+	}
+
+	if err := checkpointer.Delete(ctx, "run_1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := checkpointer.Delete(ctx, "run_1"); err != nil {
+		t.Errorf("expected deleting a missing checkpoint to succeed, got %v", err)
+	}
+	if _, err := checkpointer.Load(ctx, "run_1"); !errors.Is(err, gopherai.ErrCheckpointNotFound) {
+		t.Errorf("expected ErrCheckpointNotFound after delete, got %v", err)
+	}
+}
+
+func TestMemoryCheckpointer_SavesAndLoadsCheckpoints(t *testing.T) {
+	testCheckpointer(t, gopherai.NewMemoryCheckpointer())
+}
+
+func TestFileCheckpointer_SavesAndLoadsCheckpoints(t *testing.T) {
+	checkpointer, err := gopherai.NewFileCheckpointer(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testCheckpointer(t, checkpointer)
+}
+
+func TestFileCheckpointer_RejectsInvalidRunID(t *testing.T) {
+	checkpointer, err := gopherai.NewFileCheckpointer(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := checkpointer.Save(context.Background(), &gopherai.RunState{RunID: "../escape"}); err == nil {
+		t.Error("expected an error for an invalid run ID")
+	}
+}
+
+func TestResumeRun_ContinuesWithoutReplayingCompletedTools(t *testing.T) {
+	var calls int
+	tool := gopherai.NewTool("echo", "echoes the value", func(p limitTestParams) (string, error) {
+		calls++
+		return p.Value, nil
+	})
+	provider := meteredProvider()
+	provider.turns = slices.Insert(provider.turns, 1, mockTurn{err: errors.New("pod restarted")})
+	checkpointer := gopherai.NewMemoryCheckpointer()
+	agent := gopherai.NewAgent(provider,
+		gopherai.WithTools(tool),
+		gopherai.WithCheckpointer(checkpointer),
+	)
+
+	ctx := gopherai.ContextWithRunID(context.Background(), "job-42")
+	if _, err := agent.Run(ctx, "go"); err == nil {
+		t.Fatal("expected the run to fail")
+	}
+
+	result, err := agent.ResumeRun(context.Background(), "job-42")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Text != "done" || result.RunID != "job-42" {
+		t.Errorf("expected run job-42 to complete with 'done', got %q from %s", result.Text, result.RunID)
+	}
+	if calls != 1 {
+		t.Errorf("expected the tool to run once, got %d calls", calls)
+	}
+	if len(result.MessageHistory()) != 4 || len(result.Turns) != 2 {
+		t.Errorf("expected 4 messages and 2 turns, got %d and %d", len(result.MessageHistory()), len(result.Turns))
+	}
+	if _, err := checkpointer.Load(context.Background(), "job-42"); !errors.Is(err, gopherai.ErrCheckpointNotFound) {
+		t.Errorf("expected the checkpoint of the completed run to be deleted, got %v", err)
+	}
+}
+
+func TestResumeRun_RunsToolsOfCheckpointedResponse(t *testing.T) {
+	fail := true
+	var calls int
+	tool := gopherai.NewTool("echo", "echoes the value", func(p limitTestParams) (string, error) {
+		calls++
+		if fail {
+			return "", errors.New("connection reset")
+		}
+		return p.Value, nil
+	})
+	checkpointer := gopherai.NewMemoryCheckpointer()
+	agent := gopherai.NewAgent(meteredProvider(), gopherai.WithTools(tool), gopherai.WithCheckpointer(checkpointer))
+
+	ctx := gopherai.ContextWithRunID(context.Background(), "job-7")
+	if _, err := agent.Run(ctx, "go"); err == nil {
+		t.Fatal("expected the run to fail")
+	}
+	checkpoint, err := checkpointer.Load(context.Background(), "job-7")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if checkpoint.Response == nil || len(checkpoint.Messages) != 1 {
+		t.Fatalf("expected a checkpoint of the model response, got %+v", checkpoint)
+	}
+
+	fail = false
+	result, err := agent.ResumeRun(context.Background(), "job-7")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Text != "done" || calls != 2 {
+		t.Errorf("expected the tool to run again and the run to complete, got %q after %d calls", result.Text, calls)
+	}
+}
+
+func TestResumeRun_SkipsToolCallsCompletedBeforeTheCheckpoint(t *testing.T) {
+	echoed := make(chan struct{})
+	var echoCalls, flakyCalls atomic.Int32
+	fail := true
+	echo := gopherai.NewTool("echo", "echoes the value", func(p limitTestParams) (string, error) {
+		echoCalls.Add(1)
+		close(echoed)
+		return p.Value, nil
+	})
+	flaky := gopherai.NewTool("flaky", "fails until fixed", func(p limitTestParams) (string, error) {
+		flakyCalls.Add(1)
+		<-echoed
+		if fail {
+			return "", errors.New("connection reset")
+		}
+		return p.Value, nil
+	})
+	provider := &mockScriptedProvider{turns: []mockTurn{{calls: []gopherai.ToolCall{
+		{Name: "echo", Arguments: `{"value":"a"}`, CallID: "call_1"},
+		{Name: "flaky", Arguments: `{"value":"b"}`, CallID: "call_2"},
+	}}}}
+	checkpointer := gopherai.NewMemoryCheckpointer()
+	agent := gopherai.NewAgent(provider, gopherai.WithTools(echo, flaky), gopherai.WithCheckpointer(checkpointer))
+
+	ctx := gopherai.ContextWithRunID(context.Background(), "job-9")
+	if _, err := agent.Run(ctx, "go"); err == nil {
+		t.Fatal("expected the run to fail")
+	}
+	checkpoint, err := checkpointer.Load(context.Background(), "job-9")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(checkpoint.Results) != 1 || checkpoint.Results[0].CallID != "call_1" {
+		t.Fatalf("expected the checkpoint to hold the result of call_1, got %+v", checkpoint.Results)
+	}
+
+	fail = false
+	result, err := agent.ResumeRun(context.Background(), "job-9")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Text != "done" {
+		t.Errorf("expected 'done', got %q", result.Text)
+	}
+	if echoCalls.Load() != 1 || flakyCalls.Load() != 2 {
+		t.Errorf("expected echo to run once and flaky twice, got %d and %d", echoCalls.Load(), flakyCalls.Load())
+	}
+	results := result.MessageHistory()[2].ToolResults()
+	if len(results) != 2 || results[0].Output != "a" || results[1].Output != "b" {
+		t.Errorf("expected the results of both calls in order, got %+v", results)
+	}
+}
+
+func TestResumeRun_ReturnsInterruptedRunForApproval(t *testing.T) {
+	var received []string
+	agent := gopherai.NewAgent(meteredProvider(),
+		gopherai.WithTools(approvalTool(&received)),
+		gopherai.WithCheckpointer(gopherai.NewMemoryCheckpointer()),
+	)
+
+	interrupted, err := agent.Run(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := agent.ResumeRun(context.Background(), interrupted.RunID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Interrupted || len(result.PendingToolCalls()) != 1 || len(received) != 0 {
+		t.Errorf("expected the run to stay interrupted, got %+v", result)
+	}
+
+	completed, err := agent.Resume(context.Background(), result.State, []gopherai.ToolDecision{gopherai.Approve("call_1")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if completed.Text != "done" {
+		t.Errorf("expected 'done', got %q", completed.Text)
+	}
+}
+
+func TestResumeRun_RequiresCheckpoint(t *testing.T) {
+	agent := gopherai.NewAgent(meteredProvider(), gopherai.WithCheckpointer(gopherai.NewMemoryCheckpointer()))
+
+	if _, err := agent.ResumeRun(context.Background(), "missing"); !errors.Is(err, gopherai.ErrCheckpointNotFound) {
+		t.Errorf("expected ErrCheckpointNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package gopherai_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+)
+
+func TestNewAPIError_ClassifiesByStatus(t *testing.T) {
+	header := http.Header{"X-Request-Id": {"req_123"}, "Retry-After": {"2"}}
+
+	err := gopherai.NewAPIError("test", http.StatusTooManyRequests, header, []byte("slow down"))
+	if !errors.Is(err, gopherai.ErrRateLimited) {
+		t.Errorf("expected ErrRateLimited, got %v", err.Kind)
+	}
+	if !err.Retryable || err.RetryAfter != 2*time.Second {
+		t.Errorf("expected retryable error with delay, got %+v", err)
+	}
+	if err.RequestID != "req_123" || err.Message != "slow down" || string(err.Body) != "slow down" {
+		t.Errorf("unexpected error: %+v", err)
+	}
+
+	auth := gopherai.NewAPIError("test", http.StatusUnauthorized, http.Header{}, nil)
+	if !errors.Is(auth, gopherai.ErrAuth) || auth.Retryable {
+		t.Errorf("expected non-retryable ErrAuth, got %+v", auth)
+	}
+
+	bad := gopherai.NewAPIError("test", http.StatusBadRequest, http.Header{}, nil)
+	if bad.Kind != nil || bad.Retryable {
+		t.Errorf("expected unclassified non-retryable error, got %+v", bad)
+	}
+}
+
+func TestAPIError_MatchesThroughWrapping(t *testing.T) {
+	apiErr := &gopherai.APIError{Provider: "test", StatusCode: 400, Code: "too_long", Message: "too long", Kind: gopherai.ErrContextLengthExceeded}
+	err := fmt.Errorf("model call failed: %w", apiErr)
+
+	if !errors.Is(err, gopherai.ErrContextLengthExceeded) {
+		t.Error("expected ErrContextLengthExceeded to match")
+	}
+	var target *gopherai.APIError
+	if !errors.As(err, &target) || target.Code != "too_long" {
+		t.Errorf("expected to extract APIError, got %v", target)
+	}
+	if got := apiErr.Error(); got != "test API error (400 too_long): too long" {
+		t.Errorf("unexpected message: %q", got)
+	}
+}
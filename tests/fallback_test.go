@@ -0,0 +1,223 @@
+package gopherai_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+)
+
+func rateLimitError() error {
+	return &gopherai.APIError{Provider: "openai", StatusCode: 429, Retryable: true, Kind: gopherai.ErrRateLimited}
+}
+
+func TestFallbackProvider_UsesFirstProvider(t *testing.T) {
+	primary := &mockScriptedProvider{then: answeringTurns("primary", nil)}
+	secondary := &mockScriptedProvider{then: answeringTurns("secondary", nil)}
+	provider := gopherai.NewFallbackProvider().Add("openai", primary).Add("gemini", secondary)
+
+	result, err := gopherai.NewAgent(provider).Run(context.Background(), "hi")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Text != "primary" || result.Provider != "openai" {
+		t.Errorf("expected primary answer from openai, got %q from %q", result.Text, result.Provider)
+	}
+	if secondary.responses() != 0 {
+		t.Errorf("expected secondary provider not to be called, got %d calls", secondary.responses())
+	}
+}
+
+func TestFallbackProvider_FallsBackOnRateLimit(t *testing.T) {
+	primary := &mockScriptedProvider{turns: []mockTurn{{err: rateLimitError()}}}
+	secondary := &mockScriptedProvider{then: answeringTurns("secondary", nil)}
+	provider := gopherai.NewFallbackProvider().Add("openai", primary).Add("gemini", secondary)
+
+	history := []gopherai.Message{gopherai.NewUserMessage("earlier"), gopherai.NewAssistantMessage("reply")}
+	agent := gopherai.NewAgent(provider, gopherai.WithConversationHistory(history))
+
+	result, err := agent.Run(context.Background(), "hi")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Text != "secondary" || result.Provider != "gemini" {
+		t.Errorf("expected secondary answer from gemini, got %q from %q", result.Text, result.Provider)
+	}
+	if len(secondary.requests) != 1 || len(secondary.requests[0]) != 3 || secondary.requests[0][2].Text() != "hi" {
+		t.Errorf("expected the conversation to be passed to the secondary provider, got %+v", secondary.requests)
+	}
+}
+
+func TestFallbackProvider_DoesNotFallBackOnOtherErrors(t *testing.T) {
+	primary := &mockScriptedProvider{turns: []mockTurn{{err: &gopherai.APIError{Provider: "openai", StatusCode: 400, Kind: gopherai.ErrContextLengthExceeded}}}}
+	secondary := &mockScriptedProvider{then: answeringTurns("secondary", nil)}
+	provider := gopherai.NewFallbackProvider().Add("openai", primary).Add("gemini", secondary)
+
+	_, err := gopherai.NewAgent(provider).Run(context.Background(), "hi")
+	if !errors.Is(err, gopherai.ErrContextLengthExceeded) {
+		t.Fatalf("expected context length error, got %v", err)
+	}
+	if secondary.responses() != 0 {
+		t.Errorf("expected secondary provider not to be called, got %d calls", secondary.responses())
+	}
+}
+
+func TestFallbackProvider_ReturnsAllErrorsWhenEveryProviderFails(t *testing.T) {
+	provider := gopherai.NewFallbackProvider().
+		Add("openai", &mockScriptedProvider{turns: []mockTurn{{err: rateLimitError()}}}).
+		Add("gemini", &mockScriptedProvider{turns: []mockTurn{{err: &gopherai.APIError{Provider: "gemini", StatusCode: 503, Retryable: true}}}})
+
+	_, err := gopherai.NewAgent(provider).Run(context.Background(), "hi")
+	if !errors.Is(err, gopherai.ErrRateLimited) {
+		t.Fatalf("expected rate limit error, got %v", err)
+	}
+	var apiErr *gopherai.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected an APIError, got %v", err)
+	}
+}
+
+func TestFallbackProvider_SetFallbackOn(t *testing.T) {
+	primary := &mockScriptedProvider{turns: []mockTurn{{err: errors.New("boom")}}}
+	secondary := &mockScriptedProvider{then: answeringTurns("secondary", nil)}
+	provider := gopherai.NewFallbackProvider().
+		Add("openai", primary).
+		Add("gemini", secondary).
+		SetFallbackOn(func(error) bool { return true })
+
+	result, err := gopherai.NewAgent(provider).Run(context.Background(), "hi")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Provider != "gemini" {
+		t.Errorf("expected gemini to answer, got %q", result.Provider)
+	}
+}
+
+func TestFallbackProvider_StreamFallsBackOnErrorEvent(t *testing.T) {
+	primary := &mockScriptedProvider{turns: []mockTurn{{events: []gopherai.StreamEvent{
+		{Type: gopherai.StreamEventTypeError, Error: rateLimitError()},
+	}}}}
+	secondary := &mockScriptedProvider{turns: []mockTurn{{events: []gopherai.StreamEvent{
+		{Type: gopherai.StreamEventTypeTextDelta, Delta: "from "},
+		{Type: gopherai.StreamEventTypeTextDelta, Delta: "gemini"},
+		{Type: gopherai.StreamEventTypeTextDone, Text: "from gemini"},
+		{Type: gopherai.StreamEventTypeDone},
+	}}}}
+	provider := gopherai.NewFallbackProvider().Add("openai", primary).Add("gemini", secondary)
+
+	events, err := gopherai.NewAgent(provider).RunStream(context.Background(), "hi")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var text, respondedBy string
+	for event := range events {
+		switch event.Type {
+		case gopherai.StreamEventTypeError:
+			t.Fatalf("unexpected error event: %v", event.Error)
+		case gopherai.StreamEventTypeTextDelta:
+			text += event.Delta
+		case gopherai.StreamEventTypeDone:
+			respondedBy = event.Provider
+		}
+	}
+	if text != "from gemini" {
+		t.Errorf("expected streamed text from gemini, got %q", text)
+	}
+	if respondedBy != "gemini" {
+		t.Errorf("expected the done event to name gemini, got %q", respondedBy)
+	}
+}
+
+func TestFallbackProvider_StreamsFromNonStreamingProvider(t *testing.T) {
+	primary := &mockScriptedProvider{turns: []mockTurn{{err: rateLimitError()}}}
+	provider := gopherai.NewFallbackProvider().
+		Add("openai", primary).
+		Add("plain", &mockProvider{text: "plain answer"})
+
+	events, err := gopherai.NewAgent(provider).RunStream(context.Background(), "hi")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var text string
+	for event := range events {
+		if event.Type == gopherai.StreamEventTypeError {
+			t.Fatalf("unexpected error event: %v", event.Error)
+		}
+		if event.Type == gopherai.StreamEventTypeTextDone {
+			text = event.Text
+		}
+	}
+	if text != "plain answer" {
+		t.Errorf("expected text from the non-streaming provider, got %q", text)
+	}
+}
+
+// mockBlockingStreamProvider streams events without buffering, as providers
+// reading from a connection do, and closes finished when its stream ends. When
+// err is set, the stream starts with an error event.
+type mockBlockingStreamProvider struct {
+	mockProvider
+	finished chan struct{}
+	err      error
+}
+
+func (m *mockBlockingStreamProvider) CreateResponseStream(_ context.Context, _ any) (<-chan gopherai.StreamEvent, error) {
+	events := make(chan gopherai.StreamEvent)
+	go func() {
+		defer close(m.finished)
+		defer close(events)
+		if m.err != nil {
+			events <- gopherai.StreamEvent{Type: gopherai.StreamEventTypeError, Error: m.err}
+		}
+		for range 500 {
+			events <- gopherai.StreamEvent{Type: gopherai.StreamEventTypeTextDelta, Delta: "x"}
+		}
+		events <- gopherai.StreamEvent{Type: gopherai.StreamEventTypeDone}
+	}()
+	return events, nil
+}
+
+func TestFallbackProvider_DrainsStreamAfterCancellation(t *testing.T) {
+	inner := &mockBlockingStreamProvider{finished: make(chan struct{})}
+	provider := gopherai.NewFallbackProvider().Add("openai", inner)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil)
+	if _, err := provider.CreateResponseStream(ctx, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cancel()
+
+	select {
+	case <-inner.finished:
+	case <-time.After(time.Second):
+		t.Fatal("expected the provider's stream to be drained after cancellation")
+	}
+}
+
+func TestFallbackProvider_DrainsStreamThatFailsOver(t *testing.T) {
+	primary := &mockBlockingStreamProvider{finished: make(chan struct{}), err: rateLimitError()}
+	secondary := &mockScriptedProvider{then: answeringTurns("secondary", nil)}
+	provider := gopherai.NewFallbackProvider().Add("openai", primary).Add("gemini", secondary)
+
+	events, err := gopherai.NewAgent(provider).RunStream(context.Background(), "hi")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for event := range events {
+		if event.Type == gopherai.StreamEventTypeError {
+			t.Fatalf("unexpected error event: %v", event.Error)
+		}
+	}
+
+	select {
+	case <-primary.finished:
+	case <-time.After(time.Second):
+		t.Fatal("expected the stream of the failed provider to be drained")
+	}
+}
@@ -0,0 +1,87 @@
+package gemini_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+	"github.com/marti-jorda-roca/gopher-ai/gopherai/gemini"
+)
+
+func TestCreateResponse_ReturnsTypedAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error":{"code":429,"message":"Resource has been exhausted (e.g. check quota).","status":"RESOURCE_EXHAUSTED"}}`))
+	}))
+	defer server.Close()
+
+	provider := gemini.NewProvider("test-key").SetBaseURL(server.URL)
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil)
+
+	_, err := provider.CreateResponse(context.Background(), req)
+	var apiErr *gopherai.APIError
+	if !errors.As(err, &apiErr) || !errors.Is(err, gopherai.ErrRateLimited) {
+		t.Fatalf("expected typed rate limit error, got %v", err)
+	}
+	if apiErr.Provider != "gemini" || apiErr.Code != "RESOURCE_EXHAUSTED" || !apiErr.Retryable {
+		t.Errorf("unexpected error: %+v", apiErr)
+	}
+}
+
+func TestCreateResponseStream_ParsesArrayErrorBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`[{"error":{"code":400,"message":"The input token count (2000000) exceeds the maximum number of tokens allowed (1048576).","status":"INVALID_ARGUMENT"}}]`))
+	}))
+	defer server.Close()
+
+	provider := gemini.NewProvider("test-key").SetBaseURL(server.URL)
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil)
+
+	_, err := provider.CreateResponseStream(context.Background(), req)
+	if !errors.Is(err, gopherai.ErrContextLengthExceeded) {
+		t.Fatalf("expected ErrContextLengthExceeded, got %v", err)
+	}
+}
+
+func TestCreateResponse_ReturnsContentFilteredErrorForBlockedPrompt(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"promptFeedback":{"blockReason":"SAFETY","safetyRatings":[{"category":"HARM_CATEGORY_DANGEROUS_CONTENT","probability":"HIGH"}]}}`))
+	}))
+	defer server.Close()
+
+	provider := gemini.NewProvider("test-key").SetBaseURL(server.URL)
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil)
+
+	_, err := provider.CreateResponse(context.Background(), req)
+	var apiErr *gopherai.APIError
+	if !errors.As(err, &apiErr) || !errors.Is(err, gopherai.ErrContentFiltered) {
+		t.Fatalf("expected content filtered error, got %v", err)
+	}
+	if apiErr.Code != "SAFETY" {
+		t.Errorf("expected code SAFETY, got %q", apiErr.Code)
+	}
+}
+
+func TestParseGeminiStream_ReturnsContentFilteredErrorForSafetyFinish(t *testing.T) {
+	sseData := "data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"Sure\"}]}}]}\n\n" +
+		"data: {\"candidates\":[{\"content\":{\"parts\":[]},\"finishReason\":\"SAFETY\"}]}\n\n"
+
+	var streamErr error
+	for event := range gemini.ParseGeminiStreamForTest(strings.NewReader(sseData)) {
+		if event.Type == gopherai.StreamEventTypeError {
+			streamErr = event.Error
+		}
+	}
+
+	if !errors.Is(streamErr, gopherai.ErrContentFiltered) {
+		t.Errorf("expected content filtered error, got %v", streamErr)
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/marti-jorda-roca/gopher-ai/gopherai"
 	"github.com/marti-jorda-roca/gopher-ai/gopherai/gemini"
+	"github.com/marti-jorda-roca/gopher-ai/gopherai/openai"
 )
 
 func TestConvertTool_CreatesFunctionDeclaration(t *testing.T) {
@@ -123,7 +124,7 @@ func TestExtractText_ReturnsEmptyForInvalidType(t *testing.T) {
 func TestBuildRequest_CreatesRequestWithStringInput(t *testing.T) {
 	provider := gemini.NewProvider("test-key")
 
-	req := provider.BuildRequest("test message", "", []any{})
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("test message")}, "", []any{})
 	genReq, ok := req.(*gemini.GenerateContentRequest)
 	if !ok {
 		t.Fatal("expected GenerateContentRequest")
@@ -146,29 +147,30 @@ func TestBuildRequest_CreatesRequestWithStringInput(t *testing.T) {
 	}
 }
 
-func TestBuildRequest_CreatesRequestWithSliceInput(t *testing.T) {
+func TestBuildRequest_CreatesRequestWithMessageHistory(t *testing.T) {
 	provider := gemini.NewProvider("test-key")
 
-	input := []any{
-		"user message",
-		gemini.Content{
-			Role:  "model",
-			Parts: []gemini.Part{{Text: "model response"}},
-		},
+	messages := []gopherai.Message{
+		gopherai.NewUserMessage("user message"),
+		gopherai.NewAssistantMessage("model response"),
 	}
 
-	req := provider.BuildRequest(input, "", []any{})
+	req := provider.BuildRequest(messages, "", []any{})
 	genReq := req.(*gemini.GenerateContentRequest)
 
 	if len(genReq.Contents) != 2 {
-		t.Errorf("expected 2 contents, got %d", len(genReq.Contents))
+		t.Fatalf("expected 2 contents, got %d", len(genReq.Contents))
+	}
+
+	if genReq.Contents[1].Role != "model" {
+		t.Errorf("expected role 'model', got '%s'", genReq.Contents[1].Role)
 	}
 }
 
 func TestBuildRequest_AddsSystemInstructionWhenProvided(t *testing.T) {
 	provider := gemini.NewProvider("test-key")
 
-	req := provider.BuildRequest("test", "You are a helpful assistant", []any{})
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("test")}, "You are a helpful assistant", []any{})
 	genReq := req.(*gemini.GenerateContentRequest)
 
 	if genReq.SystemInstruction == nil {
@@ -184,26 +186,25 @@ func TestBuildRequest_AddsSystemInstructionWhenProvided(t *testing.T) {
 	}
 }
 
-func TestCreateFunctionCallInput_CreatesModelContent(t *testing.T) {
-	provider := gemini.NewProvider("test-key")
+func TestConvertMessages_CreatesModelContentForToolCall(t *testing.T) {
 	call := gopherai.ToolCall{
 		Name:      "test_func",
 		Arguments: `{"key":"value"}`,
 		CallID:    "call_123",
 	}
 
-	result := provider.CreateFunctionCallInput(call)
-	content, ok := result.(gemini.Content)
-	if !ok {
-		t.Fatal("expected result to be Content")
+	contents := gemini.ConvertMessages([]gopherai.Message{gopherai.NewAssistantMessage("", call)})
+	if len(contents) != 1 {
+		t.Fatalf("expected 1 content, got %d", len(contents))
 	}
+	content := contents[0]
 
 	if content.Role != "model" {
 		t.Errorf("expected role 'model', got '%s'", content.Role)
 	}
 
 	if len(content.Parts) != 1 {
-		t.Errorf("expected 1 part, got %d", len(content.Parts))
+		t.Fatalf("expected 1 part, got %d", len(content.Parts))
 	}
 
 	if content.Parts[0].FunctionCall == nil {
@@ -213,49 +214,80 @@ func TestCreateFunctionCallInput_CreatesModelContent(t *testing.T) {
 	if content.Parts[0].FunctionCall.Name != "test_func" {
 		t.Errorf("expected function name 'test_func', got '%s'", content.Parts[0].FunctionCall.Name)
 	}
+
+	if content.Parts[0].FunctionCall.Args["key"] != "value" {
+		t.Errorf("expected arg 'value', got '%v'", content.Parts[0].FunctionCall.Args["key"])
+	}
 }
 
-func TestCreateFunctionCallOutput_CreatesUserContent(t *testing.T) {
-	provider := gemini.NewProvider("test-key")
-	result := provider.CreateFunctionCallOutput("call_123", `{"result":"success"}`)
+func TestConvertMessages_CreatesUserContentForToolResult(t *testing.T) {
+	result := gopherai.ToolResult{CallID: "call_123", Name: "test_func", Output: `{"result":"success"}`}
 
-	content, ok := result.(gemini.Content)
-	if !ok {
-		t.Fatal("expected result to be Content")
+	contents := gemini.ConvertMessages([]gopherai.Message{gopherai.NewToolResultMessage(result)})
+	if len(contents) != 1 {
+		t.Fatalf("expected 1 content, got %d", len(contents))
 	}
+	content := contents[0]
 
 	if content.Role != "user" {
 		t.Errorf("expected role 'user', got '%s'", content.Role)
 	}
 
 	if len(content.Parts) != 1 {
-		t.Errorf("expected 1 part, got %d", len(content.Parts))
+		t.Fatalf("expected 1 part, got %d", len(content.Parts))
 	}
 
 	if content.Parts[0].FunctionResponse == nil {
 		t.Fatal("expected FunctionResponse to be set")
 	}
 
-	if content.Parts[0].FunctionResponse.Name != "call_123" {
-		t.Errorf("expected function name 'call_123', got '%s'", content.Parts[0].FunctionResponse.Name)
+	if content.Parts[0].FunctionResponse.Name != "test_func" {
+		t.Errorf("expected function name 'test_func', got '%s'", content.Parts[0].FunctionResponse.Name)
+	}
+
+	if content.Parts[0].FunctionResponse.Response["result"] != "success" {
+		t.Errorf("expected response result 'success', got '%v'", content.Parts[0].FunctionResponse.Response["result"])
 	}
 }
 
-func TestCreateAssistantMessage_CreatesModelContent(t *testing.T) {
-	provider := gemini.NewProvider("test-key")
-	result := provider.CreateAssistantMessage("Response text")
+func TestConvertMessages_NamesFunctionResponseFromPrecedingCall(t *testing.T) {
+	messages := []gopherai.Message{
+		gopherai.NewAssistantMessage("", gopherai.ToolCall{Name: "get_weather", Arguments: `{}`, CallID: "call_1"}),
+		gopherai.NewToolResultMessage(gopherai.ToolResult{CallID: "call_1", Output: "sunny"}),
+	}
 
-	content, ok := result.(gemini.Content)
-	if !ok {
-		t.Fatal("expected result to be Content")
+	contents := gemini.ConvertMessages(messages)
+	if len(contents) != 2 {
+		t.Fatalf("expected 2 contents, got %d", len(contents))
+	}
+
+	response := contents[1].Parts[0].FunctionResponse
+	if response == nil {
+		t.Fatal("expected FunctionResponse to be set")
 	}
 
+	if response.Name != "get_weather" {
+		t.Errorf("expected function name 'get_weather', got '%s'", response.Name)
+	}
+
+	if response.Response["result"] != "sunny" {
+		t.Errorf("expected wrapped result 'sunny', got '%v'", response.Response["result"])
+	}
+}
+
+func TestConvertMessages_CreatesModelContentForAssistantText(t *testing.T) {
+	contents := gemini.ConvertMessages([]gopherai.Message{gopherai.NewAssistantMessage("Response text")})
+	if len(contents) != 1 {
+		t.Fatalf("expected 1 content, got %d", len(contents))
+	}
+	content := contents[0]
+
 	if content.Role != "model" {
 		t.Errorf("expected role 'model', got '%s'", content.Role)
 	}
 
 	if len(content.Parts) != 1 {
-		t.Errorf("expected 1 part, got %d", len(content.Parts))
+		t.Fatalf("expected 1 part, got %d", len(content.Parts))
 	}
 
 	if content.Parts[0].Text != "Response text" {
@@ -358,8 +390,8 @@ func TestParseGeminiStream_ParsesToolCallEvents(t *testing.T) {
 		t.Errorf("expected name 'get_weather', got '%s'", toolCallEvent.ToolCall.Name)
 	}
 
-	if toolCallEvent.ToolCall.CallID != "get_weather" {
-		t.Errorf("expected CallID 'get_weather', got '%s'", toolCallEvent.ToolCall.CallID)
+	if !strings.HasPrefix(toolCallEvent.ToolCall.CallID, "call_") {
+		t.Errorf("expected a generated CallID, got '%s'", toolCallEvent.ToolCall.CallID)
 	}
 
 	if toolCallEvent.ToolCall.Arguments != `{"location":"NYC"}` {
@@ -410,3 +442,178 @@ func TestParseGeminiStream_NoTextDoneIfNoText(t *testing.T) {
 		t.Error("expected no TextDone event when there's no text")
 	}
 }
+
+func TestToMessages_ConvertsContentsToMessages(t *testing.T) {
+	contents := []gemini.Content{
+		{Role: "user", Parts: []gemini.Part{{Text: "question"}}},
+		{Role: "model", Parts: []gemini.Part{
+			{FunctionCall: &gemini.FunctionCall{Name: "lookup", Args: map[string]any{"q": "x"}}},
+		}},
+		{Role: "user", Parts: []gemini.Part{
+			{FunctionResponse: &gemini.FunctionResponse{Name: "lookup", Response: map[string]any{"result": "one"}}},
+		}},
+		{Role: "model", Parts: []gemini.Part{{Text: "answer"}}},
+	}
+
+	messages := gemini.ToMessages(contents)
+	if len(messages) != 4 {
+		t.Fatalf("expected 4 messages, got %d", len(messages))
+	}
+
+	calls := messages[1].ToolCalls()
+	if len(calls) != 1 || calls[0].CallID == "" {
+		t.Fatalf("expected 1 tool call with a generated ID, got %v", calls)
+	}
+
+	if calls[0].Arguments != `{"q":"x"}` {
+		t.Errorf("expected arguments '{\"q\":\"x\"}', got '%s'", calls[0].Arguments)
+	}
+
+	results := messages[2].ToolResults()
+	if messages[2].Role != gopherai.RoleTool || len(results) != 1 {
+		t.Fatalf("expected tool message with 1 result, got %v", messages[2])
+	}
+
+	if results[0].CallID != calls[0].CallID {
+		t.Errorf("expected result to match call '%s', got '%s'", calls[0].CallID, results[0].CallID)
+	}
+
+	if results[0].Output != "one" {
+		t.Errorf("expected unwrapped output 'one', got '%s'", results[0].Output)
+	}
+}
+
+func TestConvertMessages_AcceptsHistoryFromOpenAI(t *testing.T) {
+	messages := openai.ToMessages([]openai.InputItem{
+		{Type: "message", Role: "user", Content: "weather?"},
+		{Type: "function_call", CallID: "call_abc", Name: "get_weather", Arguments: `{"city":"Paris"}`},
+		openai.NewFunctionCallOutput("call_abc", `{"temp":22}`),
+		{Type: "message", Role: "assistant", Content: "It is 22 degrees."},
+	})
+
+	contents := gemini.ConvertMessages(messages)
+	if len(contents) != 4 {
+		t.Fatalf("expected 4 contents, got %d", len(contents))
+	}
+
+	call := contents[1].Parts[0].FunctionCall
+	if call == nil || call.Name != "get_weather" || call.Args["city"] != "Paris" {
+		t.Errorf("expected get_weather call for Paris, got %v", call)
+	}
+
+	response := contents[2].Parts[0].FunctionResponse
+	if response == nil || response.Name != "get_weather" {
+		t.Fatalf("expected get_weather response, got %v", response)
+	}
+
+	if response.Response["temp"] != float64(22) {
+		t.Errorf("expected temp 22, got %v", response.Response["temp"])
+	}
+}
+
+func TestConvertMessages_WrapsToolErrorsInErrorField(t *testing.T) {
+	result := gopherai.ToolResult{CallID: "call_1", Name: "test_func", Output: "tool test_func failed: boom", IsError: true}
+
+	contents := gemini.ConvertMessages([]gopherai.Message{gopherai.NewToolResultMessage(result)})
+	response := contents[0].Parts[0].FunctionResponse
+	if response == nil {
+		t.Fatal("expected FunctionResponse to be set")
+	}
+
+	if response.Response["error"] != "tool test_func failed: boom" {
+		t.Errorf("expected error field, got %v", response.Response)
+	}
+}
+
+func TestSetOutputSchema_SetsJSONResponseSchema(t *testing.T) {
+	provider := gemini.NewProvider("test-key")
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", []any{})
+
+	err := provider.SetOutputSchema(req, gopherai.OutputSchema{
+		Name: "answer",
+		Schema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"tags": map[string]any{
+					"type":                 "array",
+					"items":                map[string]any{"type": "string"},
+					"additionalProperties": false,
+				},
+			},
+			"required":             []string{"tags"},
+			"additionalProperties": false,
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	config := req.(*gemini.GenerateContentRequest).GenerationConfig
+	if config.ResponseMIMEType != "application/json" {
+		t.Errorf("expected mime type 'application/json', got '%s'", config.ResponseMIMEType)
+	}
+	tags := config.ResponseSchema.Properties["tags"]
+	if tags == nil || tags.Items == nil || tags.Items.Type != "string" {
+		t.Errorf("expected tags with string items, got %+v", tags)
+	}
+	if len(config.ResponseSchema.Required) != 1 {
+		t.Errorf("expected required to be kept, got %v", config.ResponseSchema.Required)
+	}
+}
+
+func TestParseGeminiStream_EmitsUsageWithThoughts(t *testing.T) {
+	sseData := `data: {"candidates":[{"content":{"parts":[{"text":"Hi"}]}}],"modelVersion":"gemini-2.5-flash"}
+
+data: {"candidates":[{"content":{"parts":[{"text":"!"}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":50,"cachedContentTokenCount":10,"candidatesTokenCount":4,"thoughtsTokenCount":30,"totalTokenCount":84},"modelVersion":"gemini-2.5-flash"}
+
+`
+	var usage *gopherai.Usage
+	for event := range gemini.ParseGeminiStreamForTest(strings.NewReader(sseData)) {
+		if event.Type == gopherai.StreamEventTypeUsage {
+			usage = event.Usage
+		}
+	}
+
+	want := gopherai.Usage{Model: "gemini-2.5-flash", InputTokens: 50, CachedInputTokens: 10, OutputTokens: 34, ReasoningTokens: 30}
+	if usage == nil || *usage != want {
+		t.Errorf("expected usage %+v, got %+v", want, usage)
+	}
+}
+
+func TestParseGeminiStream_GivesParallelCallsToTheSameFunctionDistinctIDs(t *testing.T) {
+	sseData := `data: {"candidates":[{"content":{"parts":[{"functionCall":{"name":"send_email","args":{"to":"a"}}},{"functionCall":{"name":"send_email","args":{"to":"b"}}}],"role":"model"},"finishReason":"STOP"}]}
+
+`
+	var ids []string
+	for event := range gemini.ParseGeminiStreamForTest(strings.NewReader(sseData)) {
+		if event.Type == gopherai.StreamEventTypeToolCall {
+			ids = append(ids, event.ToolCall.CallID)
+		}
+	}
+
+	if len(ids) != 2 || ids[0] == ids[1] {
+		t.Errorf("expected 2 distinct call IDs, got %v", ids)
+	}
+}
+
+func TestExtractToolCalls_GivesCallsIDsUniqueAcrossResponses(t *testing.T) {
+	provider := gemini.NewProvider("test-key")
+	resp := &gemini.GenerateContentResponse{Candidates: []gemini.Candidate{{
+		Content: gemini.Content{Role: "model", Parts: []gemini.Part{
+			{FunctionCall: &gemini.FunctionCall{Name: "send_email", Args: map[string]any{"to": "a"}}},
+		}},
+	}}}
+
+	first, err := provider.ExtractToolCalls(resp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := provider.ExtractToolCalls(resp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if first[0].CallID == "" || first[0].CallID == second[0].CallID {
+		t.Errorf("expected distinct call IDs across responses, got %q and %q", first[0].CallID, second[0].CallID)
+	}
+}
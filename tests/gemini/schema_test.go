@@ -0,0 +1,342 @@
+package gemini_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+	"github.com/marti-jorda-roca/gopher-ai/gopherai/gemini"
+)
+
+type schemaTestAddress struct {
+	Street string  `json:"street" minLength:"1"`
+	Zip    *string `json:"zip"`
+}
+
+type schemaTestNode struct {
+	Name     string           `json:"name"`
+	Children []schemaTestNode `json:"children"`
+}
+
+type schemaTestParams struct {
+	Addresses []schemaTestAddress `json:"addresses" maxItems:"5"`
+	Scores    map[string]int      `json:"scores"`
+	Email     string              `json:"email" format:"email"`
+	When      string              `json:"when" format:"date-time"`
+	Level     int                 `json:"level" enum:"1,2,3"`
+	Tree      schemaTestNode      `json:"tree"`
+}
+
+func convertTestParams(t *testing.T) *gemini.Schema {
+	t.Helper()
+	tool := gopherai.NewTool("test", "description", func(_ schemaTestParams) (string, error) {
+		return "", nil
+	})
+	provider := gemini.NewProvider("test-key")
+	return provider.ConvertTool(tool).(gemini.FunctionDeclaration).Parameters
+}
+
+func TestConvertSchema_KeepsObjectsInsideArrays(t *testing.T) {
+	params := convertTestParams(t)
+
+	addresses := params.Properties["addresses"]
+	if addresses.Type != "array" || addresses.MaxItems == nil || *addresses.MaxItems != 5 {
+		t.Fatalf("unexpected addresses schema: %+v", addresses)
+	}
+	item := addresses.Items
+	if item == nil || item.Type != "object" {
+		t.Fatalf("expected object items, got %+v", item)
+	}
+	if item.Properties["street"] == nil || item.Properties["zip"] == nil {
+		t.Errorf("expected nested properties, got %+v", item.Properties)
+	}
+	if len(item.Required) != 1 || item.Required[0] != "street" {
+		t.Errorf("expected nested required [street], got %v", item.Required)
+	}
+	if minLength := item.Properties["street"].MinLength; minLength == nil || *minLength != 1 {
+		t.Errorf("expected minLength 1 on street, got %v", minLength)
+	}
+}
+
+func TestConvertSchema_TranslatesUnsupportedKeywords(t *testing.T) {
+	params := convertTestParams(t)
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, keyword := range []string{"additionalProperties", "$ref", "$defs"} {
+		if strings.Contains(string(data), keyword) {
+			t.Errorf("expected %s to be translated, got %s", keyword, data)
+		}
+	}
+
+	scores := params.Properties["scores"]
+	if scores.Type != "array" || scores.Items == nil || scores.Items.Properties["value"].Type != "integer" {
+		t.Errorf("expected map to become an array of entries, got %+v", scores)
+	}
+
+	email := params.Properties["email"]
+	if email.Format != "" || !strings.Contains(email.Description, "email") {
+		t.Errorf("expected unsupported format to be described, got %+v", email)
+	}
+	if params.Properties["when"].Format != "date-time" {
+		t.Errorf("expected date-time format to be kept, got %+v", params.Properties["when"])
+	}
+}
+
+func TestConvertSchema_InlinesRecursiveDefinitions(t *testing.T) {
+	params := convertTestParams(t)
+
+	tree := params.Properties["tree"]
+	if tree.Type != "object" || tree.Properties["name"] == nil {
+		t.Fatalf("expected inlined tree definition, got %+v", tree)
+	}
+	depth := 1
+	for node := tree; node.Properties["children"] != nil; node = node.Properties["children"].Items {
+		depth++
+	}
+	if depth != 3 {
+		t.Errorf("expected the tree unrolled to 3 levels, got %d", depth)
+	}
+	assertGeminiAccepts(t, params)
+}
+
+func TestConvertSchema_MapsNullableAndOneOf(t *testing.T) {
+	schema := gemini.ConvertSchema(map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"note": map[string]any{"type": []any{"string", "null"}},
+			"shape": map[string]any{
+				"oneOf": []any{
+					map[string]any{"type": "object", "properties": map[string]any{"kind": map[string]any{"const": "circle"}}},
+					map[string]any{"type": "object", "properties": map[string]any{"kind": map[string]any{"const": "square"}}},
+				},
+			},
+		},
+	})
+
+	note := schema.Properties["note"]
+	if note.Type != "string" || !note.Nullable {
+		t.Errorf("expected nullable string, got %+v", note)
+	}
+
+	shape := schema.Properties["shape"]
+	if len(shape.AnyOf) != 2 {
+		t.Fatalf("expected oneOf to become anyOf, got %+v", shape)
+	}
+	kind := shape.AnyOf[0].Properties["kind"]
+	if len(kind.Enum) != 1 || kind.Enum[0] != "circle" {
+		t.Errorf("expected const to become an enum, got %+v", kind)
+	}
+}
+
+// assertGeminiAccepts checks the rules Gemini enforces on schemas: every
+// schema has a type or anyOf, objects declare properties, arrays declare
+// items, and only keywords of the OpenAPI subset are used.
+func assertGeminiAccepts(t *testing.T, schema *gemini.Schema) {
+	t.Helper()
+	data, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	checkGeminiSchema(t, "schema", raw)
+}
+
+var geminiKeywords = map[string]bool{
+	"type": true, "format": true, "title": true, "description": true, "nullable": true,
+	"enum": true, "properties": true, "required": true, "items": true, "minItems": true,
+	"maxItems": true, "minLength": true, "maxLength": true, "pattern": true,
+	"minimum": true, "maximum": true, "default": true, "example": true, "anyOf": true,
+}
+
+func checkGeminiSchema(t *testing.T, path string, schema map[string]any) {
+	t.Helper()
+	for keyword := range schema {
+		if !geminiKeywords[keyword] {
+			t.Errorf("%s: unsupported keyword %s", path, keyword)
+		}
+	}
+	variants, _ := schema["anyOf"].([]any)
+	if schema["type"] == nil && len(variants) == 0 {
+		t.Errorf("%s: no type", path)
+	}
+	for i, variant := range variants {
+		checkGeminiSchema(t, fmt.Sprintf("%s.anyOf[%d]", path, i), variant.(map[string]any))
+	}
+
+	switch schema["type"] {
+	case "object":
+		properties, _ := schema["properties"].(map[string]any)
+		if len(properties) == 0 {
+			t.Errorf("%s: object without properties", path)
+		}
+		for name, property := range properties {
+			checkGeminiSchema(t, path+"."+name, property.(map[string]any))
+		}
+	case "array":
+		items, ok := schema["items"].(map[string]any)
+		if !ok {
+			t.Errorf("%s: array without items", path)
+			return
+		}
+		checkGeminiSchema(t, path+"[]", items)
+	}
+}
+
+type schemaTestEmpty struct{}
+
+type schemaTestOpenParams struct {
+	Labels   map[string]schemaTestAddress `json:"labels"`
+	Metadata map[string]any               `json:"metadata"`
+	Extra    any                          `json:"extra"`
+	Empty    schemaTestEmpty              `json:"empty"`
+	Name     string                       `json:"name"`
+}
+
+func TestConvertSchema_ProducesSchemasGeminiAccepts(t *testing.T) {
+	tool := gopherai.NewTool("test", "description", func(_ schemaTestOpenParams) (string, error) {
+		return "", nil
+	})
+	params := gemini.NewProvider("test-key").ConvertTool(tool).(gemini.FunctionDeclaration).Parameters
+
+	assertGeminiAccepts(t, params)
+	if labels := params.Properties["labels"]; labels.Type != "array" || labels.Items.Properties["value"].Properties["street"] == nil {
+		t.Errorf("expected map of objects to become entries, got %+v", labels)
+	}
+	if metadata := params.Properties["metadata"]; metadata.Type != "array" || metadata.Items.Properties["value"].Type != "string" {
+		t.Errorf("expected map of any values to become entries of JSON strings, got %+v", metadata)
+	}
+	if extra := params.Properties["extra"]; extra.Type != "string" || !strings.Contains(extra.Description, "JSON") {
+		t.Errorf("expected any value to become a JSON string, got %+v", extra)
+	}
+	if _, ok := params.Properties["empty"]; ok {
+		t.Errorf("expected closed object without properties to be left out, got %+v", params.Properties["empty"])
+	}
+	for _, name := range params.Required {
+		if name == "empty" {
+			t.Error("expected the left out property not to be required")
+		}
+	}
+}
+
+func TestConvertTool_OmitsParametersOfToolWithoutArguments(t *testing.T) {
+	tool := gopherai.NewTool("now", "returns the time", func(_ schemaTestEmpty) (string, error) {
+		return "", nil
+	})
+	declaration := gemini.NewProvider("test-key").ConvertTool(tool).(gemini.FunctionDeclaration)
+
+	data, err := json.Marshal(declaration)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(data), "parameters") {
+		t.Errorf("expected no parameters, got %s", data)
+	}
+}
+
+func TestCreateResponse_DecodesArgumentsIntoOriginalSchemaShape(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"functionCall":{"name":"test","args":{
+			"name":"x",
+			"labels":[{"key":"home","value":{"street":"Main St"}}],
+			"metadata":[{"key":"n","value":"42"},{"key":"tags","value":"[\"a\"]"}],
+			"extra":"{\"deep\":true}"
+		}}}]},"finishReason":"STOP"}]}`))
+	}))
+	defer server.Close()
+
+	var received schemaTestOpenParams
+	tool := gopherai.NewTool("test", "description", func(p schemaTestOpenParams) (string, error) {
+		received = p
+		return "", nil
+	})
+	provider := gemini.NewProvider("test-key").SetBaseURL(server.URL)
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", []any{provider.ConvertTool(tool)})
+
+	resp, err := provider.CreateResponse(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	calls, err := provider.ExtractToolCalls(resp)
+	if err != nil || len(calls) != 1 {
+		t.Fatalf("expected 1 call, got %v, %v", calls, err)
+	}
+	if _, err := tool.Handler(calls[0].Arguments); err != nil {
+		t.Fatalf("expected the decoded arguments to fit the tool, got %v from %s", err, calls[0].Arguments)
+	}
+
+	if received.Labels["home"].Street != "Main St" {
+		t.Errorf("expected labels decoded into a map, got %+v", received.Labels)
+	}
+	if received.Metadata["n"] != float64(42) || len(received.Metadata["tags"].([]any)) != 1 {
+		t.Errorf("expected metadata values decoded from JSON, got %+v", received.Metadata)
+	}
+	if extra, ok := received.Extra.(map[string]any); !ok || extra["deep"] != true {
+		t.Errorf("expected extra decoded from JSON, got %#v", received.Extra)
+	}
+}
+
+func TestCreateResponse_DecodesStructuredOutputIntoOriginalSchemaShape(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"{\"scores\":[{\"key\":\"a\",\"value\":1}]}"}]},"finishReason":"STOP"}]}`))
+	}))
+	defer server.Close()
+
+	provider := gemini.NewProvider("test-key").SetBaseURL(server.URL)
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil)
+	schema := gopherai.OutputSchema{Name: "scores", Schema: map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"scores": map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "integer"}},
+		},
+	}}
+	if err := provider.SetOutputSchema(req, schema); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err := provider.CreateResponse(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := provider.ExtractText(resp); text != `{"scores":{"a":1}}` {
+		t.Errorf("expected the map decoded, got %s", text)
+	}
+}
+
+func TestCreateResponse_FillsRequiredPropertiesLeftOutOfUnrolledRecursion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"functionCall":{"name":"tree","args":{
+			"name":"root","children":[{"name":"a","children":[{"name":"b"}]}]
+		}}}]},"finishReason":"STOP"}]}`))
+	}))
+	defer server.Close()
+
+	tool := gopherai.NewTool("tree", "description", func(n schemaTestNode) (string, error) {
+		return n.Children[0].Children[0].Name, nil
+	})
+	provider := gemini.NewProvider("test-key").SetBaseURL(server.URL)
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", []any{provider.ConvertTool(tool)})
+
+	resp, err := provider.CreateResponse(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	calls, _ := provider.ExtractToolCalls(resp)
+	output, err := tool.Handler(calls[0].Arguments)
+	if err != nil || output != "b" {
+		t.Errorf("expected the deepest node to validate, got %q, %v from %s", output, err, calls[0].Arguments)
+	}
+}
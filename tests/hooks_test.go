@@ -0,0 +1,246 @@
+package gopherai_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+)
+
+// recordingHooks records every hook call.
+type recordingHooks struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (h *recordingHooks) record(format string, args ...any) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.calls = append(h.calls, fmt.Sprintf(format, args...))
+}
+
+func (h *recordingHooks) OnRunStart(ctx context.Context, messages []gopherai.Message) error {
+	if _, ok := gopherai.RunInfoFromContext(ctx); !ok {
+		return errors.New("missing run info")
+	}
+	h.record("run_start %d", len(messages))
+	return nil
+}
+
+func (h *recordingHooks) OnRunEnd(_ context.Context, result *gopherai.RunResult) {
+	h.record("run_end %s", result.Text)
+}
+
+func (h *recordingHooks) OnRunError(_ context.Context, err error) {
+	h.record("run_error %v", errors.Is(err, gopherai.ErrLimitExceeded))
+}
+
+func (h *recordingHooks) BeforeModelCall(_ context.Context, call *gopherai.ModelCall) error {
+	h.record("before_model %d", call.Turn)
+	return nil
+}
+
+func (h *recordingHooks) AfterModelCall(_ context.Context, call *gopherai.ModelCall, response *gopherai.ModelResponse) error {
+	h.record("after_model %d %d", call.Turn, len(response.Message.ToolCalls()))
+	return nil
+}
+
+func (h *recordingHooks) BeforeToolCall(_ context.Context, call *gopherai.ToolCall) error {
+	h.record("before_tool %s", call.Name)
+	return nil
+}
+
+func (h *recordingHooks) AfterToolCall(_ context.Context, call gopherai.ToolCall, result *gopherai.ToolResult) error {
+	h.record("after_tool %s %s", call.Name, result.Output)
+	return nil
+}
+
+var wantHookCalls = []string{
+	"run_start 1",
+	"before_model 1",
+	"after_model 1 1",
+	"before_tool echo",
+	"after_tool echo x",
+	"before_model 2",
+	"after_model 2 0",
+	"run_end done",
+}
+
+func TestHooks_CalledAtEachPointOfRun(t *testing.T) {
+	hooks := &recordingHooks{}
+	agent := gopherai.NewAgent(meteredProvider(), gopherai.WithTools(echoTool()), gopherai.WithHooks(hooks))
+
+	if _, err := agent.Run(context.Background(), "go"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(hooks.calls, wantHookCalls) {
+		t.Errorf("expected hook calls %v, got %v", wantHookCalls, hooks.calls)
+	}
+}
+
+func TestHooks_CalledTheSameWayInRunStream(t *testing.T) {
+	hooks := &recordingHooks{}
+	agent := gopherai.NewAgent(meteredProvider(), gopherai.WithTools(echoTool()), gopherai.WithHooks(hooks))
+
+	events, err := agent.RunStream(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for event := range events {
+		if event.Type == gopherai.StreamEventTypeError {
+			t.Fatalf("unexpected error: %v", event.Error)
+		}
+	}
+
+	if !reflect.DeepEqual(hooks.calls, wantHookCalls) {
+		t.Errorf("expected hook calls %v, got %v", wantHookCalls, hooks.calls)
+	}
+}
+
+func TestHooks_OnRunErrorReceivesLimitErrors(t *testing.T) {
+	hooks := &recordingHooks{}
+	agent := gopherai.NewAgent(loopingProvider(1),
+		gopherai.WithTools(echoTool()),
+		gopherai.WithMaxTurns(1),
+		gopherai.WithHooks(hooks),
+	)
+
+	if _, err := agent.Run(context.Background(), "loop"); err == nil {
+		t.Fatal("expected limit error")
+	}
+
+	if last := hooks.calls[len(hooks.calls)-1]; last != "run_error true" {
+		t.Errorf("expected run_error last, got %v", hooks.calls)
+	}
+}
+
+// redactingHooks replaces secrets in tool outputs and final answers, and
+// blocks the tool named blocked.
+type redactingHooks struct {
+	gopherai.BaseHooks
+	blocked string
+}
+
+func (h redactingHooks) BeforeToolCall(_ context.Context, call *gopherai.ToolCall) error {
+	if call.Name == h.blocked {
+		return errors.New("tool not allowed")
+	}
+	call.Arguments = strings.ReplaceAll(call.Arguments, "x", "secret")
+	return nil
+}
+
+func (h redactingHooks) AfterToolCall(_ context.Context, _ gopherai.ToolCall, result *gopherai.ToolResult) error {
+	result.Output = strings.ReplaceAll(result.Output, "secret", "[redacted]")
+	return nil
+}
+
+func (h redactingHooks) AfterModelCall(_ context.Context, _ *gopherai.ModelCall, response *gopherai.ModelResponse) error {
+	if text := response.Message.Text(); text != "" {
+		response.Message = gopherai.NewAssistantMessage(strings.ToUpper(text), response.Message.ToolCalls()...)
+	}
+	return nil
+}
+
+func TestHooks_ModifyToolCallsAndResponses(t *testing.T) {
+	var received string
+	tool := gopherai.NewTool("echo", "echoes the value", func(p limitTestParams) (string, error) {
+		received = p.Value
+		return p.Value, nil
+	})
+	agent := gopherai.NewAgent(meteredProvider(), gopherai.WithTools(tool), gopherai.WithHooks(redactingHooks{}))
+
+	result, err := agent.Run(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if received != "secret" {
+		t.Errorf("expected the tool to receive modified arguments, got %q", received)
+	}
+	history := result.MessageHistory()
+	if args := history[1].ToolCalls()[0].Arguments; args != `{"value":"secret"}` {
+		t.Errorf("expected modified arguments in history, got %s", args)
+	}
+	if output := history[2].ToolResults()[0].Output; output != "[redacted]" {
+		t.Errorf("expected redacted tool output in history, got %q", output)
+	}
+	if result.Text != "DONE" {
+		t.Errorf("expected modified answer, got %q", result.Text)
+	}
+}
+
+func TestHooks_ModifiedArgumentsOfResumedCallsAreRecorded(t *testing.T) {
+	var received []string
+	agent := gopherai.NewAgent(meteredProvider(),
+		gopherai.WithTools(approvalTool(&received)),
+		gopherai.WithHooks(redactingHooks{}),
+	)
+
+	interrupted, err := agent.Run(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, err := agent.Resume(context.Background(), interrupted.State, []gopherai.ToolDecision{gopherai.Approve("call_1")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(received) != 1 || received[0] != "secret" {
+		t.Errorf("expected the tool to receive modified arguments, got %v", received)
+	}
+	if args := result.MessageHistory()[1].ToolCalls()[0].Arguments; args != `{"value":"secret"}` {
+		t.Errorf("expected modified arguments in history, got %s", args)
+	}
+}
+
+func TestHooks_BeforeToolCallErrorFollowsToolErrorPolicy(t *testing.T) {
+	called := false
+	tool := gopherai.NewTool("echo", "echoes the value", func(p limitTestParams) (string, error) {
+		called = true
+		return p.Value, nil
+	})
+	agent := gopherai.NewAgent(meteredProvider(),
+		gopherai.WithTools(tool),
+		gopherai.WithToolErrorPolicy(gopherai.ReportToolErrors()),
+		gopherai.WithHooks(redactingHooks{blocked: "echo"}),
+	)
+
+	result, err := agent.Run(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if called {
+		t.Error("expected the blocked tool not to run")
+	}
+	toolResult := result.MessageHistory()[2].ToolResults()[0]
+	if !toolResult.IsError || !strings.Contains(toolResult.Output, "tool not allowed") {
+		t.Errorf("expected the hook error to be reported, got %+v", toolResult)
+	}
+}
+
+type failingModelHooks struct {
+	gopherai.BaseHooks
+}
+
+func (failingModelHooks) BeforeModelCall(_ context.Context, call *gopherai.ModelCall) error {
+	if call.Turn > 1 {
+		return errors.New("policy violation")
+	}
+	return nil
+}
+
+func TestHooks_BeforeModelCallErrorAbortsRun(t *testing.T) {
+	agent := gopherai.NewAgent(meteredProvider(), gopherai.WithTools(echoTool()), gopherai.WithHooks(failingModelHooks{}))
+
+	_, err := agent.Run(context.Background(), "go")
+	if err == nil || err.Error() != "policy violation" {
+		t.Fatalf("expected policy violation, got %v", err)
+	}
+}
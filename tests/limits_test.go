@@ -0,0 +1,173 @@
+package gopherai_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+)
+
+type limitTestParams struct {
+	Value string `json:"value"`
+}
+
+func echoTool() gopherai.Tool {
+	return gopherai.NewTool("echo", "echoes the value", func(p limitTestParams) (string, error) {
+		return p.Value, nil
+	})
+}
+
+func TestRun_StopsAtDefaultMaxTurns(t *testing.T) {
+	provider := loopingProvider(1)
+	agent := gopherai.NewAgent(provider, gopherai.WithTools(echoTool()))
+
+	_, err := agent.Run(context.Background(), "loop")
+	if !errors.Is(err, gopherai.ErrLimitExceeded) {
+		t.Fatalf("expected ErrLimitExceeded, got %v", err)
+	}
+
+	if provider.responses() != 10 {
+		t.Errorf("expected 10 model calls, got %d", provider.responses())
+	}
+}
+
+func TestRun_MaxTurnsReturnsPartialResult(t *testing.T) {
+	provider := loopingProvider(1)
+	agent := gopherai.NewAgent(provider,
+		gopherai.WithTools(echoTool()),
+		gopherai.WithMaxTurns(3),
+	)
+
+	_, err := agent.Run(context.Background(), "loop")
+
+	var limitErr *gopherai.LimitExceededError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("expected LimitExceededError, got %v", err)
+	}
+
+	if limitErr.Limit != gopherai.LimitMaxTurns {
+		t.Errorf("expected limit 'max_turns', got '%s'", limitErr.Limit)
+	}
+
+	if limitErr.Turns != 3 || limitErr.ToolCalls != 3 {
+		t.Errorf("expected 3 turns and 3 tool calls, got %d and %d", limitErr.Turns, limitErr.ToolCalls)
+	}
+
+	if limitErr.Result == nil {
+		t.Fatal("expected partial result")
+	}
+
+	history := limitErr.Result.MessageHistory()
+	if len(history) != 7 {
+		t.Errorf("expected prompt and 3 tool exchanges in history, got %d messages", len(history))
+	}
+}
+
+func TestRun_MaxToolCallsStopsBeforeExceedingBatch(t *testing.T) {
+	provider := loopingProvider(2)
+	agent := gopherai.NewAgent(provider,
+		gopherai.WithTools(echoTool()),
+		gopherai.WithMaxToolCalls(3),
+	)
+
+	_, err := agent.Run(context.Background(), "loop")
+
+	var limitErr *gopherai.LimitExceededError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("expected LimitExceededError, got %v", err)
+	}
+
+	if limitErr.Limit != gopherai.LimitMaxToolCalls {
+		t.Errorf("expected limit 'max_tool_calls', got '%s'", limitErr.Limit)
+	}
+
+	if limitErr.ToolCalls != 2 {
+		t.Errorf("expected 2 executed tool calls, got %d", limitErr.ToolCalls)
+	}
+
+	history := limitErr.Result.MessageHistory()
+	last := history[len(history)-1]
+	if last.Role != gopherai.RoleTool {
+		t.Errorf("expected partial history to end with tool results, got role '%s'", last.Role)
+	}
+}
+
+func TestRun_TimeoutReturnsPartialResult(t *testing.T) {
+	provider := loopingProvider(1)
+	provider.delay = 20 * time.Millisecond
+	agent := gopherai.NewAgent(provider,
+		gopherai.WithTools(echoTool()),
+		gopherai.WithMaxTurns(0),
+		gopherai.WithTimeout(50*time.Millisecond),
+	)
+
+	_, err := agent.Run(context.Background(), "loop")
+
+	var limitErr *gopherai.LimitExceededError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("expected LimitExceededError, got %v", err)
+	}
+
+	if limitErr.Limit != gopherai.LimitTimeout {
+		t.Errorf("expected limit 'timeout', got '%s'", limitErr.Limit)
+	}
+
+	if len(limitErr.Result.MessageHistory()) < 1 {
+		t.Error("expected partial history to contain the prompt")
+	}
+}
+
+func TestRun_ParentCancellationIsNotALimit(t *testing.T) {
+	provider := loopingProvider(1)
+	provider.delay = time.Second
+	agent := gopherai.NewAgent(provider,
+		gopherai.WithTools(echoTool()),
+		gopherai.WithTimeout(time.Minute),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := agent.Run(ctx, "loop")
+	if err == nil {
+		t.Fatal("expected error for cancelled context")
+	}
+
+	if errors.Is(err, gopherai.ErrLimitExceeded) {
+		t.Errorf("expected cancellation not to be reported as a limit, got %v", err)
+	}
+}
+
+func TestRunStream_MaxTurnsEmitsLimitError(t *testing.T) {
+	callCount := 0
+	provider := &mockStreamProviderWithToolCalls{
+		tool:      echoTool(),
+		callCount: &callCount,
+	}
+	agent := gopherai.NewAgent(provider,
+		gopherai.WithTools(echoTool()),
+		gopherai.WithMaxTurns(1),
+	)
+
+	events, err := agent.RunStream(context.Background(), "loop")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var limitErr *gopherai.LimitExceededError
+	for event := range events {
+		if event.Type == gopherai.StreamEventTypeError {
+			errors.As(event.Error, &limitErr)
+		}
+	}
+
+	if limitErr == nil {
+		t.Fatal("expected limit error event")
+	}
+
+	if limitErr.Result == nil || len(limitErr.Result.MessageHistory()) != 3 {
+		t.Errorf("expected partial history with the tool exchange, got %v", limitErr.Result)
+	}
+}
@@ -0,0 +1,155 @@
+package gopherai_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+)
+
+func TestNewUserMessage_CreatesTextMessage(t *testing.T) {
+	msg := gopherai.NewUserMessage("hello")
+
+	if msg.Role != gopherai.RoleUser {
+		t.Errorf("expected role 'user', got '%s'", msg.Role)
+	}
+
+	if msg.Text() != "hello" {
+		t.Errorf("expected text 'hello', got '%s'", msg.Text())
+	}
+}
+
+func TestNewAssistantMessage_OmitsEmptyTextWithToolCalls(t *testing.T) {
+	call := gopherai.ToolCall{Name: "test", Arguments: "{}", CallID: "call_1"}
+	msg := gopherai.NewAssistantMessage("", call)
+
+	if len(msg.Parts) != 1 {
+		t.Fatalf("expected 1 part, got %d", len(msg.Parts))
+	}
+
+	if msg.Parts[0].Type != gopherai.PartTypeToolCall {
+		t.Errorf("expected tool call part, got '%s'", msg.Parts[0].Type)
+	}
+
+	calls := msg.ToolCalls()
+	if len(calls) != 1 || calls[0].CallID != "call_1" {
+		t.Errorf("expected tool call 'call_1', got %v", calls)
+	}
+}
+
+func TestNewAssistantMessage_KeepsTextAndToolCalls(t *testing.T) {
+	call := gopherai.ToolCall{Name: "test", Arguments: "{}", CallID: "call_1"}
+	msg := gopherai.NewAssistantMessage("thinking", call)
+
+	if len(msg.Parts) != 2 {
+		t.Fatalf("expected 2 parts, got %d", len(msg.Parts))
+	}
+
+	if msg.Text() != "thinking" {
+		t.Errorf("expected text 'thinking', got '%s'", msg.Text())
+	}
+}
+
+func TestNewToolResultMessage_CreatesToolMessage(t *testing.T) {
+	msg := gopherai.NewToolResultMessage(
+		gopherai.ToolResult{CallID: "call_1", Name: "a", Output: "1"},
+		gopherai.ToolResult{CallID: "call_2", Name: "b", Output: "2"},
+	)
+
+	if msg.Role != gopherai.RoleTool {
+		t.Errorf("expected role 'tool', got '%s'", msg.Role)
+	}
+
+	results := msg.ToolResults()
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+
+	if results[1].Output != "2" {
+		t.Errorf("expected output '2', got '%s'", results[1].Output)
+	}
+}
+
+func TestCloneMessages_DoesNotShareParts(t *testing.T) {
+	original := []gopherai.Message{
+		gopherai.NewAssistantMessage("", gopherai.ToolCall{Name: "test", CallID: "call_1"}),
+	}
+
+	cloned := gopherai.CloneMessages(original)
+	cloned[0].Parts[0].ToolCall.Name = "changed"
+
+	if original[0].Parts[0].ToolCall.Name != "test" {
+		t.Errorf("expected original tool call to be unchanged, got '%s'", original[0].Parts[0].ToolCall.Name)
+	}
+}
+
+func TestMessage_RoundTripsThroughJSON(t *testing.T) {
+	msg := gopherai.NewAssistantMessage("text", gopherai.ToolCall{Name: "test", Arguments: `{"a":1}`, CallID: "call_1"})
+	msg.Metadata = map[string]any{"source": "test"}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var decoded gopherai.Message
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if decoded.Text() != "text" {
+		t.Errorf("expected text 'text', got '%s'", decoded.Text())
+	}
+
+	calls := decoded.ToolCalls()
+	if len(calls) != 1 || calls[0].Arguments != `{"a":1}` {
+		t.Errorf("expected tool call to round trip, got %v", calls)
+	}
+
+	if decoded.Metadata["source"] != "test" {
+		t.Errorf("expected metadata to round trip, got %v", decoded.Metadata)
+	}
+}
+
+func TestNormalizeToolCallIDs_RenamesDuplicateCallIDs(t *testing.T) {
+	messages := []gopherai.Message{
+		gopherai.NewUserMessage("question"),
+		gopherai.NewAssistantMessage("", gopherai.ToolCall{Name: "a", CallID: "0"}),
+		gopherai.NewToolResultMessage(gopherai.ToolResult{CallID: "0", Output: "first"}),
+		gopherai.NewAssistantMessage("", gopherai.ToolCall{Name: "b", CallID: "0"}, gopherai.ToolCall{Name: "c"}),
+		gopherai.NewToolResultMessage(
+			gopherai.ToolResult{CallID: "0", Output: "second"},
+			gopherai.ToolResult{CallID: "", Output: "third"},
+		),
+	}
+
+	normalized := gopherai.NormalizeToolCallIDs(messages)
+
+	firstCall := normalized[1].ToolCalls()[0]
+	secondCalls := normalized[3].ToolCalls()
+	if firstCall.CallID != "0" {
+		t.Errorf("expected first call ID to be kept, got '%s'", firstCall.CallID)
+	}
+	if secondCalls[0].CallID == "0" || secondCalls[0].CallID == "" {
+		t.Errorf("expected duplicate call ID to be renamed, got '%s'", secondCalls[0].CallID)
+	}
+	if secondCalls[1].CallID == "" || secondCalls[1].CallID == secondCalls[0].CallID {
+		t.Errorf("expected empty call ID to get a unique ID, got '%s'", secondCalls[1].CallID)
+	}
+
+	firstResult := normalized[2].ToolResults()[0]
+	secondResults := normalized[4].ToolResults()
+	if firstResult.CallID != firstCall.CallID {
+		t.Errorf("expected first result to point at '%s', got '%s'", firstCall.CallID, firstResult.CallID)
+	}
+	if secondResults[0].CallID != secondCalls[0].CallID {
+		t.Errorf("expected second result to point at '%s', got '%s'", secondCalls[0].CallID, secondResults[0].CallID)
+	}
+	if secondResults[1].CallID != secondCalls[1].CallID {
+		t.Errorf("expected third result to point at '%s', got '%s'", secondCalls[1].CallID, secondResults[1].CallID)
+	}
+
+	if messages[3].ToolCalls()[0].CallID != "0" {
+		t.Error("expected original messages to be unchanged")
+	}
+}
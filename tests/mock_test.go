@@ -0,0 +1,189 @@
+package gopherai_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+)
+
+// mockTurn is a scripted model response. A turn with err fails instead of
+// answering, and a streamed turn with events sends them instead of the events
+// of its text, calls and usage.
+type mockTurn struct {
+	text   string
+	calls  []gopherai.ToolCall
+	usage  *gopherai.Usage
+	events []gopherai.StreamEvent
+	err    error
+}
+
+type mockScriptedResponse struct {
+	turn mockTurn
+}
+
+// mockScriptedProvider replays a fixed list of turns, streamed or not, and
+// records the messages of every request. Once the script is exhausted it
+// answers with the turns of then, or with "done". Each response waits delay
+// first, and peak records the highest number of responses served at once.
+type mockScriptedProvider struct {
+	mu       sync.Mutex
+	turns    []mockTurn
+	then     func(turn int) mockTurn
+	delay    time.Duration
+	next     int
+	requests [][]gopherai.Message
+
+	inFlight atomic.Int32
+	peak     atomic.Int32
+}
+
+// respond waits for the delay and returns the next turn of the script.
+func (m *mockScriptedProvider) respond(ctx context.Context) (mockTurn, error) {
+	n := m.inFlight.Add(1)
+	defer m.inFlight.Add(-1)
+	for {
+		peak := m.peak.Load()
+		if n <= peak || m.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	if m.delay > 0 {
+		select {
+		case <-time.After(m.delay):
+		case <-ctx.Done():
+			return mockTurn{}, ctx.Err()
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.next++
+	turn := mockTurn{text: "done"}
+	switch {
+	case m.next <= len(m.turns):
+		turn = m.turns[m.next-1]
+	case m.then != nil:
+		turn = m.then(m.next)
+	}
+	return turn, turn.err
+}
+
+func (m *mockScriptedProvider) CreateResponse(ctx context.Context, _ any) (any, error) {
+	turn, err := m.respond(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &mockScriptedResponse{turn: turn}, nil
+}
+
+func (m *mockScriptedProvider) CreateResponseStream(ctx context.Context, _ any) (<-chan gopherai.StreamEvent, error) {
+	turn, err := m.respond(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	events := turn.events
+	if events == nil {
+		if turn.text != "" {
+			events = append(events, gopherai.StreamEvent{Type: gopherai.StreamEventTypeTextDone, Text: turn.text})
+		}
+		for _, call := range turn.calls {
+			events = append(events, gopherai.StreamEvent{Type: gopherai.StreamEventTypeToolCall, ToolCall: &call})
+		}
+		if turn.usage != nil {
+			events = append(events, gopherai.StreamEvent{Type: gopherai.StreamEventTypeUsage, Usage: turn.usage})
+		}
+		events = append(events, gopherai.StreamEvent{Type: gopherai.StreamEventTypeDone})
+	}
+
+	ch := make(chan gopherai.StreamEvent, len(events))
+	for _, event := range events {
+		ch <- event
+	}
+	close(ch)
+	return ch, nil
+}
+
+func (m *mockScriptedProvider) BuildRequest(messages []gopherai.Message, _ string, _ []any) any {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests = append(m.requests, gopherai.CloneMessages(messages))
+	return &mockRequest{}
+}
+
+func (m *mockScriptedProvider) ConvertTool(tool gopherai.Tool) any {
+	return tool
+}
+
+func (m *mockScriptedProvider) ExtractToolCalls(resp any) ([]gopherai.ToolCall, error) {
+	return resp.(*mockScriptedResponse).turn.calls, nil
+}
+
+func (m *mockScriptedProvider) ExtractText(resp any) string {
+	return resp.(*mockScriptedResponse).turn.text
+}
+
+func (m *mockScriptedProvider) ExtractUsage(resp any) (gopherai.Usage, bool) {
+	usage := resp.(*mockScriptedResponse).turn.usage
+	if usage == nil {
+		return gopherai.Usage{}, false
+	}
+	return *usage, true
+}
+
+// responses returns the number of responses the provider was asked for.
+func (m *mockScriptedProvider) responses() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.next
+}
+
+// lastToolResults returns the tool results sent to the model in the most recent request.
+func (m *mockScriptedProvider) lastToolResults() []gopherai.ToolResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.requests) == 0 {
+		return nil
+	}
+	messages := m.requests[len(m.requests)-1]
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == gopherai.RoleTool {
+			return messages[i].ToolResults()
+		}
+	}
+	return nil
+}
+
+// answeringTurns returns turns that each answer with text, reporting usage
+// when it is set.
+func answeringTurns(text string, usage *gopherai.Usage) func(int) mockTurn {
+	return func(int) mockTurn {
+		return mockTurn{text: text, usage: usage}
+	}
+}
+
+// loopingTurns returns turns that each call the tool callsPerTurn times with
+// arguments, reporting usage when it is set, so that a run never ends.
+func loopingTurns(tool, arguments string, callsPerTurn int, usage *gopherai.Usage) func(int) mockTurn {
+	return func(turn int) mockTurn {
+		calls := make([]gopherai.ToolCall, callsPerTurn)
+		for i := range calls {
+			calls[i] = gopherai.ToolCall{Name: tool, Arguments: arguments, CallID: fmt.Sprintf("call_%d_%d", turn, i)}
+		}
+		return mockTurn{calls: calls, usage: usage}
+	}
+}
+
+// loopingProvider returns a provider that requests callsPerTurn echo tool
+// calls on every turn.
+func loopingProvider(callsPerTurn int) *mockScriptedProvider {
+	return &mockScriptedProvider{then: loopingTurns("echo", `{"value":"x"}`, callsPerTurn, nil)}
+}
@@ -0,0 +1,115 @@
+package ollama_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai/ollama"
+)
+
+func newModelServer(t *testing.T, pulled *[]string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"models":[{"name":"llama3.1:latest","model":"llama3.1:latest","size":4661224676,"details":{"family":"llama","parameter_size":"8.0B"}}]}`))
+		case "/api/pull":
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			name, _ := body["model"].(string)
+			*pulled = append(*pulled, name)
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			if name == "broken" {
+				_, _ = w.Write([]byte(`{"status":"pulling manifest"}` + "\n" + `{"error":"pull model manifest: file does not exist"}` + "\n"))
+				return
+			}
+			_, _ = w.Write([]byte(`{"status":"pulling manifest"}
+{"status":"downloading","digest":"sha256:abc","total":100,"completed":50}
+{"status":"success"}
+`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestListModels_ReturnsModels(t *testing.T) {
+	var pulled []string
+	server := newModelServer(t, &pulled)
+	defer server.Close()
+
+	models, err := ollama.NewProvider().SetBaseURL(server.URL).ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(models) != 1 || models[0].Name != "llama3.1:latest" || models[0].Details.Family != "llama" {
+		t.Errorf("unexpected models: %+v", models)
+	}
+}
+
+func TestHasModel_MatchesLatestTag(t *testing.T) {
+	var pulled []string
+	server := newModelServer(t, &pulled)
+	defer server.Close()
+	provider := ollama.NewProvider().SetBaseURL(server.URL)
+
+	for name, want := range map[string]bool{"llama3.1": true, "llama3.1:latest": true, "llama3.1:70b": false, "mistral": false} {
+		got, err := provider.HasModel(context.Background(), name)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != want {
+			t.Errorf("HasModel(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestPullModel_ReportsProgress(t *testing.T) {
+	var pulled []string
+	server := newModelServer(t, &pulled)
+	defer server.Close()
+
+	var statuses []string
+	err := ollama.NewProvider().SetBaseURL(server.URL).PullModel(context.Background(), "mistral", func(p ollama.PullProgress) {
+		statuses = append(statuses, p.Status)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(statuses, ",") != "pulling manifest,downloading,success" {
+		t.Errorf("unexpected progress: %v", statuses)
+	}
+}
+
+func TestPullModel_ReturnsStreamedError(t *testing.T) {
+	var pulled []string
+	server := newModelServer(t, &pulled)
+	defer server.Close()
+
+	err := ollama.NewProvider().SetBaseURL(server.URL).PullModel(context.Background(), "broken", nil)
+	if err == nil || !strings.Contains(err.Error(), "file does not exist") {
+		t.Errorf("expected pull error, got %v", err)
+	}
+}
+
+func TestEnsureModel_PullsOnlyMissingModels(t *testing.T) {
+	var pulled []string
+	server := newModelServer(t, &pulled)
+	defer server.Close()
+	provider := ollama.NewProvider().SetBaseURL(server.URL)
+
+	if err := provider.EnsureModel(context.Background(), "llama3.1", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := provider.EnsureModel(context.Background(), "mistral", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pulled) != 1 || pulled[0] != "mistral" {
+		t.Errorf("expected only mistral to be pulled, got %v", pulled)
+	}
+}
@@ -0,0 +1,79 @@
+package ollama_test
+
+import (
+	"testing"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+	"github.com/marti-jorda-roca/gopher-ai/gopherai/ollama"
+)
+
+func TestNewProvider_CreatesProvider(t *testing.T) {
+	provider := ollama.NewProvider()
+
+	if provider == nil {
+		t.Fatal("expected provider to be created")
+	}
+}
+
+func TestProvider_MethodChainingSetsOptions(t *testing.T) {
+	provider := ollama.NewProvider().
+		SetModel("qwen2.5").
+		SetTemperature(0.2).
+		SetMaxTokens(256).
+		SetNumCtx(8192).
+		SetSeed(42).
+		SetBaseURL("http://localhost")
+
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil).(*ollama.ChatRequest)
+	if req.Model != "qwen2.5" {
+		t.Errorf("expected model 'qwen2.5', got '%s'", req.Model)
+	}
+	if req.Options == nil {
+		t.Fatal("expected options to be set")
+	}
+	if req.Options.Temperature == nil || *req.Options.Temperature != 0.2 {
+		t.Errorf("expected temperature 0.2, got %v", req.Options.Temperature)
+	}
+	if req.Options.NumPredict == nil || *req.Options.NumPredict != 256 {
+		t.Errorf("expected num_predict 256, got %v", req.Options.NumPredict)
+	}
+	if req.Options.NumCtx == nil || *req.Options.NumCtx != 8192 {
+		t.Errorf("expected num_ctx 8192, got %v", req.Options.NumCtx)
+	}
+	if req.Options.Seed == nil || *req.Options.Seed != 42 {
+		t.Errorf("expected seed 42, got %v", req.Options.Seed)
+	}
+}
+
+func TestBuildRequest_OmitsOptionsWhenUnset(t *testing.T) {
+	provider := ollama.NewProvider()
+
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil).(*ollama.ChatRequest)
+	if req.Options != nil {
+		t.Errorf("expected no options, got %+v", req.Options)
+	}
+}
+
+var (
+	_ gopherai.StreamProvider           = (*ollama.Provider)(nil)
+	_ gopherai.StructuredOutputProvider = (*ollama.Provider)(nil)
+)
+
+func TestNewProviderFromURI_CreatesOllamaProvider(t *testing.T) {
+	provider, err := gopherai.NewProviderFromURI("ollama:llama3.1:8b?num_ctx=4096&seed=7")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil).(*ollama.ChatRequest)
+	if req.Model != "llama3.1:8b" {
+		t.Errorf("expected model 'llama3.1:8b', got '%s'", req.Model)
+	}
+	if req.Options == nil || *req.Options.NumCtx != 4096 || *req.Options.Seed != 7 {
+		t.Errorf("expected num_ctx and seed options, got %+v", req.Options)
+	}
+
+	if _, err := gopherai.NewProviderFromURI("ollama:llama3.1?num_ctx=big"); err == nil {
+		t.Error("expected invalid num_ctx error")
+	}
+}
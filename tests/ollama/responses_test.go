@@ -0,0 +1,275 @@
+package ollama_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+	"github.com/marti-jorda-roca/gopher-ai/gopherai/ollama"
+)
+
+func TestBuildRequest_SetsSystemPromptAndTools(t *testing.T) {
+	provider := ollama.NewProvider()
+	tools := []any{provider.ConvertTool(gopherai.Tool{Name: "lookup", Parameters: map[string]any{"type": "object"}})}
+
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "be brief", tools).(*ollama.ChatRequest)
+	if len(req.Messages) != 2 || req.Messages[0].Role != "system" || req.Messages[0].Content != "be brief" {
+		t.Errorf("expected system message first, got %+v", req.Messages)
+	}
+	if len(req.Tools) != 1 || req.Tools[0].Type != "function" || req.Tools[0].Function.Name != "lookup" {
+		t.Errorf("expected lookup tool, got %+v", req.Tools)
+	}
+}
+
+func TestConvertMessages_CreatesToolCallsAndToolMessages(t *testing.T) {
+	call := gopherai.ToolCall{Name: "get_weather", Arguments: `{"city":"Paris"}`, CallID: "call_0"}
+	messages := []gopherai.Message{
+		gopherai.NewUserMessage("weather?"),
+		gopherai.NewAssistantMessage("", call),
+		gopherai.NewToolResultMessage(gopherai.ToolResult{CallID: "call_0", Output: "sunny"}),
+	}
+
+	converted := ollama.ConvertMessages(messages)
+	if len(converted) != 3 {
+		t.Fatalf("expected 3 messages, got %d: %+v", len(converted), converted)
+	}
+	if args := string(converted[1].ToolCalls[0].Function.Arguments); args != `{"city":"Paris"}` {
+		t.Errorf("expected arguments object, got %s", args)
+	}
+	if converted[2].Role != "tool" || converted[2].ToolName != "get_weather" || converted[2].Content != "sunny" {
+		t.Errorf("expected tool message named after its call, got %+v", converted[2])
+	}
+}
+
+func TestToMessages_MatchesToolResultsToCalls(t *testing.T) {
+	messages := []ollama.Message{
+		{Role: "user", Content: "weather?"},
+		{Role: "assistant", ToolCalls: []ollama.ToolCall{
+			{Function: ollama.FunctionCall{Name: "get_weather", Arguments: json.RawMessage(`{"city":"Paris"}`)}},
+		}},
+		{Role: "tool", ToolName: "get_weather", Content: "sunny"},
+	}
+
+	converted := ollama.ToMessages(messages)
+	if len(converted) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(converted))
+	}
+	calls := converted[1].ToolCalls()
+	results := converted[2].ToolResults()
+	if len(calls) != 1 || len(results) != 1 || calls[0].CallID != results[0].CallID {
+		t.Errorf("expected tool result to match its call, got calls=%+v results=%+v", calls, results)
+	}
+}
+
+func TestExtractToolCalls_GeneratesMissingIDs(t *testing.T) {
+	provider := ollama.NewProvider()
+	resp := &ollama.ChatResponse{Message: ollama.Message{
+		Role: "assistant",
+		ToolCalls: []ollama.ToolCall{
+			{Function: ollama.FunctionCall{Name: "a", Arguments: json.RawMessage(`{"x":1}`)}},
+			{Function: ollama.FunctionCall{Name: "b"}},
+		},
+	}}
+
+	calls, err := provider.ExtractToolCalls(resp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(calls) != 2 || calls[0].CallID == calls[1].CallID || calls[0].CallID == "" {
+		t.Errorf("expected distinct call IDs, got %+v", calls)
+	}
+	if calls[1].Arguments != "{}" {
+		t.Errorf("expected empty object for missing arguments, got %q", calls[1].Arguments)
+	}
+}
+
+func TestSetOutputSchema_SetsFormat(t *testing.T) {
+	provider := ollama.NewProvider()
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil)
+
+	schema := map[string]any{"type": "object"}
+	if err := provider.SetOutputSchema(req, gopherai.OutputSchema{Name: "answer", Schema: schema}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.(*ollama.ChatRequest).Format["type"] != "object" {
+		t.Errorf("expected format schema, got %+v", req.(*ollama.ChatRequest).Format)
+	}
+}
+
+func TestCreateResponse_PostsToChatEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("expected path /api/chat, got %s", r.URL.Path)
+		}
+
+		var raw map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		if raw["stream"] != false {
+			t.Errorf("expected stream to be sent as false, got %v", raw["stream"])
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"model":"llama3.1","message":{"role":"assistant","content":"Hello!"},"done":true}`))
+	}))
+	defer server.Close()
+
+	provider := ollama.NewProvider().SetBaseURL(server.URL)
+	agent := gopherai.NewAgent(provider)
+
+	result, err := agent.Run(context.Background(), "hi")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Text != "Hello!" {
+		t.Errorf("expected 'Hello!', got '%s'", result.Text)
+	}
+}
+
+func TestCreateResponse_ReturnsAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":"model \"missing\" not found, try pulling it first"}`))
+	}))
+	defer server.Close()
+
+	provider := ollama.NewProvider().SetBaseURL(server.URL).SetModel("missing")
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil)
+
+	_, err := provider.CreateResponse(context.Background(), req)
+	if err == nil || !strings.Contains(err.Error(), "try pulling it first") {
+		t.Errorf("expected API error message, got %v", err)
+	}
+}
+
+const toolCallStream = `{"model":"llama3.1","message":{"role":"assistant","content":"Let me "},"done":false}
+{"model":"llama3.1","message":{"role":"assistant","content":"check."},"done":false}
+{"model":"llama3.1","message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"get_weather","arguments":{"city":"Paris"}}}]},"done":false}
+{"model":"llama3.1","message":{"role":"assistant","content":""},"done":true,"done_reason":"stop"}
+`
+
+func TestParseNDJSONStream_ParsesTextAndToolCalls(t *testing.T) {
+	events := ollama.ParseNDJSONStreamForTest(strings.NewReader(toolCallStream))
+
+	var deltas []string
+	var toolCall *gopherai.ToolCall
+	var text string
+	var done bool
+	for event := range events {
+		switch event.Type {
+		case gopherai.StreamEventTypeTextDelta:
+			deltas = append(deltas, event.Delta)
+		case gopherai.StreamEventTypeTextDone:
+			text = event.Text
+		case gopherai.StreamEventTypeToolCall:
+			toolCall = event.ToolCall
+		case gopherai.StreamEventTypeDone:
+			done = true
+		case gopherai.StreamEventTypeError:
+			t.Fatalf("unexpected error event: %v", event.Error)
+		}
+	}
+
+	if strings.Join(deltas, "") != "Let me check." || text != "Let me check." {
+		t.Errorf("unexpected text: deltas=%v text=%q", deltas, text)
+	}
+	if toolCall == nil || toolCall.Name != "get_weather" || toolCall.Arguments != `{"city":"Paris"}` || toolCall.CallID == "" {
+		t.Errorf("unexpected tool call: %+v", toolCall)
+	}
+	if !done {
+		t.Error("expected done event")
+	}
+}
+
+func TestParseNDJSONStream_ParsesErrorLines(t *testing.T) {
+	stream := `{"error":"model runner has unexpectedly stopped"}` + "\n"
+
+	var streamErr error
+	for event := range ollama.ParseNDJSONStreamForTest(strings.NewReader(stream)) {
+		if event.Type == gopherai.StreamEventTypeError {
+			streamErr = event.Error
+		}
+	}
+
+	if streamErr == nil || !strings.Contains(streamErr.Error(), "unexpectedly stopped") {
+		t.Errorf("expected stream error, got %v", streamErr)
+	}
+}
+
+func TestCreateResponseStream_RunsAgentAgainstServer(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var req ollama.ChatRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream {
+			t.Error("expected stream to be enabled")
+		}
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		if requests == 1 {
+			_, _ = w.Write([]byte(toolCallStream))
+			return
+		}
+		if last := req.Messages[len(req.Messages)-1]; last.Role != "tool" || last.ToolName != "get_weather" {
+			t.Errorf("expected tool result message, got %+v", last)
+		}
+		_, _ = w.Write([]byte(`{"message":{"role":"assistant","content":"Sunny."},"done":false}
+{"message":{"role":"assistant","content":""},"done":true}
+`))
+	}))
+	defer server.Close()
+
+	weather := gopherai.NewTool("get_weather", "Gets the weather", func(p struct {
+		City string `json:"city"`
+	}) (string, error) {
+		return "sunny in " + p.City, nil
+	})
+	provider := ollama.NewProvider().SetBaseURL(server.URL)
+	agent := gopherai.NewAgent(provider, gopherai.WithTools(weather))
+
+	events, err := agent.RunStream(context.Background(), "weather in Paris?")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var final string
+	for event := range events {
+		if event.Type == gopherai.StreamEventTypeError {
+			t.Fatalf("unexpected error: %v", event.Error)
+		}
+		if event.Type == gopherai.StreamEventTypeTextDone {
+			final = event.Text
+		}
+	}
+
+	if final != "Sunny." {
+		t.Errorf("expected final text 'Sunny.', got '%s'", final)
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+}
+
+func TestParseNDJSONStream_EmitsUsageOnDone(t *testing.T) {
+	stream := `{"model":"llama3.1","message":{"role":"assistant","content":"Hi"},"done":false}
+{"model":"llama3.1","message":{"role":"assistant","content":""},"done":true,"prompt_eval_count":26,"eval_count":7}
+`
+	var usage *gopherai.Usage
+	for event := range ollama.ParseNDJSONStreamForTest(strings.NewReader(stream)) {
+		if event.Type == gopherai.StreamEventTypeUsage {
+			usage = event.Usage
+		}
+	}
+
+	want := gopherai.Usage{Model: "llama3.1", InputTokens: 26, OutputTokens: 7}
+	if usage == nil || *usage != want {
+		t.Errorf("expected usage %+v, got %+v", want, usage)
+	}
+}
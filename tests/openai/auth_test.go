@@ -0,0 +1,162 @@
+package openai_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+	"github.com/marti-jorda-roca/gopher-ai/gopherai/openai"
+)
+
+func TestNewProvider_SendsBearerTokenAndHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Bearer test-key" {
+			t.Errorf("expected bearer token, got %q", auth)
+		}
+		if org := r.Header.Get("OpenAI-Organization"); org != "org-1" {
+			t.Errorf("expected organization header, got %q", org)
+		}
+		if project := r.Header.Get("OpenAI-Project"); project != "proj-1" {
+			t.Errorf("expected project header, got %q", project)
+		}
+		if custom := r.Header.Get("X-Team"); custom != "search" {
+			t.Errorf("expected custom header, got %q", custom)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"resp_1","output":[]}`))
+	}))
+	defer server.Close()
+
+	provider := openai.NewProvider("test-key").
+		SetBaseURL(server.URL).
+		SetOrganization("org-1").
+		SetProject("proj-1").
+		SetHeader("X-Team", "search")
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil)
+
+	if _, err := provider.CreateResponse(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestSetAuthenticator_SignsFinalRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("expected bearer token to be replaced, got %q", auth)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if sig := r.Header.Get("X-Signature"); sig != signature(body) {
+			t.Errorf("expected signature of the body, got %q", sig)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"c1","choices":[{"index":0,"message":{"role":"assistant","content":"ok"}}]}`))
+	}))
+	defer server.Close()
+
+	signer := gopherai.AuthenticatorFunc(func(r *http.Request) error {
+		body, err := r.GetBody()
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return err
+		}
+		r.Header.Set("X-Signature", signature(data))
+		return nil
+	})
+	provider := openai.NewChatProvider("test-key").SetBaseURL(server.URL).SetAuthenticator(signer)
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil)
+
+	if _, err := provider.CreateResponse(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestSetAuthenticator_ReturnsAuthenticatorErrors(t *testing.T) {
+	errNoCredentials := errors.New("no credentials")
+	provider := openai.NewProvider("test-key").
+		SetBaseURL("http://127.0.0.1:0").
+		SetAuthenticator(gopherai.AuthenticatorFunc(func(*http.Request) error { return errNoCredentials }))
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil)
+
+	_, err := provider.CreateResponse(context.Background(), req)
+	if !errors.Is(err, errNoCredentials) {
+		t.Errorf("expected authenticator error, got %v", err)
+	}
+}
+
+func TestNewAzureProvider_UsesAPIKeyAndAPIVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/openai/responses" {
+			t.Errorf("expected path /openai/responses, got %s", r.URL.Path)
+		}
+		if version := r.URL.Query().Get("api-version"); version != openai.DefaultAzureAPIVersion {
+			t.Errorf("expected default api-version, got %q", version)
+		}
+		if key := r.Header.Get("api-key"); key != "azure-key" {
+			t.Errorf("expected api-key header, got %q", key)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("expected no Authorization header, got %q", auth)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), `"model":"my-gpt"`) {
+			t.Errorf("expected deployment as model, got %s", body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"resp_1","output":[]}`))
+	}))
+	defer server.Close()
+
+	provider := openai.NewAzureProvider("azure-key", openai.AzureConfig{Endpoint: server.URL + "/", Deployment: "my-gpt"})
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil)
+
+	if _, err := provider.CreateResponse(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestNewAzureChatProvider_UsesDeploymentURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/openai/deployments/my-gpt/chat/completions" {
+			t.Errorf("expected deployment path, got %s", r.URL.Path)
+		}
+		if version := r.URL.Query().Get("api-version"); version != "2024-10-21" {
+			t.Errorf("expected api-version 2024-10-21, got %q", version)
+		}
+		if key := r.Header.Get("api-key"); key != "azure-key" {
+			t.Errorf("expected api-key header, got %q", key)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"c1","choices":[{"index":0,"message":{"role":"assistant","content":"ok"}}]}`))
+	}))
+	defer server.Close()
+
+	provider := openai.NewAzureChatProvider("azure-key", openai.AzureConfig{
+		Endpoint:   server.URL,
+		Deployment: "my-gpt",
+		APIVersion: "2024-10-21",
+	})
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil)
+
+	resp, err := provider.CreateResponse(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := provider.ExtractText(resp); text != "ok" {
+		t.Errorf("expected 'ok', got %q", text)
+	}
+}
+
+func signature(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,228 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+	"github.com/marti-jorda-roca/gopher-ai/gopherai/openai"
+)
+
+var _ gopherai.StreamProvider = (*openai.ChatProvider)(nil)
+var _ gopherai.StructuredOutputProvider = (*openai.ChatProvider)(nil)
+
+func TestChatBuildRequest_AddsSystemMessageAndTools(t *testing.T) {
+	provider := openai.NewChatProvider("").SetModel("llama3.1")
+	tools := []any{provider.ConvertTool(gopherai.Tool{Name: "lookup", Parameters: map[string]any{"type": "object"}})}
+
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "be brief", tools).(*openai.ChatCompletionRequest)
+
+	if req.Model != "llama3.1" {
+		t.Errorf("expected model 'llama3.1', got '%s'", req.Model)
+	}
+	if len(req.Messages) != 2 || req.Messages[0].Role != "system" || req.Messages[1].Content != "hi" {
+		t.Errorf("unexpected messages: %+v", req.Messages)
+	}
+	if len(req.Tools) != 1 || req.Tools[0].Type != "function" || req.Tools[0].Function.Name != "lookup" {
+		t.Errorf("unexpected tools: %+v", req.Tools)
+	}
+}
+
+func TestConvertChatMessages_CreatesToolCallsAndToolMessages(t *testing.T) {
+	messages := []gopherai.Message{
+		gopherai.NewUserMessage("weather?"),
+		gopherai.NewAssistantMessage("",
+			gopherai.ToolCall{Name: "get_weather", Arguments: `{"city":"Paris"}`, CallID: "call_1"},
+			gopherai.ToolCall{Name: "get_weather", Arguments: `{"city":"Rome"}`, CallID: "call_2"},
+		),
+		gopherai.NewToolResultMessage(
+			gopherai.ToolResult{CallID: "call_1", Output: "sunny"},
+			gopherai.ToolResult{CallID: "call_2", Output: "rainy"},
+		),
+	}
+
+	converted := openai.ConvertChatMessages(messages)
+	if len(converted) != 4 {
+		t.Fatalf("expected 4 messages, got %d", len(converted))
+	}
+	if calls := converted[1].ToolCalls; len(calls) != 2 || calls[1].Function.Arguments != `{"city":"Rome"}` {
+		t.Errorf("unexpected tool calls: %+v", calls)
+	}
+	if converted[3].Role != "tool" || converted[3].ToolCallID != "call_2" || converted[3].Content != "rainy" {
+		t.Errorf("unexpected tool message: %+v", converted[3])
+	}
+
+	roundTrip := openai.ChatToMessages(converted)
+	if len(roundTrip) != 3 || len(roundTrip[2].ToolResults()) != 2 {
+		t.Errorf("expected tool messages to be grouped, got %+v", roundTrip)
+	}
+}
+
+func TestChatCreateResponse_UsesBaseURLAndParsesToolCalls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("expected path /v1/chat/completions, got %s", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("expected no Authorization header without an API key, got %q", auth)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"c1","choices":[{"index":0,"message":{"role":"assistant","content":null,"tool_calls":[{"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"Paris\"}"}}]},"finish_reason":"tool_calls"}]}`))
+	}))
+	defer server.Close()
+
+	provider := openai.NewChatProvider("").SetBaseURL(server.URL + "/v1")
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil)
+
+	resp, err := provider.CreateResponse(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	calls, err := provider.ExtractToolCalls(resp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(calls) != 1 || calls[0].CallID != "call_1" || calls[0].Arguments != `{"city":"Paris"}` {
+		t.Errorf("unexpected calls: %+v", calls)
+	}
+}
+
+func TestChatCreateResponse_ReturnsAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":{"message":"model not found","type":"invalid_request_error"}}`))
+	}))
+	defer server.Close()
+
+	provider := openai.NewChatProvider("key").SetBaseURL(server.URL)
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil)
+
+	_, err := provider.CreateResponse(context.Background(), req)
+	if err == nil || !strings.Contains(err.Error(), "model not found") {
+		t.Errorf("expected API error, got %v", err)
+	}
+}
+
+const chatToolCallStream = `data: {"id":"c1","choices":[{"index":0,"delta":{"role":"assistant","content":"Checking"}}]}
+
+data: {"id":"c1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"get_weather","arguments":""}}]}}]}
+
+data: {"id":"c1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"city\":"}}]}}]}
+
+data: {"id":"c1","choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"id":"call_2","type":"function","function":{"name":"get_time","arguments":"{}"}}]}}]}
+
+data: {"id":"c1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"Paris\"}"}}]}}]}
+
+data: {"id":"c1","choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}
+
+data: [DONE]
+
+`
+
+func TestParseChatStream_AccumulatesToolCallArguments(t *testing.T) {
+	var calls []*gopherai.ToolCall
+	var text string
+	var done bool
+	for event := range openai.ParseChatStreamForTest(strings.NewReader(chatToolCallStream)) {
+		switch event.Type {
+		case gopherai.StreamEventTypeToolCall:
+			calls = append(calls, event.ToolCall)
+		case gopherai.StreamEventTypeTextDone:
+			text = event.Text
+		case gopherai.StreamEventTypeDone:
+			done = true
+		case gopherai.StreamEventTypeError:
+			t.Fatalf("unexpected error: %v", event.Error)
+		}
+	}
+
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 tool calls, got %d", len(calls))
+	}
+	if calls[0].CallID != "call_1" || calls[0].Name != "get_weather" || calls[0].Arguments != `{"city":"Paris"}` {
+		t.Errorf("unexpected first call: %+v", calls[0])
+	}
+	if calls[1].Name != "get_time" || calls[1].Arguments != "{}" {
+		t.Errorf("unexpected second call: %+v", calls[1])
+	}
+	if text != "Checking" || !done {
+		t.Errorf("expected text and done events, got text=%q done=%v", text, done)
+	}
+}
+
+func TestChatProvider_RunsAgentWithStreaming(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var req openai.ChatCompletionRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		if requests == 1 {
+			_, _ = w.Write([]byte(chatToolCallStream))
+			return
+		}
+		if last := req.Messages[len(req.Messages)-1]; last.Role != "tool" {
+			t.Errorf("expected tool results in the follow-up request, got %+v", last)
+		}
+		_, _ = w.Write([]byte("data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Sunny.\"}}]}\n\ndata: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	type weatherParams struct {
+		City string `json:"city"`
+	}
+	weather := gopherai.NewTool("get_weather", "weather", func(p weatherParams) (string, error) {
+		return "sunny in " + p.City, nil
+	})
+	clock := gopherai.NewTool("get_time", "time", func(struct{}) (string, error) {
+		return "noon", nil
+	})
+
+	provider := openai.NewChatProvider("").SetBaseURL(server.URL)
+	agent := gopherai.NewAgent(provider, gopherai.WithTools(weather, clock))
+
+	events, err := agent.RunStream(context.Background(), "weather?")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var final string
+	for event := range events {
+		if event.Type == gopherai.StreamEventTypeError {
+			t.Fatalf("unexpected error: %v", event.Error)
+		}
+		if event.Type == gopherai.StreamEventTypeTextDone {
+			final = event.Text
+		}
+	}
+
+	if final != "Sunny." {
+		t.Errorf("expected 'Sunny.', got '%s'", final)
+	}
+}
+
+func TestParseChatStream_EmitsUsageFromLastChunk(t *testing.T) {
+	stream := `data: {"id":"1","model":"gpt-4o-mini","choices":[{"index":0,"delta":{"content":"Hi"}}]}
+
+data: {"id":"1","model":"gpt-4o-mini","choices":[],"usage":{"prompt_tokens":12,"completion_tokens":3,"total_tokens":15,"prompt_tokens_details":{"cached_tokens":8}}}
+
+data: [DONE]
+
+`
+	var usage *gopherai.Usage
+	for event := range openai.ParseChatStreamForTest(strings.NewReader(stream)) {
+		if event.Type == gopherai.StreamEventTypeUsage {
+			usage = event.Usage
+		}
+	}
+
+	want := gopherai.Usage{Model: "gpt-4o-mini", InputTokens: 12, CachedInputTokens: 8, OutputTokens: 3}
+	if usage == nil || *usage != want {
+		t.Errorf("expected usage %+v, got %+v", want, usage)
+	}
+}
@@ -0,0 +1,76 @@
+package openai_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+	"github.com/marti-jorda-roca/gopher-ai/gopherai/openai"
+)
+
+func TestCreateResponse_ReturnsTypedAPIErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		kind      error
+		retryable bool
+	}{
+		{"context length", http.StatusBadRequest, `{"error":{"message":"This model's maximum context length is 128000 tokens.","type":"invalid_request_error","code":"context_length_exceeded"}}`, gopherai.ErrContextLengthExceeded, false},
+		{"rate limit", http.StatusTooManyRequests, `{"error":{"message":"Rate limit reached","type":"requests","code":"rate_limit_exceeded"}}`, gopherai.ErrRateLimited, true},
+		{"quota", http.StatusTooManyRequests, `{"error":{"message":"You exceeded your current quota","type":"insufficient_quota","code":"insufficient_quota"}}`, gopherai.ErrRateLimited, false},
+		{"auth", http.StatusUnauthorized, `{"error":{"message":"Incorrect API key provided","type":"invalid_request_error","code":"invalid_api_key"}}`, gopherai.ErrAuth, false},
+		{"content filter", http.StatusBadRequest, `{"error":{"message":"The response was filtered","type":null,"code":"content_filter"}}`, gopherai.ErrContentFiltered, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("x-request-id", "req_1")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			provider := openai.NewProvider("test-key").SetBaseURL(server.URL)
+			req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil)
+
+			_, err := provider.CreateResponse(context.Background(), req)
+			if !errors.Is(err, tt.kind) {
+				t.Fatalf("expected %v, got %v", tt.kind, err)
+			}
+			var apiErr *gopherai.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected *gopherai.APIError, got %T", err)
+			}
+			if apiErr.Provider != "openai" || apiErr.StatusCode != tt.status || apiErr.RequestID != "req_1" || apiErr.Retryable != tt.retryable {
+				t.Errorf("unexpected error: %+v", apiErr)
+			}
+		})
+	}
+}
+
+func TestCreateResponseStream_ReturnsTypedAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":{"message":"Incorrect API key provided","type":"invalid_request_error","code":"invalid_api_key"}}`))
+	}))
+	defer server.Close()
+
+	provider := openai.NewChatProvider("bad-key").SetBaseURL(server.URL)
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil)
+
+	_, err := provider.CreateResponseStream(context.Background(), req)
+	var apiErr *gopherai.APIError
+	if !errors.As(err, &apiErr) || !errors.Is(err, gopherai.ErrAuth) {
+		t.Fatalf("expected typed auth error, got %v", err)
+	}
+	if apiErr.Message != "Incorrect API key provided" || len(apiErr.Body) == 0 {
+		t.Errorf("expected parsed message and raw body, got %+v", apiErr)
+	}
+}
@@ -0,0 +1,61 @@
+package openai_test
+
+import (
+	"testing"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+	"github.com/marti-jorda-roca/gopher-ai/gopherai/openai"
+)
+
+func TestNewProviderFromURI_CreatesOpenAIProviders(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "test-key")
+
+	provider, err := gopherai.NewProviderFromURI("openai:gpt-4.1-mini?temperature=0.3&organization=org-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil).(*openai.CreateResponseRequest)
+	if req.Model != "gpt-4.1-mini" {
+		t.Errorf("expected model 'gpt-4.1-mini', got '%s'", req.Model)
+	}
+	if req.Temperature == nil || *req.Temperature != 0.3 {
+		t.Errorf("expected temperature 0.3, got %v", req.Temperature)
+	}
+
+	chat, err := gopherai.NewProviderFromURI("openai-chat:qwen2.5?base_url=http://localhost:8000/v1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := chat.(*openai.ChatProvider); !ok {
+		t.Errorf("expected *ChatProvider, got %T", chat)
+	}
+}
+
+func TestNewProviderFromURI_RequiresOpenAIKey(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+
+	if _, err := gopherai.NewProviderFromURI("openai:gpt-4.1"); err == nil {
+		t.Error("expected missing API key error")
+	}
+	if _, err := gopherai.NewProviderFromURI("openai-chat:local"); err != nil {
+		t.Errorf("expected chat provider without key, got %v", err)
+	}
+}
+
+func TestNewProviderFromURI_CreatesAzureProvider(t *testing.T) {
+	t.Setenv("AZURE_OPENAI_API_KEY", "azure-key")
+	t.Setenv("AZURE_OPENAI_ENDPOINT", "")
+
+	if _, err := gopherai.NewProviderFromURI("azure:my-gpt"); err == nil {
+		t.Error("expected missing endpoint error")
+	}
+
+	provider, err := gopherai.NewProviderFromURI("azure:my-gpt?endpoint=https://example.openai.azure.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil).(*openai.CreateResponseRequest)
+	if req.Model != "my-gpt" {
+		t.Errorf("expected deployment as model, got '%s'", req.Model)
+	}
+}
@@ -2,6 +2,7 @@ package openai_test
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
 
@@ -168,11 +169,28 @@ func TestExtractText_ReturnsEmptyForInvalidType(t *testing.T) {
 	}
 }
 
+func TestExtractUsage_ReturnsTokenCounts(t *testing.T) {
+	provider := openai.NewProvider("test-key")
+	resp := &openai.Response{Usage: &openai.Usage{InputTokens: 120, OutputTokens: 30, TotalTokens: 150}}
+
+	usage, ok := provider.ExtractUsage(resp)
+	if !ok {
+		t.Fatal("expected usage to be reported")
+	}
+	if usage.InputTokens != 120 || usage.OutputTokens != 30 || usage.TotalTokens() != 150 {
+		t.Errorf("unexpected usage: %+v", usage)
+	}
+
+	if _, ok := provider.ExtractUsage(&openai.Response{}); ok {
+		t.Error("expected no usage for a response without it")
+	}
+}
+
 func TestBuildRequest_CreatesRequestWithStringInput(t *testing.T) {
 	provider := openai.NewProvider("test-key")
 	provider.SetModel("gpt-4o")
 
-	req := provider.BuildRequest("test input", "system prompt", []any{})
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("test input")}, "system prompt", []any{})
 	createReq, ok := req.(*openai.CreateResponseRequest)
 	if !ok {
 		t.Fatal("expected CreateResponseRequest")
@@ -187,15 +205,15 @@ func TestBuildRequest_CreatesRequestWithStringInput(t *testing.T) {
 	}
 }
 
-func TestBuildRequest_ConvertsSliceInputToInputItems(t *testing.T) {
+func TestBuildRequest_ConvertsMessagesToInputItems(t *testing.T) {
 	provider := openai.NewProvider("test-key")
 
-	input := []any{
-		"user message",
-		openai.InputItem{Type: "message", Role: "assistant", Content: "assistant message"},
+	messages := []gopherai.Message{
+		gopherai.NewUserMessage("user message"),
+		gopherai.NewAssistantMessage("assistant message"),
 	}
 
-	req := provider.BuildRequest(input, "", []any{})
+	req := provider.BuildRequest(messages, "", []any{})
 	createReq := req.(*openai.CreateResponseRequest)
 
 	items, ok := createReq.Input.([]openai.InputItem)
@@ -212,19 +230,18 @@ func TestBuildRequest_ConvertsSliceInputToInputItems(t *testing.T) {
 	}
 }
 
-func TestCreateFunctionCallInput_CreatesInputItem(t *testing.T) {
-	provider := openai.NewProvider("test-key")
+func TestConvertMessages_CreatesFunctionCallInputItem(t *testing.T) {
 	call := gopherai.ToolCall{
 		Name:      "test_func",
 		Arguments: `{"arg":"val"}`,
 		CallID:    "call_123",
 	}
 
-	result := provider.CreateFunctionCallInput(call)
-	inputItem, ok := result.(openai.InputItem)
-	if !ok {
-		t.Fatal("expected result to be InputItem")
+	items := openai.ConvertMessages([]gopherai.Message{gopherai.NewAssistantMessage("", call)})
+	if len(items) != 1 {
+		t.Fatalf("expected 1 input item, got %d", len(items))
 	}
+	inputItem := items[0]
 
 	if inputItem.Type != "function_call" {
 		t.Errorf("expected type 'function_call', got '%s'", inputItem.Type)
@@ -237,16 +254,20 @@ func TestCreateFunctionCallInput_CreatesInputItem(t *testing.T) {
 	if inputItem.CallID != "call_123" {
 		t.Errorf("expected CallID 'call_123', got '%s'", inputItem.CallID)
 	}
+
+	if inputItem.Arguments != `{"arg":"val"}` {
+		t.Errorf("expected arguments '{\"arg\":\"val\"}', got '%s'", inputItem.Arguments)
+	}
 }
 
-func TestCreateFunctionCallOutput_CreatesOutputItem(t *testing.T) {
-	provider := openai.NewProvider("test-key")
-	result := provider.CreateFunctionCallOutput("call_123", "output value")
+func TestConvertMessages_CreatesFunctionCallOutputItem(t *testing.T) {
+	result := gopherai.ToolResult{CallID: "call_123", Name: "test_func", Output: "output value"}
 
-	inputItem, ok := result.(openai.InputItem)
-	if !ok {
-		t.Fatal("expected result to be InputItem")
+	items := openai.ConvertMessages([]gopherai.Message{gopherai.NewToolResultMessage(result)})
+	if len(items) != 1 {
+		t.Fatalf("expected 1 input item, got %d", len(items))
 	}
+	inputItem := items[0]
 
 	if inputItem.Type != "function_call_output" {
 		t.Errorf("expected type 'function_call_output', got '%s'", inputItem.Type)
@@ -261,14 +282,12 @@ func TestCreateFunctionCallOutput_CreatesOutputItem(t *testing.T) {
 	}
 }
 
-func TestCreateAssistantMessage_CreatesMessageItem(t *testing.T) {
-	provider := openai.NewProvider("test-key")
-	result := provider.CreateAssistantMessage("Hello!")
-
-	inputItem, ok := result.(openai.InputItem)
-	if !ok {
-		t.Fatal("expected result to be InputItem")
+func TestConvertMessages_CreatesAssistantMessageItem(t *testing.T) {
+	items := openai.ConvertMessages([]gopherai.Message{gopherai.NewAssistantMessage("Hello!")})
+	if len(items) != 1 {
+		t.Fatalf("expected 1 input item, got %d", len(items))
 	}
+	inputItem := items[0]
 
 	if inputItem.Type != "message" {
 		t.Errorf("expected type 'message', got '%s'", inputItem.Type)
@@ -283,6 +302,28 @@ func TestCreateAssistantMessage_CreatesMessageItem(t *testing.T) {
 	}
 }
 
+func TestConvertMessages_KeepsToolCallAndOutputOrder(t *testing.T) {
+	call := gopherai.ToolCall{Name: "lookup", Arguments: `{}`, CallID: "call_1"}
+	messages := []gopherai.Message{
+		gopherai.NewUserMessage("question"),
+		gopherai.NewAssistantMessage("", call),
+		gopherai.NewToolResultMessage(gopherai.ToolResult{CallID: "call_1", Name: "lookup", Output: "42"}),
+		gopherai.NewAssistantMessage("The answer is 42"),
+	}
+
+	items := openai.ConvertMessages(messages)
+
+	expectedTypes := []string{"message", "function_call", "function_call_output", "message"}
+	if len(items) != len(expectedTypes) {
+		t.Fatalf("expected %d input items, got %d", len(expectedTypes), len(items))
+	}
+	for i, expected := range expectedTypes {
+		if items[i].Type != expected {
+			t.Errorf("expected item %d type '%s', got '%s'", i, expected, items[i].Type)
+		}
+	}
+}
+
 func TestCreateResponseStream_ReturnsErrorForInvalidRequestType(t *testing.T) {
 	provider := openai.NewProvider("test-key")
 
@@ -405,9 +446,12 @@ func TestParseSSEStream_ParsesErrorEvents(t *testing.T) {
 		t.Fatal("expected Error to be set")
 	}
 
-	expectedErr := "rate_limit: Rate limit exceeded"
-	if errorEvent.Error.Error() != expectedErr {
-		t.Errorf("expected error '%s', got '%s'", expectedErr, errorEvent.Error.Error())
+	var apiErr *gopherai.APIError
+	if !errors.As(errorEvent.Error, &apiErr) {
+		t.Fatalf("expected *gopherai.APIError, got %T", errorEvent.Error)
+	}
+	if apiErr.Code != "rate_limit" || apiErr.Message != "Rate limit exceeded" {
+		t.Errorf("unexpected error: %+v", apiErr)
 	}
 }
 
@@ -433,3 +477,170 @@ data: [DONE]
 		t.Errorf("expected 2 events, got %d", count)
 	}
 }
+
+func TestConvertMessages_MakesCallIDsFromOtherProvidersUnique(t *testing.T) {
+	messages := []gopherai.Message{
+		gopherai.NewUserMessage("question"),
+		gopherai.NewAssistantMessage("", gopherai.ToolCall{Name: "get_weather", Arguments: `{}`, CallID: "0"}),
+		gopherai.NewToolResultMessage(gopherai.ToolResult{CallID: "0", Name: "get_weather", Output: "sunny"}),
+		gopherai.NewAssistantMessage("", gopherai.ToolCall{Name: "get_time", Arguments: `{}`, CallID: "0"}),
+		gopherai.NewToolResultMessage(gopherai.ToolResult{CallID: "0", Name: "get_time", Output: "noon"}),
+	}
+
+	items := openai.ConvertMessages(messages)
+	if len(items) != 5 {
+		t.Fatalf("expected 5 input items, got %d", len(items))
+	}
+
+	if items[1].CallID == items[3].CallID {
+		t.Errorf("expected unique call IDs, got '%s' twice", items[1].CallID)
+	}
+
+	if items[2].CallID != items[1].CallID {
+		t.Errorf("expected first output to match call '%s', got '%s'", items[1].CallID, items[2].CallID)
+	}
+
+	if items[4].CallID != items[3].CallID {
+		t.Errorf("expected second output to match call '%s', got '%s'", items[3].CallID, items[4].CallID)
+	}
+}
+
+func TestToMessages_ConvertsInputItemsToMessages(t *testing.T) {
+	items := []openai.InputItem{
+		{Type: "message", Role: "user", Content: "question"},
+		{Type: "function_call", CallID: "call_1", Name: "lookup", Arguments: `{"q":"x"}`},
+		{Type: "function_call", CallID: "call_2", Name: "lookup", Arguments: `{"q":"y"}`},
+		openai.NewFunctionCallOutput("call_1", "one"),
+		openai.NewFunctionCallOutput("call_2", "two"),
+		{Type: "message", Role: "assistant", Content: "answer"},
+	}
+
+	messages := openai.ToMessages(items)
+	if len(messages) != 4 {
+		t.Fatalf("expected 4 messages, got %d", len(messages))
+	}
+
+	calls := messages[1].ToolCalls()
+	if messages[1].Role != gopherai.RoleAssistant || len(calls) != 2 {
+		t.Fatalf("expected assistant message with 2 tool calls, got %v", messages[1])
+	}
+
+	results := messages[2].ToolResults()
+	if messages[2].Role != gopherai.RoleTool || len(results) != 2 {
+		t.Fatalf("expected tool message with 2 results, got %v", messages[2])
+	}
+
+	if results[1].Name != "lookup" || results[1].Output != "two" {
+		t.Errorf("expected second result from lookup with output 'two', got %v", results[1])
+	}
+
+	if messages[3].Text() != "answer" {
+		t.Errorf("expected final text 'answer', got '%s'", messages[3].Text())
+	}
+}
+
+func TestSetOutputSchema_SetsStrictJSONSchemaFormat(t *testing.T) {
+	provider := openai.NewProvider("test-key")
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", []any{})
+
+	err := provider.SetOutputSchema(req, gopherai.OutputSchema{
+		Name: "answer",
+		Schema: map[string]any{
+			"type":                 "object",
+			"properties":           map[string]any{"value": map[string]any{"type": "string"}},
+			"required":             []string{"value"},
+			"additionalProperties": false,
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	format := req.(*openai.CreateResponseRequest).Text.Format
+	if format.Type != "json_schema" || format.Name != "answer" {
+		t.Errorf("unexpected format: %+v", format)
+	}
+	if format.Strict == nil || !*format.Strict {
+		t.Error("expected strict mode for a schema with all properties required")
+	}
+}
+
+func TestSetOutputSchema_DisablesStrictForOptionalProperties(t *testing.T) {
+	provider := openai.NewProvider("test-key")
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", []any{})
+
+	err := provider.SetOutputSchema(req, gopherai.OutputSchema{
+		Name: "answer",
+		Schema: map[string]any{
+			"type":                 "object",
+			"properties":           map[string]any{"value": map[string]any{"type": "string"}},
+			"additionalProperties": false,
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	format := req.(*openai.CreateResponseRequest).Text.Format
+	if format.Strict == nil || *format.Strict {
+		t.Error("expected strict mode to be disabled")
+	}
+}
+
+func TestSetOutputSchema_ReturnsErrorForInvalidRequestType(t *testing.T) {
+	provider := openai.NewProvider("test-key")
+
+	if err := provider.SetOutputSchema("invalid", gopherai.OutputSchema{}); err == nil {
+		t.Error("expected error for invalid request type")
+	}
+}
+
+func TestConvertTool_DisablesStrictForOpenEndedSchemas(t *testing.T) {
+	provider := openai.NewProvider("test-key")
+	tool := gopherai.Tool{
+		Name: "test_tool",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"counts": map[string]any{
+					"type":                 "object",
+					"additionalProperties": map[string]any{"type": "integer"},
+				},
+			},
+			"required":             []string{"counts"},
+			"additionalProperties": false,
+		},
+	}
+
+	funcTool := provider.ConvertTool(tool).(openai.FunctionTool)
+	if funcTool.Strict == nil || *funcTool.Strict {
+		t.Error("expected strict to be false for a map parameter")
+	}
+}
+
+func TestParseSSEStream_EmitsUsageBeforeDone(t *testing.T) {
+	sseData := `data: {"type":"response.output_text.delta","delta":"Hi"}
+
+data: {"type":"response.completed","response":{"model":"gpt-4.1-2025-04-14","usage":{"input_tokens":100,"input_tokens_details":{"cached_tokens":40},"output_tokens":20,"output_tokens_details":{"reasoning_tokens":5},"total_tokens":120}}}
+
+`
+	var types []gopherai.StreamEventType
+	var usage *gopherai.Usage
+	for event := range openai.ParseSSEStreamForTest(strings.NewReader(sseData)) {
+		types = append(types, event.Type)
+		if event.Type == gopherai.StreamEventTypeUsage {
+			usage = event.Usage
+		}
+	}
+
+	if usage == nil {
+		t.Fatal("expected a usage event")
+	}
+	want := gopherai.Usage{Model: "gpt-4.1-2025-04-14", InputTokens: 100, CachedInputTokens: 40, OutputTokens: 20, ReasoningTokens: 5}
+	if *usage != want {
+		t.Errorf("expected %+v, got %+v", want, *usage)
+	}
+	if types[len(types)-1] != gopherai.StreamEventTypeDone || types[len(types)-2] != gopherai.StreamEventTypeUsage {
+		t.Errorf("expected usage before done, got %v", types)
+	}
+}
@@ -0,0 +1,150 @@
+package openai_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+	"github.com/marti-jorda-roca/gopher-ai/gopherai/openai"
+)
+
+func TestCreateResponse_RetriesRateLimitedRequests(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		if requests == 1 {
+			w.Header().Set("retry-after-ms", "10")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":{"message":"Rate limit reached","type":"requests"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"resp_1","output":[{"type":"message","role":"assistant","content":[{"type":"output_text","text":"ok"}]}]}`))
+	}))
+	defer server.Close()
+
+	provider := openai.NewProvider("test-key").
+		SetBaseURL(server.URL).
+		SetRetryPolicy(gopherai.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond})
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil)
+
+	resp, err := provider.CreateResponse(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests != 2 || provider.ExtractText(resp) != "ok" {
+		t.Errorf("expected success after one retry, got %d requests", requests)
+	}
+}
+
+func TestCreateResponse_ReturnsLastErrorWhenRetriesRunOut(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"error":{"message":"The server is overloaded","type":"server_error"}}`))
+	}))
+	defer server.Close()
+
+	provider := openai.NewProvider("test-key").
+		SetBaseURL(server.URL).
+		SetRetryPolicy(gopherai.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond})
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil)
+
+	_, err := provider.CreateResponse(context.Background(), req)
+	if err == nil || !strings.Contains(err.Error(), "overloaded") {
+		t.Errorf("expected overloaded error, got %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+}
+
+func TestCreateResponseStream_RetriesBeforeFirstEvent(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: {\"type\":\"response.output_text.delta\",\"delta\":\"ok\"}\n\ndata: {\"type\":\"response.completed\"}\n\n"))
+	}))
+	defer server.Close()
+
+	provider := openai.NewProvider("test-key").
+		SetBaseURL(server.URL).
+		SetRetryPolicy(gopherai.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond})
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil)
+
+	events, err := provider.CreateResponseStream(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for event := range events {
+		if event.Type == gopherai.StreamEventTypeError {
+			t.Fatalf("unexpected error event: %v", event.Error)
+		}
+		if event.Type == gopherai.StreamEventTypeDone {
+			break
+		}
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+}
+
+func quotaExceededServer(requests *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		*requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error":{"message":"You exceeded your current quota","type":"insufficient_quota","code":"insufficient_quota"}}`))
+	}))
+}
+
+func TestCreateResponse_DoesNotRetryExhaustedQuota(t *testing.T) {
+	requests := 0
+	server := quotaExceededServer(&requests)
+	defer server.Close()
+
+	provider := openai.NewProvider("test-key").
+		SetBaseURL(server.URL).
+		SetRetryPolicy(gopherai.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond})
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil)
+
+	_, err := provider.CreateResponse(context.Background(), req)
+	var apiErr *gopherai.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "insufficient_quota" || apiErr.Retryable {
+		t.Fatalf("expected a non-retryable insufficient_quota error, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 request, got %d", requests)
+	}
+}
+
+func TestCreateResponseStream_DoesNotRetryExhaustedQuota(t *testing.T) {
+	requests := 0
+	server := quotaExceededServer(&requests)
+	defer server.Close()
+
+	provider := openai.NewProvider("test-key").
+		SetBaseURL(server.URL).
+		SetRetryPolicy(gopherai.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond})
+	req := provider.BuildRequest([]gopherai.Message{gopherai.NewUserMessage("hi")}, "", nil)
+
+	_, err := provider.CreateResponseStream(context.Background(), req)
+	if !errors.Is(err, gopherai.ErrRateLimited) || !strings.Contains(err.Error(), "quota") {
+		t.Fatalf("expected the quota error, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 request, got %d", requests)
+	}
+}
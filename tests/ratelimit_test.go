@@ -0,0 +1,213 @@
+package gopherai_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+)
+
+func withTimeout(t *testing.T, timeout time.Duration) context.Context {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestRateLimitedProvider_LimitsInFlightRequests(t *testing.T) {
+	mock := &mockScriptedProvider{delay: 20 * time.Millisecond}
+	provider := gopherai.NewRateLimitedProvider(mock, gopherai.NewRateLimiter(gopherai.RateLimits{MaxInFlight: 2}))
+
+	var wg sync.WaitGroup
+	for range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := provider.CreateResponse(context.Background(), &mockRequest{}); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if peak := mock.peak.Load(); peak != 2 {
+		t.Errorf("expected at most 2 requests in flight, got %d", peak)
+	}
+}
+
+func TestRateLimitedProvider_WaitsForRequestsPerMinute(t *testing.T) {
+	provider := gopherai.NewRateLimitedProvider(&mockScriptedProvider{}, gopherai.NewRateLimiter(gopherai.RateLimits{RequestsPerMinute: 1}))
+
+	if _, err := provider.CreateResponse(context.Background(), &mockRequest{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err := provider.CreateResponse(withTimeout(t, 20*time.Millisecond), &mockRequest{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the second request to wait until the deadline, got %v", err)
+	}
+}
+
+func TestRateLimitedProvider_WaitsForTokensPerMinute(t *testing.T) {
+	provider := gopherai.NewRateLimitedProvider(&mockScriptedProvider{}, gopherai.NewRateLimiter(gopherai.RateLimits{TokensPerMinute: 100})).
+		SetTokenEstimator(func(any) int { return 60 })
+
+	if _, err := provider.CreateResponse(context.Background(), &mockRequest{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err := provider.CreateResponse(withTimeout(t, 20*time.Millisecond), &mockRequest{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the second request to wait until the deadline, got %v", err)
+	}
+}
+
+func TestRateLimitedProvider_SettlesTokensFromUsage(t *testing.T) {
+	provider := gopherai.NewRateLimitedProvider(&mockScriptedProvider{then: answeringTurns("done", &gopherai.Usage{InputTokens: 10})}, gopherai.NewRateLimiter(gopherai.RateLimits{TokensPerMinute: 100})).
+		SetTokenEstimator(func(any) int { return 60 })
+
+	for range 2 {
+		if _, err := provider.CreateResponse(withTimeout(t, 20*time.Millisecond), &mockRequest{}); err != nil {
+			t.Fatalf("expected the reported usage to free capacity, got %v", err)
+		}
+	}
+}
+
+func TestRateLimitedProvider_AllowsOversizedRequestInEmptyWindow(t *testing.T) {
+	provider := gopherai.NewRateLimitedProvider(&mockScriptedProvider{}, gopherai.NewRateLimiter(gopherai.RateLimits{TokensPerMinute: 100})).
+		SetTokenEstimator(func(any) int { return 500 })
+
+	if _, err := provider.CreateResponse(withTimeout(t, 20*time.Millisecond), &mockRequest{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRateLimitedProvider_SharesLimiterAcrossProviders(t *testing.T) {
+	limiter := gopherai.NewRateLimiter(gopherai.RateLimits{RequestsPerMinute: 1})
+	first := gopherai.NewRateLimitedProvider(&mockScriptedProvider{}, limiter)
+	second := gopherai.NewRateLimitedProvider(&mockScriptedProvider{}, limiter)
+
+	if _, err := first.CreateResponse(context.Background(), &mockRequest{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := second.CreateResponse(withTimeout(t, 20*time.Millisecond), &mockRequest{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the shared limit to apply, got %v", err)
+	}
+}
+
+// mockOpenStreamProvider streams one delta, then ends the stream once finish
+// is closed.
+type mockOpenStreamProvider struct {
+	mockProvider
+	finish chan struct{}
+}
+
+func (m *mockOpenStreamProvider) CreateResponseStream(_ context.Context, _ any) (<-chan gopherai.StreamEvent, error) {
+	events := make(chan gopherai.StreamEvent)
+	go func() {
+		defer close(events)
+		events <- gopherai.StreamEvent{Type: gopherai.StreamEventTypeTextDelta, Delta: "hi"}
+		<-m.finish
+		events <- gopherai.StreamEvent{Type: gopherai.StreamEventTypeDone}
+	}()
+	return events, nil
+}
+
+func TestRateLimitedProvider_StreamHoldsSlotUntilDone(t *testing.T) {
+	mock := &mockOpenStreamProvider{finish: make(chan struct{})}
+	provider := gopherai.NewRateLimitedProvider(mock, gopherai.NewRateLimiter(gopherai.RateLimits{MaxInFlight: 1}))
+
+	events, err := provider.CreateResponseStream(context.Background(), &mockRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	<-events
+
+	if _, err := provider.CreateResponse(withTimeout(t, 20*time.Millisecond), &mockRequest{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the open stream to hold the slot, got %v", err)
+	}
+
+	close(mock.finish)
+	for range events {
+	}
+	if _, err := provider.CreateResponse(withTimeout(t, time.Second), &mockRequest{}); err != nil {
+		t.Fatalf("expected the finished stream to free the slot, got %v", err)
+	}
+}
+
+func TestRateLimitedProvider_WorksWithAgent(t *testing.T) {
+	provider := gopherai.NewRateLimitedProvider(&mockProvider{text: "ok"}, gopherai.NewRateLimiter(gopherai.RateLimits{RequestsPerMinute: 10}))
+
+	result, err := gopherai.NewAgent(provider).Run(context.Background(), "hi")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Text != "ok" {
+		t.Errorf("expected %q, got %q", "ok", result.Text)
+	}
+}
+
+func TestRateLimitedProvider_StreamsFromNonStreamingProvider(t *testing.T) {
+	provider := gopherai.NewRateLimitedProvider(&mockProvider{text: "ok"}, gopherai.NewRateLimiter(gopherai.RateLimits{RequestsPerMinute: 10}))
+
+	events, err := gopherai.NewAgent(provider).RunStream(context.Background(), "hi")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var text string
+	for event := range events {
+		switch event.Type {
+		case gopherai.StreamEventTypeError:
+			t.Fatalf("unexpected error event: %v", event.Error)
+		case gopherai.StreamEventTypeTextDone:
+			text = event.Text
+		}
+	}
+	if text != "ok" {
+		t.Errorf("expected %q, got %q", "ok", text)
+	}
+}
+
+func TestRateLimitedProvider_ReportsWrappedProviderCapabilities(t *testing.T) {
+	provider := gopherai.NewRateLimitedProvider(&mockProvider{text: "ok"}, gopherai.NewRateLimiter(gopherai.RateLimits{RequestsPerMinute: 10}))
+
+	if err := provider.SetOutputSchema(&mockRequest{}, gopherai.OutputSchema{Name: "out"}); err == nil || !strings.Contains(err.Error(), "does not support structured output") {
+		t.Errorf("expected structured output not to be supported, got %v", err)
+	}
+	resp, err := provider.CreateResponse(context.Background(), &mockRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := provider.ExtractUsage(resp); ok {
+		t.Error("expected no usage from a provider that does not report it")
+	}
+	if name := provider.RespondedBy(resp); name != "" {
+		t.Errorf("expected no routed provider, got %q", name)
+	}
+}
+
+func TestFallbackProvider_StreamsFromRateLimitedNonStreamingProvider(t *testing.T) {
+	limited := gopherai.NewRateLimitedProvider(&mockProvider{text: "limited"}, gopherai.NewRateLimiter(gopherai.RateLimits{RequestsPerMinute: 10}))
+	provider := gopherai.NewFallbackProvider().Add("limited", limited)
+
+	events, err := gopherai.NewAgent(provider).RunStream(context.Background(), "hi")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var text string
+	for event := range events {
+		switch event.Type {
+		case gopherai.StreamEventTypeError:
+			t.Fatalf("unexpected error event: %v", event.Error)
+		case gopherai.StreamEventTypeTextDone:
+			text = event.Text
+		}
+	}
+	if text != "limited" {
+		t.Errorf("expected %q, got %q", "limited", text)
+	}
+}
@@ -1,7 +1,12 @@
 package gopherai_test
 
 import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/marti-jorda-roca/gopher-ai/gopherai"
 )
@@ -191,3 +196,472 @@ func TestNewTool_SkipsFieldsWithoutJSONTag(t *testing.T) {
 		t.Error("expected field without json tag to be skipped")
 	}
 }
+
+type schemaAddress struct {
+	Street string `json:"street"`
+	City   string `json:"city"`
+}
+
+type schemaTreeNode struct {
+	Value    string           `json:"value"`
+	Children []schemaTreeNode `json:"children"`
+}
+
+type schemaTreeParams struct {
+	Root schemaTreeNode `json:"root"`
+}
+
+type schemaCategory struct {
+	Name   string          `json:"name"`
+	Parent *schemaCategory `json:"parent"`
+}
+
+type schemaBase struct {
+	ID string `json:"id"`
+}
+
+func toolProperty(t *testing.T, tool gopherai.Tool, name string) map[string]any {
+	t.Helper()
+	props := tool.Parameters["properties"].(map[string]any)
+	prop, ok := props[name].(map[string]any)
+	if !ok {
+		t.Fatalf("expected property '%s', got %v", name, props)
+	}
+	return prop
+}
+
+func TestNewTool_GeneratesNestedStructSchemas(t *testing.T) {
+	type testParams struct {
+		Home      schemaAddress   `json:"home"`
+		Addresses []schemaAddress `json:"addresses"`
+	}
+
+	tool := gopherai.NewTool("test", "description", func(_ testParams) (string, error) {
+		return "", nil
+	})
+
+	home := toolProperty(t, tool, "home")
+	if home["type"] != "object" {
+		t.Errorf("expected home type 'object', got '%v'", home["type"])
+	}
+	homeProps := home["properties"].(map[string]any)
+	if _, ok := homeProps["street"]; !ok {
+		t.Errorf("expected nested 'street' property, got %v", homeProps)
+	}
+
+	items := toolProperty(t, tool, "addresses")["items"].(map[string]any)
+	if items["type"] != "object" || items["additionalProperties"] != false {
+		t.Errorf("expected object items, got %v", items)
+	}
+	if required := items["required"].([]string); len(required) != 2 {
+		t.Errorf("expected 2 required item fields, got %v", required)
+	}
+}
+
+func TestNewTool_GeneratesMapSchemaWithAdditionalProperties(t *testing.T) {
+	type testParams struct {
+		Counts map[string]int `json:"counts"`
+	}
+
+	tool := gopherai.NewTool("test", "description", func(_ testParams) (string, error) {
+		return "", nil
+	})
+
+	counts := toolProperty(t, tool, "counts")
+	if counts["type"] != "object" {
+		t.Errorf("expected type 'object', got '%v'", counts["type"])
+	}
+	additional, ok := counts["additionalProperties"].(map[string]any)
+	if !ok || additional["type"] != "integer" {
+		t.Errorf("expected integer additionalProperties, got %v", counts["additionalProperties"])
+	}
+}
+
+func TestNewTool_PromotesEmbeddedStructFields(t *testing.T) {
+	type testParams struct {
+		schemaBase
+		Name string `json:"name"`
+	}
+
+	tool := gopherai.NewTool("test", "description", func(_ testParams) (string, error) {
+		return "", nil
+	})
+
+	toolProperty(t, tool, "id")
+	toolProperty(t, tool, "name")
+
+	required := tool.Parameters["required"].([]string)
+	if len(required) != 2 {
+		t.Errorf("expected 2 required fields, got %v", required)
+	}
+}
+
+func TestNewTool_GeneratesSchemasForTimeAndRawMessage(t *testing.T) {
+	type testParams struct {
+		When  time.Time       `json:"when"`
+		Extra json.RawMessage `json:"extra"`
+	}
+
+	tool := gopherai.NewTool("test", "description", func(_ testParams) (string, error) {
+		return "", nil
+	})
+
+	when := toolProperty(t, tool, "when")
+	if when["type"] != "string" || when["format"] != "date-time" {
+		t.Errorf("expected date-time string, got %v", when)
+	}
+
+	extra := toolProperty(t, tool, "extra")
+	if len(extra) != 0 {
+		t.Errorf("expected an unconstrained schema for json.RawMessage, got %v", extra)
+	}
+}
+
+func TestNewTool_UsesDefsForRecursiveTypes(t *testing.T) {
+	tool := gopherai.NewTool("test", "description", func(_ schemaTreeParams) (string, error) {
+		return "", nil
+	})
+
+	root := toolProperty(t, tool, "root")
+	if root["$ref"] != "#/$defs/schemaTreeNode" {
+		t.Errorf("expected $ref to schemaTreeNode, got %v", root)
+	}
+
+	defs := tool.Parameters["$defs"].(map[string]any)
+	node, ok := defs["schemaTreeNode"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected schemaTreeNode definition, got %v", defs)
+	}
+	children := node["properties"].(map[string]any)["children"].(map[string]any)
+	items := children["items"].(map[string]any)
+	if items["$ref"] != "#/$defs/schemaTreeNode" {
+		t.Errorf("expected children items to reference schemaTreeNode, got %v", items)
+	}
+}
+
+func TestNewTool_ReferencesRootForSelfReferentialParams(t *testing.T) {
+	tool := gopherai.NewTool("test", "description", func(_ schemaCategory) (string, error) {
+		return "", nil
+	})
+
+	parent := toolProperty(t, tool, "parent")
+	if parent["$ref"] != "#" {
+		t.Errorf("expected $ref to the root schema, got %v", parent)
+	}
+	if _, ok := tool.Parameters["$defs"]; ok {
+		t.Error("expected no $defs for a type referencing only itself")
+	}
+}
+
+type validatedParams struct {
+	Name  string   `json:"name" minLength:"2" maxLength:"10" pattern:"^[a-z]+$" example:"alice"`
+	Age   int      `json:"age" minimum:"0" maximum:"150" default:"30"`
+	Email string   `json:"email" format:"email"`
+	Tags  []string `json:"tags" minItems:"1" maxItems:"3"`
+}
+
+func validatedTool(called *bool) gopherai.Tool {
+	return gopherai.NewTool("register", "registers a user", func(_ validatedParams) (string, error) {
+		*called = true
+		return "ok", nil
+	})
+}
+
+func TestNewTool_IncludesConstraintTagsInSchema(t *testing.T) {
+	var called bool
+	tool := validatedTool(&called)
+
+	name := toolProperty(t, tool, "name")
+	if name["minLength"] != 2 || name["maxLength"] != 10 || name["pattern"] != "^[a-z]+$" {
+		t.Errorf("unexpected name constraints: %v", name)
+	}
+	if examples, ok := name["examples"].([]any); !ok || examples[0] != "alice" {
+		t.Errorf("expected example 'alice', got %v", name["examples"])
+	}
+
+	age := toolProperty(t, tool, "age")
+	if age["minimum"] != 0.0 || age["maximum"] != 150.0 {
+		t.Errorf("unexpected age constraints: %v", age)
+	}
+	if age["default"] != 30.0 {
+		t.Errorf("expected numeric default 30, got %v (%T)", age["default"], age["default"])
+	}
+
+	if toolProperty(t, tool, "email")["format"] != "email" {
+		t.Error("expected email format")
+	}
+
+	tags := toolProperty(t, tool, "tags")
+	if tags["minItems"] != 1 || tags["maxItems"] != 3 {
+		t.Errorf("unexpected tags constraints: %v", tags)
+	}
+}
+
+func TestNewTool_PanicsForInvalidConstraintTag(t *testing.T) {
+	type testParams struct {
+		Value int `json:"value" minimum:"low"`
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected panic for invalid minimum tag")
+		}
+	}()
+
+	gopherai.NewTool("test", "description", func(_ testParams) (string, error) {
+		return "", nil
+	})
+}
+
+func TestNewTool_HandlerAcceptsValidArguments(t *testing.T) {
+	var called bool
+	tool := validatedTool(&called)
+
+	_, err := tool.Handler(`{"name":"alice","age":30,"email":"alice@example.com","tags":["a"]}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !called {
+		t.Error("expected function to be called")
+	}
+}
+
+func TestNewTool_HandlerRejectsConstraintViolations(t *testing.T) {
+	var called bool
+	tool := validatedTool(&called)
+
+	_, err := tool.Handler(`{"name":"Al","age":-1,"email":"not-an-email","tags":[]}`)
+	if !errors.Is(err, gopherai.ErrInvalidArguments) {
+		t.Fatalf("expected ErrInvalidArguments, got %v", err)
+	}
+	if called {
+		t.Error("expected function not to be called")
+	}
+
+	var validationErr *gopherai.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected ValidationError, got %T", err)
+	}
+
+	constraints := make(map[string]string)
+	for _, v := range validationErr.Violations {
+		constraints[v.Path] = v.Constraint
+	}
+	expected := map[string]string{
+		"$.name":  "pattern",
+		"$.age":   "minimum",
+		"$.email": "format",
+		"$.tags":  "minItems",
+	}
+	for path, constraint := range expected {
+		if constraints[path] != constraint {
+			t.Errorf("expected %s violation at %s, got %v", constraint, path, validationErr.Violations)
+		}
+	}
+}
+
+func TestNewTool_HandlerRejectsUnknownProperties(t *testing.T) {
+	var called bool
+	tool := validatedTool(&called)
+
+	_, err := tool.Handler(`{"name":"alice","age":30,"email":"alice@example.com","tags":["a"],"nickname":"al"}`)
+
+	var validationErr *gopherai.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	if called {
+		t.Error("expected function not to be called")
+	}
+	want := []gopherai.Violation{{Path: "$.nickname", Constraint: "additionalProperties", Message: "is not an allowed property"}}
+	if !reflect.DeepEqual(validationErr.Violations, want) {
+		t.Errorf("expected %v, got %v", want, validationErr.Violations)
+	}
+}
+
+func TestNewTool_HandlerRejectsWrongTypesAndMissingFields(t *testing.T) {
+	var called bool
+	tool := validatedTool(&called)
+
+	_, err := tool.Handler(`{"name":"alice","age":"thirty","tags":["a"]}`)
+
+	var validationErr *gopherai.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "$.age: must be of type integer") {
+		t.Errorf("expected type violation for age, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "$.email: is required") {
+		t.Errorf("expected required violation for email, got %v", err)
+	}
+}
+
+func TestNewTool_HandlerValidatesNestedValues(t *testing.T) {
+	type item struct {
+		Quantity int `json:"quantity" minimum:"1"`
+	}
+	type testParams struct {
+		Items []item `json:"items"`
+	}
+
+	tool := gopherai.NewTool("order", "description", func(_ testParams) (string, error) {
+		return "", nil
+	})
+
+	_, err := tool.Handler(`{"items":[{"quantity":2},{"quantity":0}]}`)
+	if err == nil || !strings.Contains(err.Error(), "$.items[1].quantity") {
+		t.Errorf("expected violation at $.items[1].quantity, got %v", err)
+	}
+}
+
+type schemaOrderID string
+
+func (schemaOrderID) JSONSchema() map[string]any {
+	return map[string]any{"type": "string", "pattern": "^ord_[0-9]+$"}
+}
+
+type schemaCircle struct {
+	Kind   string  `json:"kind" enum:"circle"`
+	Radius float64 `json:"radius"`
+}
+
+type schemaSquare struct {
+	Kind string  `json:"kind" enum:"square"`
+	Side float64 `json:"side"`
+}
+
+// schemaShape is a tagged union of schemaCircle and schemaSquare.
+type schemaShape struct {
+	Circle *schemaCircle
+	Square *schemaSquare
+}
+
+func (*schemaShape) JSONSchema() map[string]any {
+	return gopherai.OneOf(schemaCircle{}, schemaSquare{})
+}
+
+func (s *schemaShape) UnmarshalJSON(data []byte) error {
+	var kind struct {
+		Kind string `json:"kind"`
+	}
+	if err := json.Unmarshal(data, &kind); err != nil {
+		return err
+	}
+	if kind.Kind == "circle" {
+		s.Circle = &schemaCircle{}
+		return json.Unmarshal(data, s.Circle)
+	}
+	s.Square = &schemaSquare{}
+	return json.Unmarshal(data, s.Square)
+}
+
+type schemaDrawParams struct {
+	Order  schemaOrderID `json:"order" description:"The order to draw for"`
+	Shapes []schemaShape `json:"shapes"`
+}
+
+func TestNewTool_UsesCustomSchemaAtAnyDepth(t *testing.T) {
+	type nested struct {
+		IDs map[string]schemaOrderID `json:"ids"`
+	}
+	type testParams struct {
+		Order  schemaOrderID `json:"order" description:"The order"`
+		Nested nested        `json:"nested"`
+	}
+
+	tool := gopherai.NewTool("test", "description", func(_ testParams) (string, error) {
+		return "", nil
+	})
+
+	order := toolProperty(t, tool, "order")
+	if order["pattern"] != "^ord_[0-9]+$" || order["description"] != "The order" {
+		t.Errorf("expected custom schema with description, got %v", order)
+	}
+
+	ids := toolProperty(t, tool, "nested")["properties"].(map[string]any)["ids"].(map[string]any)
+	additional := ids["additionalProperties"].(map[string]any)
+	if additional["pattern"] != "^ord_[0-9]+$" {
+		t.Errorf("expected custom schema for map values, got %v", additional)
+	}
+}
+
+func TestNewTool_DescribesTaggedUnionsWithOneOf(t *testing.T) {
+	tool := gopherai.NewTool("draw", "description", func(_ schemaDrawParams) (string, error) {
+		return "", nil
+	})
+
+	items := toolProperty(t, tool, "shapes")["items"].(map[string]any)
+	variants, ok := items["oneOf"].([]any)
+	if !ok || len(variants) != 2 {
+		t.Fatalf("expected 2 oneOf variants, got %v", items)
+	}
+	circle := variants[0].(map[string]any)
+	if _, ok := circle["properties"].(map[string]any)["radius"]; !ok {
+		t.Errorf("expected circle variant, got %v", circle)
+	}
+}
+
+func TestNewTool_ValidatesOneOfVariants(t *testing.T) {
+	var drawn []schemaShape
+	tool := gopherai.NewTool("draw", "description", func(p schemaDrawParams) (string, error) {
+		drawn = p.Shapes
+		return "", nil
+	})
+
+	_, err := tool.Handler(`{"order":"ord_1","shapes":[{"kind":"circle","radius":2},{"kind":"square","side":3}]}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(drawn) != 2 || drawn[0].Circle == nil || drawn[1].Square == nil {
+		t.Errorf("expected a circle and a square, got %+v", drawn)
+	}
+
+	_, err = tool.Handler(`{"order":"ord_1","shapes":[{"kind":"triangle","side":3}]}`)
+	if err == nil || !strings.Contains(err.Error(), "$.shapes[0]: must match exactly one") {
+		t.Errorf("expected oneOf violation, got %v", err)
+	}
+
+	_, err = tool.Handler(`{"order":"42","shapes":[]}`)
+	if err == nil || !strings.Contains(err.Error(), "$.order: must match the pattern") {
+		t.Errorf("expected pattern violation from the custom schema, got %v", err)
+	}
+}
+
+func TestAnyOf_HoistsVariantDefinitions(t *testing.T) {
+	schema := gopherai.AnyOf(schemaTreeNode{}, map[string]any{"type": "string"})
+
+	variants := schema["anyOf"].([]any)
+	if len(variants) != 2 {
+		t.Fatalf("expected 2 variants, got %v", schema)
+	}
+	if _, ok := variants[0].(map[string]any)["$defs"]; ok {
+		t.Error("expected variant definitions to be hoisted")
+	}
+	defs, ok := schema["$defs"].(map[string]any)
+	if !ok || defs["schemaTreeNode"] == nil {
+		t.Errorf("expected schemaTreeNode definition at the union level, got %v", schema["$defs"])
+	}
+}
+
+func TestNewTool_ParsesEnumValuesOfNonStringFields(t *testing.T) {
+	type testParams struct {
+		Level int `json:"level" enum:"1,2,3"`
+	}
+
+	tool := gopherai.NewTool("test", "description", func(_ testParams) (string, error) {
+		return "", nil
+	})
+
+	enum, ok := toolProperty(t, tool, "level")["enum"].([]any)
+	if !ok || len(enum) != 3 || enum[0] != 1.0 {
+		t.Fatalf("expected numeric enum values, got %v", toolProperty(t, tool, "level")["enum"])
+	}
+
+	if _, err := tool.Handler(`{"level":2}`); err != nil {
+		t.Errorf("unexpected error for a valid level: %v", err)
+	}
+	if _, err := tool.Handler(`{"level":4}`); !errors.Is(err, gopherai.ErrInvalidArguments) {
+		t.Errorf("expected ErrInvalidArguments for an invalid level, got %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package gopherai_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+)
+
+// registeredConfig records the config passed to the "mock" scheme's factory.
+var registeredConfig gopherai.ProviderConfig
+
+func init() {
+	gopherai.RegisterProvider("mock", gopherai.ProviderRegistration{
+		EnvKeys: []string{"GOPHERAI_MOCK_KEY"},
+		Params:  []string{"region"},
+		New: func(cfg gopherai.ProviderConfig) (gopherai.Provider, error) {
+			registeredConfig = cfg
+			return &mockProvider{}, nil
+		},
+	})
+}
+
+func TestNewProviderFromURI_ParsesModelAndParameters(t *testing.T) {
+	t.Setenv("GOPHERAI_MOCK_KEY", "secret")
+
+	provider, err := gopherai.NewProviderFromURI("mock:model:8b?temperature=0.2&max_tokens=100&base_url=http://localhost&region=eu")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := provider.(*mockProvider); !ok {
+		t.Fatalf("expected mock provider, got %T", provider)
+	}
+
+	cfg := registeredConfig
+	if cfg.Model != "model:8b" {
+		t.Errorf("expected model 'model:8b', got %q", cfg.Model)
+	}
+	if cfg.APIKey != "secret" {
+		t.Errorf("expected API key from environment, got %q", cfg.APIKey)
+	}
+	if cfg.Temperature == nil || *cfg.Temperature != 0.2 {
+		t.Errorf("expected temperature 0.2, got %v", cfg.Temperature)
+	}
+	if cfg.MaxTokens == nil || *cfg.MaxTokens != 100 {
+		t.Errorf("expected max tokens 100, got %v", cfg.MaxTokens)
+	}
+	if cfg.BaseURL != "http://localhost" {
+		t.Errorf("expected base URL, got %q", cfg.BaseURL)
+	}
+	if cfg.Params.Get("region") != "eu" {
+		t.Errorf("expected region parameter, got %v", cfg.Params)
+	}
+}
+
+func TestNewProviderFromURI_ReadsNamedKeyVariable(t *testing.T) {
+	t.Setenv("GOPHERAI_MOCK_KEY", "")
+	t.Setenv("TEAM_KEY", "team-secret")
+
+	if _, err := gopherai.NewProviderFromURI("mock?api_key_env=TEAM_KEY"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if registeredConfig.APIKey != "team-secret" || registeredConfig.Model != "" {
+		t.Errorf("unexpected config: %+v", registeredConfig)
+	}
+}
+
+func TestNewProviderFromURI_ReturnsErrors(t *testing.T) {
+	t.Setenv("GOPHERAI_MOCK_KEY", "secret")
+
+	tests := map[string]string{
+		"mock:m?unknown=1":         "unknown parameter",
+		"mock:m?temperature=warm":  "invalid temperature",
+		"mock:m?max_tokens=lots":   "invalid max_tokens",
+		":m":                       "missing scheme",
+		"mock:m?api_key_env=UNSET": "missing API key",
+	}
+	for uri, want := range tests {
+		_, err := gopherai.NewProviderFromURI(uri)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("NewProviderFromURI(%q) = %v, want error containing %q", uri, err, want)
+		}
+	}
+}
+
+func TestNewProviderFromURI_ReturnsUnknownProvider(t *testing.T) {
+	_, err := gopherai.NewProviderFromURI("nope:model")
+	if !errors.Is(err, gopherai.ErrUnknownProvider) {
+		t.Errorf("expected ErrUnknownProvider, got %v", err)
+	}
+}
+
+func TestRegisterProvider_PanicsOnDuplicateScheme(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	gopherai.RegisterProvider("mock", gopherai.ProviderRegistration{
+		New: func(gopherai.ProviderConfig) (gopherai.Provider, error) { return &mockProvider{}, nil },
+	})
+}
+
+func TestRegisteredProviders_ListsSchemes(t *testing.T) {
+	if !slices.Contains(gopherai.RegisteredProviders(), "mock") {
+		t.Errorf("expected mock scheme, got %v", gopherai.RegisteredProviders())
+	}
+}
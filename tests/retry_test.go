@@ -0,0 +1,218 @@
+package gopherai_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+)
+
+func fastRetryPolicy(attempts int) gopherai.RetryPolicy {
+	return gopherai.RetryPolicy{
+		MaxAttempts:    attempts,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+	}
+}
+
+func serveStatuses(t *testing.T, statuses ...int) (*httptest.Server, *int) {
+	t.Helper()
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		status := statuses[min(calls, len(statuses)-1)]
+		calls++
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func doGet(ctx context.Context, url string) func() (*http.Response, error) {
+	return func() (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		return http.DefaultClient.Do(req)
+	}
+}
+
+func TestRetryPolicy_RetriesRetryableStatuses(t *testing.T) {
+	server, calls := serveStatuses(t, http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK)
+
+	var last *http.Response
+	send := doGet(context.Background(), server.URL)
+	err := fastRetryPolicy(4).Do(context.Background(), func() (*http.Response, error) {
+		resp, err := send()
+		last = resp
+		return resp, err
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func() { _ = last.Body.Close() }()
+	if *calls != 3 || last.StatusCode != http.StatusOK {
+		t.Errorf("expected success on the third attempt, got %d calls and status %d", *calls, last.StatusCode)
+	}
+}
+
+func TestRetryPolicy_StopsAtMaxAttempts(t *testing.T) {
+	server, calls := serveStatuses(t, http.StatusInternalServerError)
+
+	var last *http.Response
+	send := doGet(context.Background(), server.URL)
+	_ = fastRetryPolicy(3).Do(context.Background(), func() (*http.Response, error) {
+		resp, err := send()
+		last = resp
+		return resp, err
+	})
+	defer func() { _ = last.Body.Close() }()
+	if *calls != 3 || last.StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected 3 attempts ending in 500, got %d calls and status %d", *calls, last.StatusCode)
+	}
+}
+
+func TestRetryPolicy_DoesNotRetryClientErrors(t *testing.T) {
+	server, calls := serveStatuses(t, http.StatusBadRequest, http.StatusOK)
+
+	send := doGet(context.Background(), server.URL)
+	_ = fastRetryPolicy(4).Do(context.Background(), func() (*http.Response, error) {
+		resp, err := send()
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+		return resp, err
+	})
+	if *calls != 1 {
+		t.Errorf("expected a single attempt, got %d", *calls)
+	}
+}
+
+func TestRetryPolicy_ZeroValueMakesOneAttempt(t *testing.T) {
+	server, calls := serveStatuses(t, http.StatusServiceUnavailable, http.StatusOK)
+
+	send := doGet(context.Background(), server.URL)
+	_ = gopherai.RetryPolicy{}.Do(context.Background(), func() (*http.Response, error) {
+		resp, err := send()
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+		return resp, err
+	})
+	if *calls != 1 {
+		t.Errorf("expected a single attempt, got %d", *calls)
+	}
+}
+
+func TestRetryPolicy_RetriesNetworkErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	attempts := 0
+	send := doGet(context.Background(), url)
+	err := fastRetryPolicy(3).Do(context.Background(), func() (*http.Response, error) {
+		attempts++
+		return send()
+	})
+	if err == nil || attempts != 3 {
+		t.Errorf("expected 3 failed attempts, got %d and %v", attempts, err)
+	}
+}
+
+func TestRetryPolicy_DoesNotRetryOtherErrors(t *testing.T) {
+	errSign := errors.New("signing failed")
+
+	attempts := 0
+	err := fastRetryPolicy(3).Do(context.Background(), func() (*http.Response, error) {
+		attempts++
+		return nil, errSign
+	})
+	if !errors.Is(err, errSign) || attempts != 1 {
+		t.Errorf("expected one attempt returning the error, got %d and %v", attempts, err)
+	}
+}
+
+func TestRetryPolicy_GivesUpWhenDelayPassesDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	attempts := 0
+	send := doGet(ctx, server.URL)
+	start := time.Now()
+	_ = fastRetryPolicy(3).Do(ctx, func() (*http.Response, error) {
+		attempts++
+		resp, err := send()
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+		return resp, err
+	})
+	if attempts != 1 || time.Since(start) > 500*time.Millisecond {
+		t.Errorf("expected to give up at once, got %d attempts after %v", attempts, time.Since(start))
+	}
+}
+
+func TestRetryDelay_ReadsServerHeaders(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+		ok     bool
+	}{
+		{"retry-after seconds", http.Header{"Retry-After": {"3"}}, 3 * time.Second, true},
+		{"retry-after date", http.Header{"Retry-After": {now.Add(5 * time.Second).Format(http.TimeFormat)}}, 5 * time.Second, true},
+		{"retry-after-ms", http.Header{"Retry-After-Ms": {"250"}, "Retry-After": {"1"}}, 250 * time.Millisecond, true},
+		{
+			"exhausted rate limit",
+			http.Header{
+				"X-Ratelimit-Remaining-Requests": {"0"},
+				"X-Ratelimit-Reset-Requests":     {"1.5s"},
+				"X-Ratelimit-Remaining-Tokens":   {"1200"},
+				"X-Ratelimit-Reset-Tokens":       {"6m0s"},
+			},
+			1500 * time.Millisecond,
+			true,
+		},
+		{"reset in seconds", http.Header{"X-Ratelimit-Remaining-Tokens": {"0"}, "X-Ratelimit-Reset-Tokens": {"2"}}, 2 * time.Second, true},
+		{"no headers", http.Header{}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := gopherai.RetryDelay(&http.Response{Header: tt.header}, now)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("RetryDelay() = %v, %v, want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestIsRetryableStatus(t *testing.T) {
+	for status, want := range map[int]bool{
+		http.StatusTooManyRequests:     true,
+		http.StatusServiceUnavailable:  true,
+		529:                            true,
+		http.StatusRequestTimeout:      true,
+		http.StatusBadRequest:          false,
+		http.StatusUnauthorized:        false,
+		http.StatusNotFound:            false,
+		http.StatusUnprocessableEntity: false,
+	} {
+		if got := gopherai.IsRetryableStatus(status); got != want {
+			t.Errorf("IsRetryableStatus(%d) = %v, want %v", status, got, want)
+		}
+	}
+}
@@ -0,0 +1,340 @@
+package gopherai_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+)
+
+func TestMarshalConversation_RoundTripsMessages(t *testing.T) {
+	conv := gopherai.NewConversation("session-1", []gopherai.Message{
+		gopherai.NewUserMessage("question"),
+		gopherai.NewAssistantMessage("", gopherai.ToolCall{Name: "lookup", Arguments: `{}`, CallID: "call_1"}),
+		gopherai.NewToolResultMessage(gopherai.ToolResult{CallID: "call_1", Name: "lookup", Output: "42"}),
+		gopherai.NewAssistantMessage("42"),
+	})
+
+	data, err := gopherai.MarshalConversation(conv)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	decoded, err := gopherai.UnmarshalConversation(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if decoded.Version != gopherai.ConversationVersion {
+		t.Errorf("expected version %d, got %d", gopherai.ConversationVersion, decoded.Version)
+	}
+
+	if decoded.ID != "session-1" {
+		t.Errorf("expected ID 'session-1', got '%s'", decoded.ID)
+	}
+
+	if len(decoded.Messages) != 4 {
+		t.Fatalf("expected 4 messages, got %d", len(decoded.Messages))
+	}
+
+	if decoded.Messages[2].ToolResults()[0].Output != "42" {
+		t.Errorf("expected tool result '42', got %v", decoded.Messages[2].ToolResults())
+	}
+}
+
+func TestUnmarshalConversation_RejectsUnknownVersion(t *testing.T) {
+	_, err := gopherai.UnmarshalConversation([]byte(`{"version":99,"messages":[]}`))
+	if err == nil {
+		t.Fatal("expected error for unsupported version")
+	}
+
+	_, err = gopherai.UnmarshalConversation([]byte(`{"messages":[]}`))
+	if err == nil {
+		t.Fatal("expected error for missing version")
+	}
+}
+
+func testSessionStore(t *testing.T, store gopherai.SessionStore) {
+	t.Helper()
+	ctx := context.Background()
+
+	if _, err := store.Load(ctx, "missing"); !errors.Is(err, gopherai.ErrSessionNotFound) {
+		t.Errorf("expected ErrSessionNotFound, got %v", err)
+	}
+
+	if err := store.Append(ctx, "chat-1", gopherai.NewUserMessage("hello")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := store.Append(ctx, "chat-1",
+		gopherai.NewAssistantMessage("", gopherai.ToolCall{Name: "lookup", Arguments: `{}`, CallID: "call_1"}),
+		gopherai.NewToolResultMessage(gopherai.ToolResult{CallID: "call_1", Name: "lookup", Output: "ok"}),
+	); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	messages, err := store.Load(ctx, "chat-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(messages) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(messages))
+	}
+
+	if messages[0].Text() != "hello" {
+		t.Errorf("expected first message 'hello', got '%s'", messages[0].Text())
+	}
+
+	if calls := messages[1].ToolCalls(); len(calls) != 1 || calls[0].CallID != "call_1" {
+		t.Errorf("expected tool call 'call_1', got %v", calls)
+	}
+
+	if err := store.Delete(ctx, "chat-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := store.Load(ctx, "chat-1"); !errors.Is(err, gopherai.ErrSessionNotFound) {
+		t.Errorf("expected ErrSessionNotFound after delete, got %v", err)
+	}
+}
+
+func TestMemorySessionStore_AppendsAndLoadsMessages(t *testing.T) {
+	testSessionStore(t, gopherai.NewMemorySessionStore())
+}
+
+func TestFileSessionStore_AppendsAndLoadsMessages(t *testing.T) {
+	store, err := gopherai.NewFileSessionStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	testSessionStore(t, store)
+}
+
+func TestFileSessionStore_RejectsInvalidSessionID(t *testing.T) {
+	store, err := gopherai.NewFileSessionStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = store.Append(context.Background(), "../escape", gopherai.NewUserMessage("hello"))
+	if err == nil {
+		t.Fatal("expected error for invalid session ID")
+	}
+}
+
+// fakeSQLDB is an in-memory database/sql driver that runs the statements of
+// SQLSessionStore, so that the store is tested without a SQL driver
+// dependency.
+type fakeSQLDB struct {
+	mu   sync.Mutex
+	rows map[string][]fakeSQLRow
+}
+
+type fakeSQLRow struct {
+	seq     int64
+	version int64
+	message string
+}
+
+func (d *fakeSQLDB) Connect(context.Context) (driver.Conn, error) { return fakeSQLConn{d}, nil }
+func (d *fakeSQLDB) Driver() driver.Driver                        { return nil }
+
+type fakeSQLConn struct{ db *fakeSQLDB }
+
+func (c fakeSQLConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeSQLStmt{db: c.db, query: query}, nil
+}
+func (c fakeSQLConn) Close() error              { return nil }
+func (c fakeSQLConn) Begin() (driver.Tx, error) { return fakeSQLTx{}, nil }
+
+type fakeSQLTx struct{}
+
+func (fakeSQLTx) Commit() error   { return nil }
+func (fakeSQLTx) Rollback() error { return nil }
+
+type fakeSQLStmt struct {
+	db    *fakeSQLDB
+	query string
+}
+
+func (s fakeSQLStmt) Close() error  { return nil }
+func (s fakeSQLStmt) NumInput() int { return -1 }
+
+func (s fakeSQLStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(s.query, "CREATE TABLE"):
+	case strings.HasPrefix(s.query, "INSERT INTO"):
+		if _, ok := args[3].(time.Time); !ok {
+			return nil, fmt.Errorf("created_at is %T, not a time", args[3])
+		}
+		session := args[0].(string)
+		row := fakeSQLRow{seq: 1, version: args[1].(int64), message: args[2].(string)}
+		for _, existing := range s.db.rows[args[4].(string)] {
+			row.seq = max(row.seq, existing.seq+1)
+		}
+		s.db.rows[session] = append(s.db.rows[session], row)
+	case strings.HasPrefix(s.query, "DELETE FROM"):
+		delete(s.db.rows, args[0].(string))
+	default:
+		return nil, fmt.Errorf("unsupported statement: %s", s.query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s fakeSQLStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	rows := append([]fakeSQLRow(nil), s.db.rows[args[0].(string)]...)
+	switch {
+	case strings.HasPrefix(s.query, "SELECT version, message"):
+		sort.Slice(rows, func(i, j int) bool { return rows[i].seq < rows[j].seq })
+		values := make([][]driver.Value, len(rows))
+		for i, row := range rows {
+			values[i] = []driver.Value{row.version, row.message}
+		}
+		return &fakeSQLRows{columns: []string{"version", "message"}, values: values}, nil
+	default:
+		return nil, fmt.Errorf("unsupported query: %s", s.query)
+	}
+}
+
+type fakeSQLRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeSQLRows) Columns() []string { return r.columns }
+func (r *fakeSQLRows) Close() error      { return nil }
+
+func (r *fakeSQLRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func TestSQLSessionStore_AppendsAndLoadsMessages(t *testing.T) {
+	db := sql.OpenDB(&fakeSQLDB{rows: make(map[string][]fakeSQLRow)})
+	defer func() { _ = db.Close() }()
+
+	store, err := gopherai.NewSQLSessionStore(context.Background(), db)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	testSessionStore(t, store)
+}
+
+func TestSQLSessionStore_NumbersConcurrentAppendsInOrder(t *testing.T) {
+	db := sql.OpenDB(&fakeSQLDB{rows: make(map[string][]fakeSQLRow)})
+	defer func() { _ = db.Close() }()
+
+	store, err := gopherai.NewSQLSessionStore(context.Background(), db)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Go(func() {
+			if err := store.Append(context.Background(), "chat-1", gopherai.NewUserMessage(fmt.Sprint(i))); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+
+	messages, err := store.Load(context.Background(), "chat-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(messages) != 20 {
+		t.Errorf("expected 20 messages, got %d", len(messages))
+	}
+}
+
+func TestRunSession_LoadsAndAppendsConversation(t *testing.T) {
+	store := gopherai.NewMemorySessionStore()
+	agent := gopherai.NewAgent(&mockProvider{text: "response"}, gopherai.WithSessionStore(store))
+
+	if _, err := agent.RunSession(context.Background(), "chat-1", "first"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := agent.RunSession(context.Background(), "chat-1", "second")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.MessageHistory()) != 4 {
+		t.Errorf("expected run history of 4 messages, got %d", len(result.MessageHistory()))
+	}
+
+	stored, err := store.Load(context.Background(), "chat-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(stored) != 4 {
+		t.Fatalf("expected 4 stored messages, got %d", len(stored))
+	}
+
+	if stored[2].Text() != "second" {
+		t.Errorf("expected third stored message 'second', got '%s'", stored[2].Text())
+	}
+}
+
+func TestRunSession_RequiresSessionStore(t *testing.T) {
+	agent := gopherai.NewAgent(&mockProvider{text: "response"})
+
+	if _, err := agent.RunSession(context.Background(), "chat-1", "hello"); err == nil {
+		t.Fatal("expected error without session store")
+	}
+}
+
+func TestRunStreamSession_AppendsConversationOnCompletion(t *testing.T) {
+	store := gopherai.NewMemorySessionStore()
+	provider := &mockStreamProvider{
+		events: []gopherai.StreamEvent{
+			{Type: gopherai.StreamEventTypeTextDelta, Delta: "streamed"},
+			{Type: gopherai.StreamEventTypeTextDone, Text: "streamed"},
+			{Type: gopherai.StreamEventTypeDone},
+		},
+	}
+	agent := gopherai.NewAgent(provider, gopherai.WithSessionStore(store))
+
+	events, err := agent.RunStreamSession(context.Background(), "chat-1", "hello")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for event := range events {
+		if event.Type == gopherai.StreamEventTypeError {
+			t.Fatalf("unexpected error event: %v", event.Error)
+		}
+	}
+
+	stored, err := store.Load(context.Background(), "chat-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(stored) != 2 || stored[1].Text() != "streamed" {
+		t.Errorf("expected prompt and streamed answer to be stored, got %v", stored)
+	}
+}
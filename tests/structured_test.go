@@ -0,0 +1,247 @@
+package gopherai_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+)
+
+type weatherReport struct {
+	City        string  `json:"city" description:"The city name"`
+	Temperature float64 `json:"temperature"`
+	Conditions  *string `json:"conditions,omitempty"`
+}
+
+// mockStructuredProvider is a scripted provider that records the output
+// schemas applied to its requests.
+type mockStructuredProvider struct {
+	mockScriptedProvider
+	schemas []gopherai.OutputSchema
+}
+
+func (m *mockStructuredProvider) SetOutputSchema(_ any, schema gopherai.OutputSchema) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.schemas = append(m.schemas, schema)
+	return nil
+}
+
+func TestRunTyped_DecodesOutput(t *testing.T) {
+	provider := &mockStructuredProvider{}
+	provider.turns = []mockTurn{{text: `{"city":"Paris","temperature":21.5}`}}
+	agent := gopherai.NewAgent(provider)
+
+	result, err := gopherai.RunTyped[weatherReport](context.Background(), agent, "Weather in Paris?")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Output.City != "Paris" || result.Output.Temperature != 21.5 {
+		t.Errorf("unexpected output: %+v", result.Output)
+	}
+	if len(provider.schemas) != 1 {
+		t.Fatalf("expected schema on 1 request, got %d", len(provider.schemas))
+	}
+	if provider.schemas[0].Name != "weatherReport" {
+		t.Errorf("expected schema name 'weatherReport', got '%s'", provider.schemas[0].Name)
+	}
+}
+
+func TestRunTyped_AcceptsFencedJSON(t *testing.T) {
+	provider := &mockStructuredProvider{}
+	provider.turns = []mockTurn{{text: "```json\n{\"city\":\"Oslo\",\"temperature\":3}\n```"}}
+	agent := gopherai.NewAgent(provider)
+
+	result, err := gopherai.RunTyped[weatherReport](context.Background(), agent, "Weather in Oslo?")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Output.City != "Oslo" {
+		t.Errorf("expected city 'Oslo', got '%s'", result.Output.City)
+	}
+}
+
+func TestRunTyped_RepromptsOnInvalidJSON(t *testing.T) {
+	provider := &mockStructuredProvider{}
+	provider.turns = []mockTurn{
+		{text: "It is sunny in Rome"},
+		{text: `{"city":"Rome","temperature":28}`},
+	}
+	agent := gopherai.NewAgent(provider)
+
+	result, err := gopherai.RunTyped[weatherReport](context.Background(), agent, "Weather in Rome?")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Output.City != "Rome" {
+		t.Errorf("expected city 'Rome', got '%s'", result.Output.City)
+	}
+
+	if len(provider.requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(provider.requests))
+	}
+	retry := provider.requests[1]
+	last := retry[len(retry)-1]
+	if last.Role != gopherai.RoleUser || !strings.Contains(last.Text(), "not valid") {
+		t.Errorf("expected retry prompt with the validation error, got %+v", last)
+	}
+	if len(result.MessageHistory()) != 4 {
+		t.Errorf("expected 4 messages in history, got %d", len(result.MessageHistory()))
+	}
+}
+
+func TestRunTyped_RepromptsOnMissingRequiredField(t *testing.T) {
+	provider := &mockStructuredProvider{}
+	provider.turns = []mockTurn{
+		{text: `{"city":"Rome"}`},
+		{text: `{"city":"Rome","temperature":28}`},
+	}
+	agent := gopherai.NewAgent(provider)
+
+	_, err := gopherai.RunTyped[weatherReport](context.Background(), agent, "Weather in Rome?")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	retry := provider.requests[1]
+	if !strings.Contains(retry[len(retry)-1].Text(), "temperature") {
+		t.Errorf("expected retry prompt to name the missing field, got %q", retry[len(retry)-1].Text())
+	}
+}
+
+func TestRunTyped_FailsAfterOutputRetries(t *testing.T) {
+	provider := &mockStructuredProvider{}
+	provider.turns = []mockTurn{
+		{text: "not json"},
+		{text: "still not json"},
+	}
+	agent := gopherai.NewAgent(provider, gopherai.WithOutputRetries(1))
+
+	_, err := gopherai.RunTyped[weatherReport](context.Background(), agent, "Weather?")
+	if !errors.Is(err, gopherai.ErrInvalidOutput) {
+		t.Fatalf("expected ErrInvalidOutput, got %v", err)
+	}
+	if len(provider.requests) != 2 {
+		t.Errorf("expected 2 requests, got %d", len(provider.requests))
+	}
+}
+
+func TestRunTyped_RetriesWithinOneRun(t *testing.T) {
+	provider := &mockStructuredProvider{}
+	provider.turns = []mockTurn{
+		{text: "not json"},
+		{text: `{"city":"Rome","temperature":28}`},
+	}
+	hooks := &recordingHooks{}
+	agent := gopherai.NewAgent(provider, gopherai.WithHooks(hooks))
+
+	if _, err := gopherai.RunTyped[weatherReport](context.Background(), agent, "Weather in Rome?"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{
+		"run_start 1",
+		"before_model 1",
+		"after_model 1 0",
+		"before_model 2",
+		"after_model 2 0",
+		`run_end {"city":"Rome","temperature":28}`,
+	}
+	if !reflect.DeepEqual(hooks.calls, want) {
+		t.Errorf("expected hook calls %v, got %v", want, hooks.calls)
+	}
+}
+
+func TestResumeTyped_DecodesOutputOfApprovedRun(t *testing.T) {
+	provider := &mockStructuredProvider{}
+	provider.turns = []mockTurn{
+		{calls: []gopherai.ToolCall{{Name: "echo", Arguments: `{"value":"Rome"}`, CallID: "call_1"}}},
+		{text: "It is sunny in Rome"},
+		{text: `{"city":"Rome","temperature":28}`},
+	}
+	var received []string
+	agent := gopherai.NewAgent(provider, gopherai.WithTools(approvalTool(&received)))
+
+	interrupted, err := gopherai.RunTyped[weatherReport](context.Background(), agent, "Weather in Rome?")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !interrupted.Interrupted {
+		t.Fatal("expected the run to be interrupted for approval")
+	}
+
+	decisions := []gopherai.ToolDecision{gopherai.Approve("call_1")}
+	result, err := gopherai.ResumeTyped[weatherReport](context.Background(), agent, roundTrip(t, interrupted.State), decisions)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Output.City != "Rome" || result.Output.Temperature != 28 {
+		t.Errorf("unexpected output: %+v", result.Output)
+	}
+	if len(provider.schemas) != 3 {
+		t.Errorf("expected schema on 3 requests, got %d", len(provider.schemas))
+	}
+}
+
+func TestResumeRunTyped_DecodesOutputOfCheckpointedRun(t *testing.T) {
+	provider := &mockStructuredProvider{}
+	provider.turns = []mockTurn{
+		{calls: []gopherai.ToolCall{{Name: "echo", Arguments: `{"value":"Oslo"}`, CallID: "call_1"}}},
+		{err: errors.New("pod restarted")},
+		{text: `{"city":"Oslo","temperature":3}`},
+	}
+	agent := gopherai.NewAgent(provider,
+		gopherai.WithTools(echoTool()),
+		gopherai.WithCheckpointer(gopherai.NewMemoryCheckpointer()),
+	)
+
+	ctx := gopherai.ContextWithRunID(context.Background(), "job-3")
+	if _, err := gopherai.RunTyped[weatherReport](ctx, agent, "Weather in Oslo?"); err == nil {
+		t.Fatal("expected the run to fail")
+	}
+
+	result, err := gopherai.ResumeRunTyped[weatherReport](context.Background(), agent, "job-3")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Output.City != "Oslo" || result.Output.Temperature != 3 {
+		t.Errorf("unexpected output: %+v", result.Output)
+	}
+}
+
+func TestRunTyped_ReturnsErrorWhenProviderDoesNotSupportStructuredOutput(t *testing.T) {
+	provider := &mockScriptedProvider{}
+	agent := gopherai.NewAgent(provider)
+
+	_, err := gopherai.RunTyped[weatherReport](context.Background(), agent, "Weather?")
+	if err == nil || !strings.Contains(err.Error(), "structured output") {
+		t.Fatalf("expected structured output error, got %v", err)
+	}
+}
+
+func TestWithOutputType_AppliesSchemaToRequests(t *testing.T) {
+	provider := &mockStructuredProvider{}
+	provider.turns = []mockTurn{{text: `{"city":"Lima","temperature":18}`}}
+	agent := gopherai.NewAgent(provider, gopherai.WithOutputType[weatherReport]())
+
+	result, err := agent.Run(context.Background(), "Weather in Lima?")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Text != `{"city":"Lima","temperature":18}` {
+		t.Errorf("unexpected text: %s", result.Text)
+	}
+
+	if len(provider.schemas) != 1 {
+		t.Fatalf("expected schema on 1 request, got %d", len(provider.schemas))
+	}
+	props, _ := provider.schemas[0].Schema["properties"].(map[string]any)
+	if _, ok := props["city"]; !ok {
+		t.Errorf("expected schema to describe 'city', got %v", provider.schemas[0].Schema)
+	}
+}
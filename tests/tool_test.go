@@ -0,0 +1,311 @@
+package gopherai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+)
+
+type toolTestParams struct {
+	Value string `json:"value"`
+}
+
+func failingTool(name string, failures int32, attempts *int32) gopherai.Tool {
+	return gopherai.NewTool(name, "fails a number of times", func(p toolTestParams) (string, error) {
+		if atomic.AddInt32(attempts, 1) <= failures {
+			return "", errors.New("temporary failure")
+		}
+		return "ok:" + p.Value, nil
+	})
+}
+
+func callTurn(name string) mockTurn {
+	return mockTurn{calls: []gopherai.ToolCall{{Name: name, Arguments: `{"value":"x"}`, CallID: "call_1"}}}
+}
+
+func TestRun_ToolErrorAbortsByDefault(t *testing.T) {
+	var attempts int32
+	provider := &mockScriptedProvider{turns: []mockTurn{callTurn("flaky")}}
+	agent := gopherai.NewAgent(provider, gopherai.WithTools(failingTool("flaky", 1, &attempts)))
+
+	_, err := agent.Run(context.Background(), "go")
+	if err == nil {
+		t.Fatal("expected tool error to abort the run")
+	}
+}
+
+func TestRun_ReportToolErrorsSendsErrorToModel(t *testing.T) {
+	var attempts int32
+	provider := &mockScriptedProvider{turns: []mockTurn{callTurn("flaky")}}
+	agent := gopherai.NewAgent(provider,
+		gopherai.WithTools(failingTool("flaky", 1, &attempts)),
+		gopherai.WithToolErrorPolicy(gopherai.ReportToolErrors()),
+	)
+
+	result, err := agent.Run(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Text != "done" {
+		t.Errorf("expected 'done', got '%s'", result.Text)
+	}
+
+	results := provider.lastToolResults()
+	if len(results) != 1 {
+		t.Fatalf("expected 1 tool result, got %d", len(results))
+	}
+
+	if !results[0].IsError {
+		t.Error("expected tool result to be marked as an error")
+	}
+
+	if !strings.Contains(results[0].Output, "temporary failure") {
+		t.Errorf("expected error message in output, got '%s'", results[0].Output)
+	}
+}
+
+func TestRun_ReportToolErrorsReportsUnknownTool(t *testing.T) {
+	provider := &mockScriptedProvider{turns: []mockTurn{callTurn("missing")}}
+	agent := gopherai.NewAgent(provider, gopherai.WithToolErrorPolicy(gopherai.ReportToolErrors()))
+
+	if _, err := agent.Run(context.Background(), "go"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	results := provider.lastToolResults()
+	if len(results) != 1 || !results[0].IsError || results[0].Output != "unknown tool: missing" {
+		t.Errorf("expected unknown tool error result, got %v", results)
+	}
+}
+
+func TestRun_RetryToolErrorsRetriesHandler(t *testing.T) {
+	var attempts int32
+	provider := &mockScriptedProvider{turns: []mockTurn{callTurn("flaky")}}
+	agent := gopherai.NewAgent(provider,
+		gopherai.WithTools(failingTool("flaky", 2, &attempts)),
+		gopherai.WithToolErrorPolicy(gopherai.RetryToolErrors(2, gopherai.ToolErrorAbort)),
+	)
+
+	if _, err := agent.Run(context.Background(), "go"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+
+	results := provider.lastToolResults()
+	if len(results) != 1 || results[0].IsError || results[0].Output != "ok:x" {
+		t.Errorf("expected successful result after retries, got %v", results)
+	}
+}
+
+func TestRun_RetryToolErrorsAppliesActionWhenRetriesRunOut(t *testing.T) {
+	var attempts int32
+	provider := &mockScriptedProvider{turns: []mockTurn{callTurn("flaky")}}
+	agent := gopherai.NewAgent(provider,
+		gopherai.WithTools(failingTool("flaky", 10, &attempts)),
+		gopherai.WithToolErrorPolicy(gopherai.RetryToolErrors(1, gopherai.ToolErrorAbort)),
+	)
+
+	if _, err := agent.Run(context.Background(), "go"); err == nil {
+		t.Fatal("expected run to abort after retries")
+	}
+
+	if attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestRun_ToolErrorPolicyOverridesAgentPolicy(t *testing.T) {
+	var attempts int32
+	tool := failingTool("flaky", 1, &attempts).WithErrorPolicy(gopherai.ReportToolErrors())
+	provider := &mockScriptedProvider{turns: []mockTurn{callTurn("flaky")}}
+	agent := gopherai.NewAgent(provider,
+		gopherai.WithTools(tool),
+		gopherai.WithToolErrorPolicy(gopherai.AbortOnToolError()),
+	)
+
+	if _, err := agent.Run(context.Background(), "go"); err != nil {
+		t.Fatalf("expected tool policy to report the error, got %v", err)
+	}
+
+	results := provider.lastToolResults()
+	if len(results) != 1 || !results[0].IsError {
+		t.Errorf("expected error result, got %v", results)
+	}
+}
+
+func TestNewToolCtx_ReceivesRunAndCallInfo(t *testing.T) {
+	var gotInfo gopherai.RunInfo
+	var gotCall gopherai.ToolCall
+	tool := gopherai.NewToolCtx("inspect", "inspects its context", func(ctx context.Context, _ toolTestParams) (string, error) {
+		gotInfo, _ = gopherai.RunInfoFromContext(ctx)
+		gotCall, _ = gopherai.ToolCallFromContext(ctx)
+		return "ok", nil
+	})
+
+	provider := &mockScriptedProvider{turns: []mockTurn{callTurn("inspect")}}
+	agent := gopherai.NewAgent(provider, gopherai.WithName("inspector"), gopherai.WithTools(tool))
+
+	result, err := agent.Run(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if gotInfo.RunID == "" || gotInfo.RunID != result.RunID {
+		t.Errorf("expected run ID '%s', got '%s'", result.RunID, gotInfo.RunID)
+	}
+
+	if gotInfo.AgentName != "inspector" {
+		t.Errorf("expected agent name 'inspector', got '%s'", gotInfo.AgentName)
+	}
+
+	if gotCall.CallID != "call_1" || gotCall.Name != "inspect" {
+		t.Errorf("expected call 'call_1' to 'inspect', got %v", gotCall)
+	}
+}
+
+func TestNewToolCtx_HandlerWorksWithoutContext(t *testing.T) {
+	tool := gopherai.NewToolCtx("echo", "echoes", func(_ context.Context, p toolTestParams) (string, error) {
+		return p.Value, nil
+	})
+
+	output, err := tool.Handler(`{"value":"hi"}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if output != "hi" {
+		t.Errorf("expected 'hi', got '%s'", output)
+	}
+}
+
+func TestRun_ToolTimeoutIsReportedToModel(t *testing.T) {
+	cancelled := make(chan struct{})
+	tool := gopherai.NewToolCtx("slow", "never finishes", func(ctx context.Context, _ toolTestParams) (string, error) {
+		<-ctx.Done()
+		close(cancelled)
+		return "", ctx.Err()
+	}).WithTimeout(10 * time.Millisecond)
+
+	provider := &mockScriptedProvider{turns: []mockTurn{callTurn("slow")}}
+	agent := gopherai.NewAgent(provider, gopherai.WithTools(tool))
+
+	if _, err := agent.Run(context.Background(), "go"); err != nil {
+		t.Fatalf("expected timeout to be reported, got %v", err)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("expected handler context to be cancelled")
+	}
+
+	results := provider.lastToolResults()
+	if len(results) != 1 || !results[0].IsError || !strings.Contains(results[0].Output, "timed out") {
+		t.Errorf("expected timeout result, got %v", results)
+	}
+}
+
+func TestRun_CancellationStopsToolHandlers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	tool := gopherai.NewToolCtx("block", "blocks until cancelled", func(ctx context.Context, _ toolTestParams) (string, error) {
+		cancel()
+		<-ctx.Done()
+		close(stopped)
+		return "", ctx.Err()
+	})
+
+	provider := &mockScriptedProvider{turns: []mockTurn{callTurn("block")}}
+	agent := gopherai.NewAgent(provider, gopherai.WithTools(tool))
+
+	_, err := agent.Run(ctx, "go")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("expected tool handler to stop")
+	}
+}
+
+func TestAgent_AsTool_RunsWithParentRunInfo(t *testing.T) {
+	var childInfo gopherai.RunInfo
+	inspect := gopherai.NewToolCtx("inspect", "inspects its context", func(ctx context.Context, _ toolTestParams) (string, error) {
+		childInfo, _ = gopherai.RunInfoFromContext(ctx)
+		return "ok", nil
+	})
+	child := gopherai.NewAgent(
+		&mockScriptedProvider{turns: []mockTurn{callTurn("inspect")}},
+		gopherai.WithName("child"),
+		gopherai.WithTools(inspect),
+	)
+
+	parentProvider := &mockScriptedProvider{turns: []mockTurn{
+		{calls: []gopherai.ToolCall{{Name: "child", Arguments: `{"task":"work"}`, CallID: "call_1"}}},
+	}}
+	parent := gopherai.NewAgent(parentProvider, gopherai.WithTools(child.AsTool("child", "child agent")))
+
+	result, err := parent.Run(context.Background(), "delegate")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if childInfo.AgentName != "child" {
+		t.Errorf("expected agent name 'child', got '%s'", childInfo.AgentName)
+	}
+
+	if childInfo.ParentRunID != result.RunID {
+		t.Errorf("expected parent run ID '%s', got '%s'", result.RunID, childInfo.ParentRunID)
+	}
+}
+
+func TestRun_InvalidArgumentsAreReportedToModel(t *testing.T) {
+	type boundedParams struct {
+		Count int `json:"count" minimum:"1"`
+	}
+
+	var called bool
+	tool := gopherai.NewTool("bounded", "needs a positive count", func(_ boundedParams) (string, error) {
+		called = true
+		return "ok", nil
+	})
+	provider := &mockScriptedProvider{turns: []mockTurn{
+		{calls: []gopherai.ToolCall{{Name: "bounded", Arguments: `{"count":0}`, CallID: "call_1"}}},
+	}}
+	agent := gopherai.NewAgent(provider, gopherai.WithTools(tool.WithErrorPolicy(gopherai.RetryToolErrors(3, gopherai.ToolErrorAbort))))
+
+	_, err := agent.Run(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("expected invalid arguments to be reported, got error: %v", err)
+	}
+	if called {
+		t.Error("expected handler not to be called")
+	}
+
+	results := provider.lastToolResults()
+	if len(results) != 1 || !results[0].IsError {
+		t.Fatalf("expected 1 error result, got %+v", results)
+	}
+
+	var report struct {
+		Violations []gopherai.Violation `json:"violations"`
+	}
+	if err := json.Unmarshal([]byte(results[0].Output), &report); err != nil {
+		t.Fatalf("expected JSON error report, got %q", results[0].Output)
+	}
+	if len(report.Violations) != 1 || report.Violations[0].Constraint != "minimum" {
+		t.Errorf("expected minimum violation, got %+v", report.Violations)
+	}
+}
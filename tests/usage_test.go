@@ -0,0 +1,130 @@
+package gopherai_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/marti-jorda-roca/gopher-ai/gopherai"
+)
+
+// meteredProvider returns a provider that requests one echo tool call, then
+// answers, reporting usage for each response.
+func meteredProvider() *mockScriptedProvider {
+	return &mockScriptedProvider{turns: []mockTurn{
+		{
+			calls: []gopherai.ToolCall{{Name: "echo", Arguments: `{"value":"x"}`, CallID: "call_1"}},
+			usage: &gopherai.Usage{Model: "gpt-4.1-2025-04-14", InputTokens: 1000, CachedInputTokens: 400, OutputTokens: 100, ReasoningTokens: 20},
+		},
+		{
+			text:  "done",
+			usage: &gopherai.Usage{Model: "gpt-4.1-2025-04-14", InputTokens: 1200, OutputTokens: 50},
+		},
+	}}
+}
+
+var testPrices = gopherai.PriceTable{
+	"gpt-4.1":      {Input: 2, CachedInput: 0.5, Output: 8},
+	"gpt-4.1-mini": {Input: 0.4, Output: 1.6},
+}
+
+func TestRun_RecordsUsagePerTurnAndInTotal(t *testing.T) {
+	agent := gopherai.NewAgent(meteredProvider(), gopherai.WithTools(echoTool()))
+
+	result, err := agent.Run(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Turns) != 2 {
+		t.Fatalf("expected 2 turns of usage, got %d", len(result.Turns))
+	}
+	want := gopherai.Usage{Model: "gpt-4.1-2025-04-14", InputTokens: 2200, CachedInputTokens: 400, OutputTokens: 150, ReasoningTokens: 20}
+	if result.Usage != want {
+		t.Errorf("expected %+v, got %+v", want, result.Usage)
+	}
+	if result.Cost != 0 {
+		t.Errorf("expected no cost without pricing, got %f", result.Cost)
+	}
+}
+
+func TestRun_PricesUsage(t *testing.T) {
+	agent := gopherai.NewAgent(meteredProvider(), gopherai.WithTools(echoTool()), gopherai.WithPricing(testPrices))
+
+	result, err := agent.Run(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	first := (600*2 + 400*0.5 + 100*8) / 1e6
+	second := (1200*2 + 50*8) / 1e6
+	if math.Abs(result.Turns[0].Cost-first) > 1e-12 || math.Abs(result.Turns[1].Cost-second) > 1e-12 {
+		t.Errorf("unexpected turn costs: %+v", result.Turns)
+	}
+	if math.Abs(result.Cost-(first+second)) > 1e-12 {
+		t.Errorf("expected cost %f, got %f", first+second, result.Cost)
+	}
+}
+
+func TestRunStream_EmitsUsageEvents(t *testing.T) {
+	agent := gopherai.NewAgent(meteredProvider(), gopherai.WithTools(echoTool()))
+
+	events, err := agent.RunStream(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var total gopherai.Usage
+	for event := range events {
+		if event.Type == gopherai.StreamEventTypeError {
+			t.Fatalf("unexpected error: %v", event.Error)
+		}
+		if event.Type == gopherai.StreamEventTypeUsage {
+			total = total.Add(*event.Usage)
+		}
+	}
+	if total.InputTokens != 2200 || total.OutputTokens != 150 {
+		t.Errorf("unexpected streamed usage: %+v", total)
+	}
+}
+
+func TestPriceTable_PricesDatedSnapshotsAsTheirModel(t *testing.T) {
+	price, ok := testPrices.Price("gpt-4.1-mini-2025-04-14")
+	if !ok || price.Input != 0.4 {
+		t.Errorf("expected gpt-4.1-mini price, got %+v (%v)", price, ok)
+	}
+	prices := gopherai.PriceTable{"claude-sonnet-4-5": {Input: 3}}
+	if price, ok := prices.Price("claude-sonnet-4-5-20250929"); !ok || price.Input != 3 {
+		t.Errorf("expected claude-sonnet-4-5 price, got %+v (%v)", price, ok)
+	}
+	if _, ok := testPrices.Cost(gopherai.Usage{Model: "claude-sonnet-4-5", InputTokens: 10}); ok {
+		t.Error("expected no price for an unknown model")
+	}
+}
+
+func TestPriceTable_DoesNotPriceOtherModelsByPrefix(t *testing.T) {
+	prices := gopherai.PriceTable{"gpt-4.1": {Input: 2}, "gpt-4o": {Input: 2.5}}
+
+	for _, model := range []string{"gpt-4.1-mini", "gpt-4.1-mini-2025-04-14", "gpt-4o-mini"} {
+		if price, ok := prices.Price(model); ok {
+			t.Errorf("expected %s not to be priced, got %+v", model, price)
+		}
+	}
+}
+
+func TestPriceTable_BillsCachedTokensAsInputWithoutCachedPrice(t *testing.T) {
+	cost, ok := testPrices.Cost(gopherai.Usage{Model: "gpt-4.1-mini", InputTokens: 1_000_000, CachedInputTokens: 500_000})
+	if !ok || math.Abs(cost-0.4) > 1e-12 {
+		t.Errorf("expected cost 0.4, got %f (%v)", cost, ok)
+	}
+}
+
+func TestUsage_AddDropsModelWhenModelsDiffer(t *testing.T) {
+	sum := gopherai.Usage{}.Add(gopherai.Usage{Model: "a", InputTokens: 1})
+	if sum.Model != "a" {
+		t.Errorf("expected model a, got %q", sum.Model)
+	}
+	if sum = sum.Add(gopherai.Usage{Model: "b", OutputTokens: 1}); sum.Model != "" || sum.TotalTokens() != 2 {
+		t.Errorf("unexpected sum: %+v", sum)
+	}
+}